@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newManager(t *testing.T) (*Manager, *MemoryStore) {
+	t.Helper()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	m, err := NewManager(r, "sk", store)
+	require.NoError(t, err)
+	return m, store
+}
+
+func TestIssueAndAuthenticate(t *testing.T) {
+	m, store := newManager(t)
+	ctx := context.Background()
+
+	key, rec, err := m.Issue(ctx, "billing", "orders:read", "orders:write")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "sk_"))
+	assert.True(t, strings.HasSuffix(key, "-orders:read,orders:write"))
+	assert.Equal(t, Hash(key), rec.Hash)
+	assert.Equal(t, key[3:29], rec.ULID)
+
+	stored, err := store.Lookup(ctx, Hash(key))
+	require.NoError(t, err)
+	assert.Equal(t, rec, stored)
+
+	got, err := m.Authenticate(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "billing", got.Name)
+	assert.True(t, got.HasScope("orders:write"))
+	assert.False(t, got.HasScope("admin"))
+}
+
+func TestIssueWithReplayStore(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	replays := rigid.NewMemoryStore(0)
+	m, err := NewManager(r.WithReplayStore(replays, time.Hour), "sk", NewMemoryStore())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Issuing does not spend the single use of the key
+	key, _, err := m.Issue(ctx, "billing", "orders:read")
+	require.NoError(t, err)
+	_, err = m.Authenticate(ctx, key)
+	require.NoError(t, err)
+	_, err = m.Authenticate(ctx, key)
+	assert.ErrorIs(t, err, rigid.ErrReplayed)
+}
+
+func TestKeyWithoutScopes(t *testing.T) {
+	m, _ := newManager(t)
+
+	key, _, err := m.Issue(context.Background(), "monitoring")
+	require.NoError(t, err)
+
+	rec, err := m.Authenticate(context.Background(), key)
+	require.NoError(t, err)
+	assert.Empty(t, rec.Scopes)
+}
+
+func TestRevoke(t *testing.T) {
+	m, _ := newManager(t)
+	ctx := context.Background()
+
+	key, _, err := m.Issue(ctx, "billing", "orders:read")
+	require.NoError(t, err)
+	require.NoError(t, m.Revoke(ctx, key))
+
+	_, err = m.Authenticate(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAuthenticateRejectsForgedKeys(t *testing.T) {
+	m, store := newManager(t)
+	ctx := context.Background()
+
+	key, rec, err := m.Issue(ctx, "billing", "orders:read")
+	require.NoError(t, err)
+
+	// Scopes cannot be widened in the key ...
+	_, err = m.Authenticate(ctx, key+",admin")
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+
+	// ... or in the store.
+	rec.Scopes = append(rec.Scopes, "admin")
+	require.NoError(t, store.Save(ctx, rec))
+	_, err = m.Authenticate(ctx, key)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+
+	// Keys without the type prefix are rejected.
+	_, err = m.Authenticate(ctx, key[3:])
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+}
+
+func TestIssueErrors(t *testing.T) {
+	m, _ := newManager(t)
+
+	for _, scope := range []string{"", "a,b", "orders read"} {
+		_, _, err := m.Issue(context.Background(), "billing", scope)
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	}
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	_, err = NewManager(r, "", NewMemoryStore())
+	assert.ErrorIs(t, err, rigid.ErrInvalidPrefix)
+	_, err = NewManager(r, "s-k", NewMemoryStore())
+	assert.ErrorIs(t, err, rigid.ErrInvalidPrefix)
+}
@@ -0,0 +1,85 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type recordKey struct{}
+
+type config struct {
+	header string
+}
+
+// Option configures the middleware.
+type Option func(*config)
+
+// WithHeader reads the key from the named header instead of an Authorization bearer token.
+func WithHeader(name string) Option {
+	return func(c *config) {
+		c.header = name
+	}
+}
+
+// Middleware returns middleware authenticating every request with m. The key is read from
+// the Authorization header as a bearer token unless WithHeader is used. Requests without a
+// valid key are rejected with 401 Unauthorized; authenticated requests carry the key's
+// record in their context, where FromContext retrieves it.
+func Middleware(m *Manager, opts ...Option) func(http.Handler) http.Handler {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := c.key(req)
+			if key == "" {
+				http.Error(w, "missing API key", http.StatusUnauthorized)
+				return
+			}
+
+			rec, err := m.Authenticate(req.Context(), key)
+			if err != nil {
+				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), recordKey{}, rec)))
+		})
+	}
+}
+
+// RequireScope returns middleware rejecting requests whose key lacks scope with 403 Forbidden.
+// It must run after Middleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rec, ok := FromContext(req.Context())
+			if !ok || !rec.HasScope(scope) {
+				http.Error(w, "insufficient scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// FromContext returns the record of the key that authenticated the request, if any.
+func FromContext(ctx context.Context) (Record, bool) {
+	rec, ok := ctx.Value(recordKey{}).(Record)
+	return rec, ok
+}
+
+func (c config) key(req *http.Request) string {
+	if c.header != "" {
+		return req.Header.Get(c.header)
+	}
+
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,77 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	m, _ := newManager(t)
+	ctx := context.Background()
+
+	reader, _, err := m.Issue(ctx, "reader", "orders:read")
+	require.NoError(t, err)
+	writer, _, err := m.Issue(ctx, "writer", "orders:read", "orders:write")
+	require.NoError(t, err)
+
+	var name string
+	h := Middleware(m)(RequireScope("orders:write")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec, _ := FromContext(req.Context())
+		name = rec.Name
+	})))
+
+	tests := []struct {
+		auth string
+		code int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Basic " + writer, http.StatusUnauthorized},
+		{"Bearer " + writer[:len(writer)-1], http.StatusUnauthorized},
+		{"Bearer " + reader, http.StatusForbidden},
+		{"bearer " + writer, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, tt.code, rec.Code, tt.auth)
+	}
+	assert.Equal(t, "writer", name)
+}
+
+func TestMiddlewareCustomHeader(t *testing.T) {
+	m, _ := newManager(t)
+
+	key, _, err := m.Issue(context.Background(), "reader", "orders:read")
+	require.NoError(t, err)
+
+	h := Middleware(m, WithHeader("X-API-Key"))(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequireScopeWithoutMiddleware(t *testing.T) {
+	h := RequireScope("orders:read")(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
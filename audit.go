@@ -0,0 +1,144 @@
+package rigid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit event types.
+const (
+	AuditIssued   = "issued"
+	AuditRejected = "rejected"
+)
+
+// AuditEvent records the issuance of an ID or a failed verification. Events never contain
+// signatures or metadata, so audit trails cannot be used to replay IDs or leak what they carry.
+type AuditEvent struct {
+	// Time is the time of the event.
+	Time time.Time `json:"time"`
+	// Type is AuditIssued or AuditRejected.
+	Type string `json:"type"`
+	// Prefix is the type prefix of the ID, or of the instance for rejected IDs that cannot be split.
+	Prefix string `json:"prefix,omitempty"`
+	// ULID is the ULID of the ID, or empty for rejected IDs that cannot be split.
+	ULID string `json:"ulid,omitempty"`
+	// KeyID is the key ID of the issuing or verifying instance, see Rigid.KeyID.
+	KeyID string `json:"key_id"`
+	// Reason is the FailureReason of a rejected ID.
+	Reason string `json:"reason,omitempty"`
+	// Remote is the remote party stored in the context by ContextWithRemote, formatted with
+	// fmt.Sprint, or empty.
+	Remote string `json:"remote,omitempty"`
+}
+
+// AuditSink records audit events. Implementations must be safe for concurrent use.
+type AuditSink interface {
+	// Audit records e. An error fails the generation of an issued ID, so no ID is handed out
+	// without an audit record; errors recording rejections are ignored, as the verification
+	// has failed already.
+	Audit(e AuditEvent) error
+}
+
+// auditor is the audit sink of an instance along with its key ID, computed once.
+type auditor struct {
+	sink  AuditSink
+	keyID string
+}
+
+// WithAuditSink returns a copy of r recording every generated ID and every failed verification
+// to s, or recording nothing if s is nil. Generation fails if the issuance cannot be recorded.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithAuditSink(s AuditSink) *Rigid {
+	c := r.clone()
+	c.audit = nil
+	if s != nil {
+		c.audit = &auditor{sink: s, keyID: r.KeyID()}
+	}
+	return c
+}
+
+// issued records the issuance of id.
+func (a *auditor) issued(id string) error {
+	seg, _ := splitID(id)
+	err := a.sink.Audit(AuditEvent{
+		Time:   time.Now(),
+		Type:   AuditIssued,
+		Prefix: seg.prefix,
+		ULID:   seg.ulid,
+		KeyID:  a.keyID,
+	})
+	if err != nil {
+		return fmt.Errorf("audit issuance: %w", err)
+	}
+	return nil
+}
+
+// rejected records the failed verification of id by r.
+func (a *auditor) rejected(ctx context.Context, r *Rigid, id string, err error) {
+	e := AuditEvent{
+		Time:   time.Now(),
+		Type:   AuditRejected,
+		Prefix: r.prefix,
+		KeyID:  a.keyID,
+		Reason: FailureReason(err),
+	}
+	if seg, err := splitID(id); err == nil {
+		e.Prefix = seg.prefix
+		e.ULID = seg.ulid
+	}
+	if remote := RemoteFromContext(ctx); remote != nil {
+		e.Remote = fmt.Sprint(remote)
+	}
+	_ = a.sink.Audit(e)
+}
+
+// JSONAuditSink is an AuditSink writing each event as a line of JSON.
+type JSONAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONAuditSink returns an AuditSink writing events to w as JSON lines. Each event is written
+// with a single Write call.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{w: w}
+}
+
+// OpenAuditFile opens the file at path for appending, creating it with mode 0600 if needed, and
+// returns a JSONAuditSink writing to it. Close the sink to close the file.
+func OpenAuditFile(path string) (*JSONAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONAuditSink(f), nil
+}
+
+// Audit implements AuditSink.
+func (s *JSONAuditSink) Audit(e AuditEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(b)
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (s *JSONAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
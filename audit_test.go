@@ -0,0 +1,137 @@
+package rigid
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAudit decodes the JSON lines in b.
+func readAudit(t *testing.T, b []byte) []AuditEvent {
+	t.Helper()
+
+	var events []AuditEvent
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		var e AuditEvent
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &e), sc.Text())
+		events = append(events, e)
+	}
+	require.NoError(t, sc.Err())
+	return events
+}
+
+func TestWithAuditSink(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := base.WithPrefix("ord")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	r := typed.WithAuditSink(NewJSONAuditSink(&buf))
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	appended, err := r.AppendGenerate(nil)
+	require.NoError(t, err)
+	_, err = r.Verify(id)
+	require.NoError(t, err)
+
+	tampered := id[:31] + "AAAAAAAAAAAAA"
+	ctx := ContextWithRemote(context.Background(), "192.0.2.1:4711")
+	_, err = r.VerifyContext(ctx, tampered)
+	require.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.VerifyBytes([]byte("garbage"))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	events := readAudit(t, buf.Bytes())
+	require.Len(t, events, 4)
+	for _, e := range events {
+		assert.WithinDuration(t, time.Now(), e.Time, time.Minute)
+		assert.Equal(t, base.KeyID(), e.KeyID)
+		assert.Equal(t, "ord", e.Prefix)
+	}
+
+	seg, _ := splitID(id)
+	assert.Equal(t, AuditIssued, events[0].Type)
+	assert.Equal(t, seg.ulid, events[0].ULID)
+	assert.Empty(t, events[0].Reason)
+	appendedSeg, _ := splitID(string(appended))
+	assert.Equal(t, appendedSeg.ulid, events[1].ULID)
+
+	assert.Equal(t, AuditRejected, events[2].Type)
+	assert.Equal(t, seg.ulid, events[2].ULID)
+	assert.Equal(t, ReasonIntegrity, events[2].Reason)
+	assert.Equal(t, "192.0.2.1:4711", events[2].Remote)
+	assert.Equal(t, ReasonInvalidFormat, events[3].Reason)
+	assert.Empty(t, events[3].ULID)
+
+	// Signatures and metadata never reach the audit trail
+	assert.NotContains(t, buf.String(), seg.signature)
+	assert.NotContains(t, buf.String(), "alice")
+}
+
+// failingSink is an AuditSink whose writes fail.
+type failingSink struct{}
+
+func (failingSink) Audit(AuditEvent) error { return errors.New("disk full") }
+
+func TestWithAuditSinkFailure(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithAuditSink(failingSink{})
+
+	// No ID is issued without an audit record
+	id, err := r.Generate()
+	assert.ErrorContains(t, err, "disk full")
+	assert.Empty(t, id)
+	dst := []byte("ids:")
+	b, err := r.AppendGenerate(dst)
+	assert.Error(t, err)
+	assert.Equal(t, "ids:", string(b))
+	assert.Equal(t, uint64(2), r.Stats().GenerateFailures)
+
+	// Failed rejection records do not change verification results
+	_, err = r.Verify("garbage")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	// Removing the sink restores generation
+	_, err = r.WithAuditSink(nil).Generate()
+	assert.NoError(t, err)
+}
+
+func TestOpenAuditFile(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for range 2 {
+		sink, err := OpenAuditFile(path)
+		require.NoError(t, err)
+		_, err = r.WithAuditSink(sink).Generate()
+		require.NoError(t, err)
+		require.NoError(t, sink.Close())
+	}
+
+	// The file is appended to and readable only by its owner
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, readAudit(t, b), 2)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	_, err = OpenAuditFile(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package rigid
+
+import (
+	"iter"
+	"time"
+)
+
+// MaxAuditSamples is the number of rejected IDs an AuditReport keeps as samples.
+const MaxAuditSamples = 10
+
+// ReasonDuplicate is the reason of AuditSample for valid IDs whose ULID was seen before.
+const ReasonDuplicate = "duplicate"
+
+// AuditReport summarizes the validity of a set of IDs, such as the IDs of a data migration, as
+// returned by Rigid.Audit.
+type AuditReport struct {
+	Total int `json:"total"`
+	Valid int `json:"valid"`
+	// Failures counts the invalid IDs by FailureReason.
+	Failures map[string]int `json:"failures"`
+	// Duplicates counts the valid IDs whose ULID appeared in an earlier valid ID, which are
+	// counted as valid too.
+	Duplicates int `json:"duplicates"`
+	// Oldest and Newest are the earliest and latest timestamps of the valid IDs, or the zero
+	// time if there are none or r generates unordered IDs.
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+	// Months counts the valid IDs by the UTC month of their timestamp, as "2006-01".
+	Months map[string]int `json:"months"`
+	// Samples holds the first MaxAuditSamples invalid and duplicate IDs.
+	Samples []AuditSample `json:"samples"`
+}
+
+// AuditSample is an ID rejected by Rigid.Audit.
+type AuditSample struct {
+	// Index is the position of the ID in the audited sequence, counting from zero.
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	// Reason is the FailureReason of the ID, or ReasonDuplicate.
+	Reason string `json:"reason"`
+}
+
+// Invalid returns the number of invalid IDs.
+func (rep AuditReport) Invalid() int {
+	return rep.Total - rep.Valid
+}
+
+// Audit verifies every ID of ids and reports counts by failure reason, duplicate ULIDs, the
+// distribution of timestamps and samples of rejected IDs, for validating bulk imports. IDs are
+// verified like Verify, without consulting the revocation and replay stores, so auditing does
+// not use up single-use IDs, and without being counted in Stats or reported to hooks. Duplicate
+// detection keeps the ULIDs of all valid IDs in memory.
+// The audit trail of WithAuditSink is unrelated.
+func (r *Rigid) Audit(ids iter.Seq[string]) AuditReport {
+	rep := AuditReport{Failures: make(map[string]int), Months: make(map[string]int)}
+	seen := make(map[string]struct{})
+	sample := func(i int, id, reason string) {
+		if len(rep.Samples) < MaxAuditSamples {
+			rep.Samples = append(rep.Samples, AuditSample{Index: i, ID: id, Reason: reason})
+		}
+	}
+
+	for id := range ids {
+		i := rep.Total
+		rep.Total++
+		result, err := r.verify(id)
+		if err != nil {
+			reason := FailureReason(err)
+			rep.Failures[reason]++
+			sample(i, id, reason)
+			continue
+		}
+
+		rep.Valid++
+		if _, dup := seen[result.ULID]; dup {
+			rep.Duplicates++
+			sample(i, id, ReasonDuplicate)
+		}
+		seen[result.ULID] = struct{}{}
+
+		if ts := result.Timestamp; !ts.IsZero() {
+			if rep.Oldest.IsZero() || ts.Before(rep.Oldest) {
+				rep.Oldest = ts
+			}
+			if ts.After(rep.Newest) {
+				rep.Newest = ts
+			}
+			rep.Months[ts.UTC().Format("2006-01")]++
+		}
+	}
+	return rep
+}
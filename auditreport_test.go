@@ -0,0 +1,78 @@
+package rigid
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	jan := time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, time.February, 3, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for _, ts := range []time.Time{jan, jan.Add(time.Hour), feb} {
+		id, err := r.GenerateAt(ts, "user:alice")
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	u, err := r.ExtractULID(ids[0])
+	require.NoError(t, err)
+	sameULID, err := r.Sign(u, "user:bob")
+	require.NoError(t, err)
+	forged := ids[1][:27] + "AAAAAAAAAAAAA-user:alice"
+
+	ids = append(ids, ids[2], sameULID, forged, "garbage")
+	rep := r.Audit(slices.Values(ids))
+
+	assert.Equal(t, 7, rep.Total)
+	assert.Equal(t, 5, rep.Valid)
+	assert.Equal(t, 2, rep.Invalid())
+	assert.Equal(t, map[string]int{ReasonIntegrity: 1, ReasonInvalidFormat: 1}, rep.Failures)
+	assert.Equal(t, 2, rep.Duplicates)
+	assert.True(t, jan.Equal(rep.Oldest))
+	assert.True(t, feb.Equal(rep.Newest))
+	assert.Equal(t, map[string]int{"2025-01": 3, "2025-02": 2}, rep.Months)
+	assert.Equal(t, []AuditSample{
+		{Index: 3, ID: ids[2], Reason: ReasonDuplicate},
+		{Index: 4, ID: sameULID, Reason: ReasonDuplicate},
+		{Index: 5, ID: forged, Reason: ReasonIntegrity},
+		{Index: 6, ID: "garbage", Reason: ReasonInvalidFormat},
+	}, rep.Samples)
+}
+
+func TestAuditSamplesBounded(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	ids := make([]string, 3*MaxAuditSamples)
+	for i := range ids {
+		ids[i] = "not-an-id"
+	}
+	rep := r.Audit(slices.Values(ids))
+	assert.Equal(t, len(ids), rep.Failures[ReasonInvalidULID])
+	assert.Len(t, rep.Samples, MaxAuditSamples)
+	assert.Zero(t, rep.Valid)
+	assert.True(t, rep.Oldest.IsZero())
+}
+
+func TestAuditSkipsStores(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	store := newMapStore()
+	single := r.WithReplayStore(store, 0)
+
+	id, err := single.Generate()
+	require.NoError(t, err)
+	rep := single.Audit(slices.Values([]string{id}))
+	assert.Equal(t, 1, rep.Valid)
+
+	_, err = single.Verify(id)
+	assert.NoError(t, err, "auditing does not use up single-use IDs")
+	assert.Equal(t, uint64(1), single.Stats().Verified, "audits are not counted")
+}
@@ -0,0 +1,119 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchResult is the outcome of verifying one ID of a batch.
+type BatchResult struct {
+	ID     string
+	Result VerifyResult
+	// Err is the verification error, or nil if the ID is valid.
+	Err error
+}
+
+// BatchStats summarizes a batch verification. Every ID is counted exactly once.
+type BatchStats struct {
+	Total int
+	Valid int
+	// Invalid counts IDs that are malformed, too long, fail the integrity check, or whose
+	// metadata, timestamp or issuer is not accepted.
+	Invalid  int
+	Expired  int
+	Revoked  int
+	Replayed int
+	// Failed counts IDs that could not be checked, because a store failed or the context ended.
+	Failed int
+	// Duration is the wall-clock time taken by the batch.
+	Duration time.Duration
+}
+
+func (s *BatchStats) add(err error) {
+	s.Total++
+	switch {
+	case err == nil:
+		s.Valid++
+	case errors.Is(err, ErrExpired):
+		s.Expired++
+	case errors.Is(err, ErrRevoked):
+		s.Revoked++
+	case errors.Is(err, ErrReplayed):
+		s.Replayed++
+	case errors.Is(err, ErrInvalidFormat), errors.Is(err, ErrInvalidULID), errors.Is(err, ErrIntegrityFailure),
+		errors.Is(err, ErrTooLong), errors.Is(err, ErrInvalidMetadata), errors.Is(err, ErrTimestampOutOfRange),
+		errors.Is(err, ErrUntrustedIssuer):
+		s.Invalid++
+	default:
+		s.Failed++
+	}
+}
+
+func (s *BatchStats) merge(o BatchStats) {
+	s.Total += o.Total
+	s.Valid += o.Valid
+	s.Invalid += o.Invalid
+	s.Expired += o.Expired
+	s.Revoked += o.Revoked
+	s.Replayed += o.Replayed
+	s.Failed += o.Failed
+}
+
+// VerifyBatchParallel verifies ids with VerifyContext across a pool of workers, for bulk audits.
+// Results are returned in the order of ids. If workers is zero or negative, runtime.GOMAXPROCS(0)
+// workers are used.
+// Once ctx is done, the remaining IDs are not checked: their results carry ctx.Err(), they are
+// counted as failed, and ctx.Err() is returned.
+func (r *Rigid) VerifyBatchParallel(ctx context.Context, ids []string, workers int) ([]BatchResult, BatchStats, error) {
+	start := time.Now()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(min(workers, len(ids)), 1)
+
+	results := make([]BatchResult, len(ids))
+	workerStats := make([]BatchStats, workers)
+	var next atomic.Int64
+	var skipped atomic.Bool
+	var wg sync.WaitGroup
+
+	for w := range workerStats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats := &workerStats[w]
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(ids) {
+					return
+				}
+
+				res := BatchResult{ID: ids[i]}
+				if err := ctx.Err(); err != nil {
+					res.Err = err
+					skipped.Store(true)
+				} else {
+					res.Result, res.Err = r.VerifyContext(ctx, ids[i])
+				}
+				results[i] = res
+				stats.add(res.Err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var stats BatchStats
+	for _, s := range workerStats {
+		stats.merge(s)
+	}
+	stats.Duration = time.Since(start)
+
+	if skipped.Load() {
+		return results, stats, ctx.Err()
+	}
+	return results, stats, nil
+}
@@ -0,0 +1,96 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBatchParallel(t *testing.T) {
+	store := newMapStore()
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour).WithRevocationStore(store)
+
+	var ids []string
+	for range 100 {
+		id, err := r.Generate("m")
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	expired, err := r.GenerateAt(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(context.Background(), ids[3], 0))
+	ids = append(ids, expired, "garbage", ids[0][:27]+"AAAAAAAAAAAAA-m")
+
+	for _, workers := range []int{0, 1, 7, 1000} {
+		results, stats, err := r.VerifyBatchParallel(context.Background(), ids, workers)
+		require.NoError(t, err)
+		require.Len(t, results, len(ids))
+
+		for i, res := range results {
+			assert.Equal(t, ids[i], res.ID, "results are in input order")
+		}
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "m", results[0].Result.Metadata)
+		assert.ErrorIs(t, results[3].Err, ErrRevoked)
+		assert.ErrorIs(t, results[100].Err, ErrExpired)
+		assert.ErrorIs(t, results[102].Err, ErrIntegrityFailure)
+
+		assert.Equal(t, BatchStats{
+			Total:    103,
+			Valid:    99,
+			Invalid:  2,
+			Expired:  1,
+			Revoked:  1,
+			Duration: stats.Duration,
+		}, stats)
+	}
+
+	results, stats, err := r.VerifyBatchParallel(context.Background(), nil, 4)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Zero(t, stats.Total)
+}
+
+func TestVerifyBatchParallelErrors(t *testing.T) {
+	store := newMapStore()
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	// Store failures are counted separately from invalid IDs
+	store.err = errors.New("unavailable")
+	_, stats, err := r.WithRevocationStore(store).VerifyBatchParallel(context.Background(), []string{id, "garbage"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, 1, stats.Invalid)
+
+	// Invalid input is invalid, whatever check rejects it
+	bounded := r.WithTimestampBounds(time.Now().Add(time.Hour), time.Time{}).WithPrintableMetadata()
+	results, stats, err := bounded.VerifyBatchParallel(context.Background(), []string{
+		id,
+		id + "-" + strings.Repeat("m", DefaultMaxLength),
+		id + "-\x00",
+		"acme._" + id,
+	}, 2)
+	require.NoError(t, err)
+	for i, want := range []error{ErrTimestampOutOfRange, ErrTooLong, ErrInvalidMetadata, ErrUntrustedIssuer} {
+		assert.ErrorIs(t, results[i].Err, want)
+	}
+	assert.Equal(t, 4, stats.Invalid)
+	assert.Zero(t, stats.Failed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, stats, err = r.VerifyBatchParallel(ctx, []string{id, id}, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, results[1].Err, context.Canceled)
+	assert.Equal(t, 2, stats.Failed)
+}
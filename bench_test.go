@@ -0,0 +1,16 @@
+package rigid_test
+
+import (
+	"testing"
+
+	"github.com/bahadrix/rigid-go/internal/benchmarks"
+)
+
+// BenchmarkSuite runs the suite also run by the rigid bench command:
+//
+//	go test -run '^$' -bench Suite
+func BenchmarkSuite(b *testing.B) {
+	for _, bm := range benchmarks.All() {
+		b.Run(bm.Name, bm.F)
+	}
+}
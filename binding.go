@@ -0,0 +1,26 @@
+package rigid
+
+import "bytes"
+
+// WithContext returns a copy of r whose signatures additionally bind b, request-specific context
+// supplied out of band, such as a hash of the client's TLS fingerprint or IP address. The
+// context is not carried in the ID: an ID generated with a context only verifies where the same
+// context is presented, with an instance derived alike or with the verify option WithContext,
+// so a token lifted from one client fails verification with ErrIntegrityFailure on another.
+// Contexts combine with namespaces, and an empty b removes the binding. Deriving the copy costs
+// a key derivation, cheap enough to do per request.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithContext(b []byte) *Rigid {
+	c := r.clone()
+	c.boundContext = nil
+	if len(b) > 0 {
+		c.boundContext = bytes.Clone(b)
+	}
+	c.deriveMACKey()
+	return c
+}
+
+// contextKey derives the key signing the IDs bound to context b from key.
+func contextKey(key, b []byte) []byte {
+	return hkdf(key, formatDomain+"context\x00"+string(b))
+}
@@ -0,0 +1,74 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithContext(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	alice := []byte("fingerprint-of-alice")
+	mallory := []byte("fingerprint-of-mallory")
+
+	bound := r.WithContext(alice)
+	assert.True(t, bound.Config().BoundContext)
+	assert.False(t, r.Config().BoundContext)
+	id, err := bound.Generate("session:1")
+	require.NoError(t, err)
+
+	_, err = bound.Verify(id)
+	assert.NoError(t, err)
+	_, err = r.WithContext(alice).Verify(id)
+	assert.NoError(t, err)
+	_, err = r.Verify(id, WithContext(alice))
+	assert.NoError(t, err)
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "bound IDs need their context")
+	_, err = r.Verify(id, WithContext(mallory))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.VerifyContext(context.Background(), id, WithContext(mallory))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	plain, err := r.Generate("session:1")
+	require.NoError(t, err)
+	_, err = r.Verify(plain, WithContext(alice))
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "unbound IDs do not verify in a context")
+	_, err = bound.WithContext(nil).Verify(plain)
+	assert.NoError(t, err, "an empty context removes the binding")
+	_, err = bound.WithContext(mallory).Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "contexts replace rather than nest")
+}
+
+func TestWithContextNamespace(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithFormatVersion(FormatV3)
+	require.NoError(t, err)
+	fp := []byte("fingerprint")
+
+	id, err := r.WithNamespace("billing").WithContext(fp).Generate()
+	require.NoError(t, err)
+
+	_, err = r.WithContext(fp).WithNamespace("billing").Verify(id)
+	assert.NoError(t, err, "the order of derivation does not matter")
+	_, err = r.WithNamespace("billing").Verify(id, WithContext(fp))
+	assert.NoError(t, err)
+	_, err = r.Verify(id, WithContext(fp))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.WithNamespace("payments").Verify(id, WithContext(fp))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// The context is copied
+	b := []byte("fingerprint")
+	bound := r.WithContext(b)
+	b[0] = 'X'
+	id, err = bound.Generate()
+	require.NoError(t, err)
+	_, err = r.Verify(id, WithContext(fp))
+	assert.NoError(t, err)
+}
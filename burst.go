@@ -0,0 +1,108 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Burst describes a burst of integrity failures detected by a BurstDetector: more than Threshold
+// failures occurred within Window.
+type Burst struct {
+	// Threshold is the threshold of the detector.
+	Threshold int
+	// Window is the window of the detector.
+	Window time.Duration
+	// At is the time the threshold was exceeded.
+	At time.Time
+	// KeyID is the key ID of the instance that recorded the last failure, see Rigid.KeyID.
+	KeyID string
+	// Remote is the remote party of the last failure stored by ContextWithRemote, or nil.
+	Remote any
+}
+
+// BurstDetector watches the rate of integrity failures, which spikes under forgery attempts or when
+// services verify with a different key than the one that issued the IDs. It is safe for concurrent
+// use and may be shared by several instances.
+type BurstDetector struct {
+	threshold int
+	window    time.Duration
+	onBurst   func(Burst)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	times   []time.Time // the last threshold+1 failure times, a ring starting at next
+	next    int
+	quietTo time.Time
+}
+
+// NewBurstDetector returns a BurstDetector calling onBurst when more than threshold integrity
+// failures occur within window. After firing, it stays quiet for window, so a sustained attack is
+// reported once per window. onBurst is called synchronously on the verifying goroutine and should
+// return quickly.
+func NewBurstDetector(threshold int, window time.Duration, onBurst func(Burst)) *BurstDetector {
+	return &BurstDetector{
+		threshold: max(threshold, 0),
+		window:    window,
+		onBurst:   onBurst,
+		now:       time.Now,
+		times:     make([]time.Time, 0, max(threshold, 0)+1),
+	}
+}
+
+// burstDetector is the burst detector of an instance along with its key ID, computed once.
+type burstDetector struct {
+	*BurstDetector
+	keyID string
+}
+
+// WithBurstDetector returns a copy of r reporting its integrity failures to d, or to no detector
+// if d is nil. The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithBurstDetector(d *BurstDetector) *Rigid {
+	c := r.clone()
+	c.bursts = nil
+	if d != nil {
+		c.bursts = &burstDetector{BurstDetector: d, keyID: r.KeyID()}
+	}
+	return c
+}
+
+// failed records a verification of the instance that failed with err.
+func (b *burstDetector) failed(ctx context.Context, err error) {
+	b.record(ctx, b.keyID, err)
+}
+
+// record records a verification of an instance with key ID keyID that failed with err.
+func (d *BurstDetector) record(ctx context.Context, keyID string, err error) {
+	if !errors.Is(err, ErrIntegrityFailure) {
+		return
+	}
+
+	now := d.now()
+	d.mu.Lock()
+	if len(d.times) < cap(d.times) {
+		d.times = append(d.times, now)
+	} else {
+		d.times[d.next] = now
+		d.next = (d.next + 1) % len(d.times)
+	}
+
+	// The ring is full and its oldest failure lies within the window, so more than threshold
+	// failures occurred within it
+	fire := len(d.times) == cap(d.times) && now.Sub(d.times[d.next]) < d.window && !now.Before(d.quietTo)
+	if fire {
+		d.quietTo = now.Add(d.window)
+	}
+	d.mu.Unlock()
+
+	if fire {
+		d.onBurst(Burst{
+			Threshold: d.threshold,
+			Window:    d.window,
+			At:        now,
+			KeyID:     keyID,
+			Remote:    RemoteFromContext(ctx),
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurstDetector(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+	forged := id[:27] + "AAAAAAAAAAAAA"
+
+	var bursts []Burst
+	d := NewBurstDetector(3, time.Minute, func(b Burst) { bursts = append(bursts, b) })
+	now := time.Unix(1700000000, 0)
+	d.now = func() time.Time { return now }
+	watched := r.WithBurstDetector(d)
+	ctx := ContextWithRemote(context.Background(), "192.0.2.1:4711")
+
+	// Only integrity failures count, and spread out they stay below the threshold
+	for range 5 {
+		_, _ = watched.Verify(id)
+		_, _ = watched.Verify("garbage")
+		_, _ = watched.VerifyContext(ctx, forged)
+		now = now.Add(30 * time.Second)
+	}
+	assert.Empty(t, bursts)
+	now = now.Add(time.Hour)
+
+	// More than threshold failures within the window fire once
+	for range 4 {
+		_, _ = watched.VerifyContext(ctx, forged)
+		now = now.Add(time.Second)
+	}
+	require.Len(t, bursts, 1)
+	assert.Equal(t, Burst{Threshold: 3, Window: time.Minute, At: now.Add(-time.Second), KeyID: r.KeyID(), Remote: "192.0.2.1:4711"}, bursts[0])
+
+	// A sustained burst is reported once per window
+	for range 100 {
+		_, _ = watched.VerifyBytes([]byte(forged))
+		now = now.Add(time.Second)
+	}
+	assert.Len(t, bursts, 2)
+
+	// Signatures of another length are integrity failures too
+	now = now.Add(time.Hour)
+	long, err := r.WithSignatureLength(16)
+	require.NoError(t, err)
+	longID, err := long.Generate()
+	require.NoError(t, err)
+	for range 4 {
+		_, err = watched.Verify(longID)
+		assert.ErrorIs(t, err, ErrSignatureLengthMismatch)
+	}
+	assert.Len(t, bursts, 3)
+
+	// The instance the detector was set on is unaffected
+	for range 10 {
+		_, _ = r.Verify(forged)
+		_, _ = watched.WithBurstDetector(nil).Verify(forged)
+	}
+	assert.Len(t, bursts, 3)
+}
+
+func TestBurstDetectorShared(t *testing.T) {
+	a, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	b, err := NewRigid([]byte("another-secret-key-for-testing"))
+	require.NoError(t, err)
+	id, err := a.Generate()
+	require.NoError(t, err)
+
+	// A detector shared by instances sees the failures of all of them, such as IDs of
+	// another key arriving at a misconfigured service
+	var bursts []Burst
+	d := NewBurstDetector(1, time.Minute, func(b Burst) { bursts = append(bursts, b) })
+	_, _ = a.WithBurstDetector(d).Verify(id[:27] + "AAAAAAAAAAAAA")
+	_, _ = b.WithBurstDetector(d).Verify(id)
+	require.Len(t, bursts, 1)
+	assert.Equal(t, b.KeyID(), bursts[0].KeyID)
+}
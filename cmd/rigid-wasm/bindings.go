@@ -0,0 +1,69 @@
+// Command rigid-wasm exposes generation and verification of rigid IDs to JavaScript, so browser
+// and edge runtime code can verify IDs without a network hop. It is built for js/wasm:
+//
+//	GOOS=js GOARCH=wasm go build -o rigid.wasm ./cmd/rigid-wasm
+//
+// and loaded with rigid.mjs in this directory, which wraps the function the module registers:
+//
+//	rigidNew(key Uint8Array, signatureLength, prefix, ttlMillis) -> {generate, verify} or {error}
+//
+// Failures are returned in an error property rather than thrown; rigid.mjs turns them into
+// exceptions, except for verification failures, which are reported in the result.
+package main
+
+import (
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// newInstance returns the instance configured by the arguments of rigidNew. A zero signature
+// length selects the default, and a zero ttl disables expiry.
+func newInstance(key []byte, signatureLength int, prefix string, ttl time.Duration) (*rigid.Rigid, error) {
+	var sigLen []int
+	if signatureLength != 0 {
+		sigLen = append(sigLen, signatureLength)
+	}
+	r, err := rigid.NewRigid(key, sigLen...)
+	if err != nil {
+		return nil, err
+	}
+	if r, err = r.WithPrefix(prefix); err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		r = r.WithTTL(ttl)
+	}
+	return r, nil
+}
+
+// generate returns the JavaScript result of generating an ID with r: {id} or {error}.
+func generate(r *rigid.Rigid, metadata string) map[string]any {
+	id, err := r.Generate(metadata)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return map[string]any{"id": id}
+}
+
+// verify returns the JavaScript result of verifying id with r. Times are Unix milliseconds, and
+// error is set only if verification failed.
+func verify(r *rigid.Rigid, id string) map[string]any {
+	result, err := r.Verify(id)
+	res := map[string]any{
+		"valid":    result.Valid,
+		"ulid":     result.ULID,
+		"metadata": result.Metadata,
+		"issuer":   result.Issuer,
+	}
+	if !result.Timestamp.IsZero() {
+		res["timestamp"] = result.Timestamp.UnixMilli()
+	}
+	if !result.ExpiresAt.IsZero() {
+		res["expiresAt"] = result.ExpiresAt.UnixMilli()
+	}
+	if err != nil {
+		res["error"] = err.Error()
+	}
+	return res
+}
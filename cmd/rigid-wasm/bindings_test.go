@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+const testSecretKey = "test-secret-key-for-rigid-testing"
+
+func TestBindings(t *testing.T) {
+	r, err := newInstance([]byte(testSecretKey), 12, "ord", time.Hour)
+	require.NoError(t, err)
+
+	res := generate(r, "order:42")
+	require.NotContains(t, res, "error")
+	id := res["id"].(string)
+	parts, err := rigid.Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, "ord", parts.Prefix)
+	assert.Equal(t, 12, parts.SignatureLength)
+
+	res = verify(r, id)
+	assert.Equal(t, true, res["valid"])
+	assert.Equal(t, parts.ULID, res["ulid"])
+	assert.Equal(t, "order:42", res["metadata"])
+	assert.Equal(t, res["timestamp"].(int64)+time.Hour.Milliseconds(), res["expiresAt"])
+	assert.NotContains(t, res, "error")
+
+	res = verify(r, "ord_"+id[4:31]+"AAAAAAAAAAAAAAAAAAAA"+id[51:])
+	assert.Equal(t, false, res["valid"])
+	assert.NotEmpty(t, res["error"])
+	assert.NotContains(t, res, "timestamp")
+}
+
+func TestBindingsDefaults(t *testing.T) {
+	r, err := newInstance([]byte(testSecretKey), 0, "", 0)
+	require.NoError(t, err)
+	res := verify(r, generate(r, "")["id"].(string))
+	assert.Equal(t, true, res["valid"])
+	assert.NotContains(t, res, "expiresAt")
+
+	_, err = newInstance(nil, 0, "", 0)
+	assert.ErrorIs(t, err, rigid.ErrEmptySecretKey)
+	_, err = newInstance([]byte(testSecretKey), 0, "a-b", 0)
+	assert.ErrorIs(t, err, rigid.ErrInvalidPrefix)
+}
@@ -0,0 +1,30 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+	"time"
+)
+
+func main() {
+	js.Global().Set("rigidNew", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		key := make([]byte, args[0].Length())
+		js.CopyBytesToGo(key, args[0])
+		r, err := newInstance(key, args[1].Int(), args[2].String(), time.Duration(args[3].Int())*time.Millisecond)
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+
+		// The functions live as long as the module, like the instances they belong to
+		return map[string]any{
+			"generate": js.FuncOf(func(_ js.Value, args []js.Value) any {
+				return generate(r, args[0].String())
+			}),
+			"verify": js.FuncOf(func(_ js.Value, args []js.Value) any {
+				return verify(r, args[0].String())
+			}),
+		}
+	}))
+	select {}
+}
@@ -0,0 +1,83 @@
+// JavaScript bindings of rigid.wasm, the js/wasm build of rigid-go, for browsers, Node.js and
+// edge runtimes such as Cloudflare Workers. Build the module and copy the Go runtime shim next to
+// this file:
+//
+//   GOOS=js GOARCH=wasm go build -o rigid.wasm ./cmd/rigid-wasm
+//   cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Usage:
+//
+//   import { load } from "./rigid.mjs";
+//
+//   const rigid = await load(fetch("rigid.wasm"));
+//   const r = rigid.newRigid(secretKey, { prefix: "ord", ttl: 24 * 60 * 60 * 1000 });
+//   const id = r.generate("order:42");
+//   const result = r.verify(id); // { valid, ulid, metadata, timestamp, expiresAt, issuer, error }
+
+import "./wasm_exec.js";
+
+const encoder = new TextEncoder();
+
+// load instantiates the module from a WebAssembly.Module, as imported by Cloudflare Workers, a
+// Response or a promise of one, as returned by fetch, or the bytes of rigid.wasm.
+export async function load(source) {
+  const go = new Go();
+  let instance;
+  source = await source;
+  if (source instanceof WebAssembly.Module) {
+    instance = await WebAssembly.instantiate(source, go.importObject);
+  } else if (typeof Response !== "undefined" && source instanceof Response) {
+    ({ instance } = await WebAssembly.instantiateStreaming(source, go.importObject));
+  } else {
+    ({ instance } = await WebAssembly.instantiate(source, go.importObject));
+  }
+  // main registers rigidNew and then blocks, so the returned promise never resolves
+  go.run(instance);
+  const rigidNew = globalThis.rigidNew;
+  delete globalThis.rigidNew;
+
+  return {
+    // newRigid returns an instance signing with key, a string or Uint8Array. Options are
+    // signatureLength in bytes, the type prefix and the ttl of IDs in milliseconds.
+    newRigid(key, { signatureLength = 0, prefix = "", ttl = 0 } = {}) {
+      const bytes = typeof key === "string" ? encoder.encode(key) : key;
+      const r = rigidNew(bytes, signatureLength, prefix, ttl);
+      if (r.error) {
+        throw new Error(r.error);
+      }
+      return new Rigid(r);
+    },
+  };
+}
+
+class Rigid {
+  #r;
+
+  constructor(r) {
+    this.#r = r;
+  }
+
+  // generate returns a new ID carrying the optional metadata.
+  generate(metadata = "") {
+    const res = this.#r.generate(metadata);
+    if (res.error) {
+      throw new Error(res.error);
+    }
+    return res.id;
+  }
+
+  // verify verifies id. Failures are reported by valid and error rather than thrown; timestamp
+  // and expiresAt are Dates, or undefined if unknown.
+  verify(id) {
+    const res = this.#r.verify(id);
+    return {
+      valid: res.valid,
+      ulid: res.ulid,
+      metadata: res.metadata,
+      timestamp: res.timestamp === undefined ? undefined : new Date(res.timestamp),
+      expiresAt: res.expiresAt === undefined ? undefined : new Date(res.expiresAt),
+      issuer: res.issuer,
+      error: res.error,
+    };
+  }
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// errStopAudit stops reading input when the audit is done with it.
+var errStopAudit = errors.New("audit stopped")
+
+// audit verifies IDs and prints a report of their validity: counts by failure reason, duplicate
+// ULIDs, the distribution of timestamps by month, and samples of rejected IDs. Without
+// arguments, IDs are read from standard input, one per line. Revocation stores are not
+// consulted. Exits with status 1 if any ID is invalid or duplicated.
+func (c *cli) audit(args []string) error {
+	fs := c.flagSet("audit", "[id...]")
+	var rf rigidFlags
+	rf.register(fs)
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil {
+		return err
+	}
+
+	var readErr error
+	rep := r.Audit(func(yield func(string) bool) {
+		readErr = c.eachInput(fs.Args(), func(id string) error {
+			if !yield(id) {
+				return errStopAudit
+			}
+			return nil
+		})
+	})
+	if readErr != nil && !errors.Is(readErr, errStopAudit) {
+		return readErr
+	}
+
+	if *asJSON {
+		err = writeJSON(c.stdout, rep)
+	} else {
+		err = writeAuditReport(c.stdout, rep)
+	}
+	if err != nil {
+		return err
+	}
+
+	if rep.Invalid() > 0 || rep.Duplicates > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+// writeAuditReport writes rep as aligned "field: value" lines, followed by the failure reasons,
+// months and samples.
+func writeAuditReport(w io.Writer, rep rigid.AuditReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "total:\t%d\n", rep.Total)
+	fmt.Fprintf(tw, "valid:\t%d\n", rep.Valid)
+	fmt.Fprintf(tw, "duplicates:\t%d\n", rep.Duplicates)
+	fmt.Fprintf(tw, "invalid:\t%d\n", rep.Invalid())
+	for _, reason := range slices.Sorted(maps.Keys(rep.Failures)) {
+		fmt.Fprintf(tw, "  %s:\t%d\n", reason, rep.Failures[reason])
+	}
+	if !rep.Oldest.IsZero() {
+		fmt.Fprintf(tw, "oldest:\t%s\n", rep.Oldest.UTC().Format(time.RFC3339Nano))
+		fmt.Fprintf(tw, "newest:\t%s\n", rep.Newest.UTC().Format(time.RFC3339Nano))
+	}
+	if len(rep.Months) > 0 {
+		fmt.Fprintf(tw, "months:\n")
+		for _, month := range slices.Sorted(maps.Keys(rep.Months)) {
+			fmt.Fprintf(tw, "  %s:\t%d\n", month, rep.Months[month])
+		}
+	}
+	if len(rep.Samples) > 0 {
+		fmt.Fprintf(tw, "samples:\n")
+		for _, s := range rep.Samples {
+			fmt.Fprintf(tw, "  %d:\t%s\t%s\n", s.Index+1, s.Reason, s.ID)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestAudit(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	ts := time.Date(2025, time.March, 9, 12, 0, 0, 0, time.UTC)
+	id, err := r.GenerateAt(ts, "order:42")
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, id+"\n", "audit")
+	assert.Equal(t, 0, res.code)
+	assert.Regexp(t, `(?m)^total: +1$`, res.stdout)
+	assert.Regexp(t, `(?m)^oldest: +2025-03-09T12:00:00Z$`, res.stdout)
+	assert.Regexp(t, `(?m)^  2025-03: +1$`, res.stdout)
+	assert.NotContains(t, res.stdout, "samples:")
+	assert.Empty(t, res.stderr)
+
+	forged := id[:27] + "AAAAAAAAAAAAA-order:42"
+	res = runCLI(t, keyEnv, strings.Join([]string{id, id, forged}, "\n"), "audit")
+	assert.Equal(t, 1, res.code)
+	assert.Regexp(t, `(?m)^duplicates: +1$`, res.stdout)
+	assert.Regexp(t, `(?m)^  integrity: +1$`, res.stdout)
+	assert.Regexp(t, `(?m)^  2: +duplicate +`+id+`$`, res.stdout)
+	assert.Regexp(t, `(?m)^  3: +integrity +`+forged+`$`, res.stdout)
+	assert.Empty(t, res.stderr)
+}
+
+func TestAuditJSON(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, "", "audit", "-json", id, "garbage")
+	assert.Equal(t, 1, res.code)
+
+	var rep rigid.AuditReport
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &rep))
+	assert.Equal(t, 2, rep.Total)
+	assert.Equal(t, 1, rep.Valid)
+	assert.Equal(t, map[string]int{rigid.ReasonInvalidFormat: 1}, rep.Failures)
+	require.Len(t, rep.Samples, 1)
+	assert.Equal(t, "garbage", rep.Samples[0].ID)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/bahadrix/rigid-go/internal/benchmarks"
+)
+
+// benchResult is the JSON form of a benchmark result.
+type benchResult struct {
+	Name        string             `json:"name"`
+	N           int                `json:"n"`
+	NsPerOp     int64              `json:"ns_per_op"`
+	BytesPerOp  int64              `json:"bytes_per_op"`
+	AllocsPerOp int64              `json:"allocs_per_op"`
+	Extra       map[string]float64 `json:"extra,omitempty"`
+}
+
+// bench runs the benchmark suite shared with the package tests and prints one line per benchmark.
+func (c *cli) bench(args []string) error {
+	fs := c.flagSet("bench", "")
+	run := fs.String("run", "", "only run benchmarks whose name matches `regexp`")
+	benchtime := fs.String("benchtime", "1s", "run each benchmark for `duration`, or a count such as 1000x")
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	match, err := regexp.Compile(*run)
+	if err != nil {
+		return err
+	}
+
+	// testing.Benchmark takes its run time from the flags of the testing package
+	testing.Init()
+	if err := flag.Set("test.benchtime", *benchtime); err != nil {
+		return fmt.Errorf("invalid -benchtime: %w", err)
+	}
+
+	if !*asJSON {
+		fmt.Fprintf(c.stdout, "goos: %s\ngoarch: %s\ngo: %s\ncpus: %d\n", runtime.GOOS, runtime.GOARCH, runtime.Version(), runtime.GOMAXPROCS(0))
+	}
+
+	for _, bm := range benchmarks.All() {
+		if !match.MatchString(bm.Name) {
+			continue
+		}
+
+		res := testing.Benchmark(bm.F)
+		if res.N == 0 {
+			return fmt.Errorf("benchmark %s failed", bm.Name)
+		}
+
+		out := benchResult{
+			Name:        bm.Name,
+			N:           res.N,
+			NsPerOp:     res.NsPerOp(),
+			BytesPerOp:  res.AllocedBytesPerOp(),
+			AllocsPerOp: res.AllocsPerOp(),
+			Extra:       res.Extra,
+		}
+		if *asJSON {
+			err = writeJSON(c.stdout, out)
+		} else {
+			err = writeBenchLine(c.stdout, out)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Benchmarks take a while, so show each result as soon as it is known
+		if f, ok := c.stdout.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeBenchLine writes a result in a layout similar to go test -bench.
+func writeBenchLine(w io.Writer, r benchResult) error {
+	line := fmt.Sprintf("%-32s %10d %12d ns/op %8d B/op %6d allocs/op", r.Name, r.N, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+	units := make([]string, 0, len(r.Extra))
+	for unit := range r.Extra {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	for _, unit := range units {
+		line += fmt.Sprintf(" %12.1f %s", r.Extra[unit], unit)
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBench(t *testing.T) {
+	res := runCLI(t, nil, "", "bench", "-benchtime", "10x", "-run", "^Verify/sig=8$")
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "cpus: ")
+	allocs := `0 B/op +0 allocs/op`
+	if raceEnabled {
+		allocs = `\d+ B/op +\d+ allocs/op`
+	}
+	assert.Regexp(t, `(?m)^Verify/sig=8 +10 +\d+ ns/op +`+allocs+`$`, res.stdout)
+	assert.NotContains(t, res.stdout, "Generate")
+
+	res = runCLI(t, nil, "", "bench", "-json", "-benchtime", "2x", "-run", "Batch")
+	require.Equal(t, 0, res.code, res.stderr)
+	var out benchResult
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &out))
+	assert.Equal(t, "VerifyBatchParallel", out.Name)
+	assert.Equal(t, 2, out.N)
+	assert.Contains(t, out.Extra, "ns/id")
+}
+
+func TestBenchInvalidFlags(t *testing.T) {
+	res := runCLI(t, nil, "", "bench", "-run", "(")
+	assert.Equal(t, 1, res.code)
+
+	res = runCLI(t, nil, "", "bench", "-benchtime", "soon")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "invalid -benchtime")
+}
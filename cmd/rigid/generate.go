@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// generated is the JSON form of a generated ID.
+type generated struct {
+	ID        string     `json:"id"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// generate prints newly generated IDs, one per line. With -ttl, each ID is followed by a tab
+// and its expiry time in RFC 3339 format. With -m -, metadata is read from standard input and
+// -n IDs are generated for each line.
+func (c *cli) generate(args []string) error {
+	fs := c.flagSet("generate", "")
+	var rf rigidFlags
+	rf.register(fs)
+	count := fs.Int("n", 1, "number of IDs to generate (per metadata line with -m -)")
+	asJSON := registerJSON(fs)
+	metadata := fs.String("m", "", "`metadata` to bind to the IDs, or - to read one metadata value per line from standard input")
+	issueLog := registerIssueLog(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *count < 1 {
+		return errors.New("-n must be positive")
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil {
+		return err
+	}
+	r, closeLog, err := c.withIssueLog(r, *issueLog)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	emit := func(metadata string) error {
+		for range *count {
+			now := time.Now()
+			id, err := r.GenerateAt(now, metadata)
+			if err != nil {
+				return err
+			}
+
+			g := generated{ID: id}
+			if ttl := r.TTL(); ttl > 0 {
+				expiresAt := now.Add(ttl).UTC()
+				g.ExpiresAt = &expiresAt
+			}
+
+			switch {
+			case *asJSON:
+				err = writeJSON(c.stdout, g)
+			case g.ExpiresAt != nil:
+				_, err = fmt.Fprintf(c.stdout, "%s\t%s\n", id, g.ExpiresAt.Format(time.RFC3339))
+			default:
+				_, err = fmt.Fprintln(c.stdout, id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if *metadata == "-" {
+		return c.eachLine(emit)
+	}
+	return emit(*metadata)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestGenerate(t *testing.T) {
+	res := runCLI(t, keyEnv, "", "generate", "-n", "3", "-m", "order:42", "-prefix", "ord", "-sig-len", "16")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	r, err := rigid.NewRigid([]byte(testSecretKey), 16)
+	require.NoError(t, err)
+	r, err = r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 3)
+	for _, id := range lines {
+		result, err := r.Verify(id)
+		require.NoError(t, err)
+		assert.Equal(t, "order:42", result.Metadata)
+	}
+}
+
+func TestGenerateTTL(t *testing.T) {
+	res := runCLI(t, keyEnv, "", "generate", "-ttl", "1h")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	id, expiry, ok := strings.Cut(strings.TrimSpace(res.stdout), "\t")
+	require.True(t, ok)
+	assert.True(t, rigid.IsWellFormed(id))
+
+	expiresAt, err := time.Parse(time.RFC3339, expiry)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, 2*time.Second)
+}
+
+func TestGenerateStdin(t *testing.T) {
+	res := runCLI(t, keyEnv, "user:alice\n\nuser:bob\n", "generate", "-m", "-", "-n", "2")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 6)
+	for i, want := range []string{"user:alice", "user:alice", "", "", "user:bob", "user:bob"} {
+		result, err := r.Verify(lines[i])
+		require.NoError(t, err)
+		assert.Equal(t, want, result.Metadata)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	assert.Equal(t, 1, runCLI(t, keyEnv, "", "generate", "-n", "0").code)
+	assert.Equal(t, 1, runCLI(t, keyEnv, "", "generate", "extra").code)
+	assert.Equal(t, 1, runCLI(t, keyEnv, "", "generate", "-sig-len", "2").code)
+	assert.Equal(t, 1, runCLI(t, keyEnv, "", "generate", "-prefix", "a-b").code)
+}
+
+func TestGenerateJSON(t *testing.T) {
+	res := runCLI(t, keyEnv, "", "generate", "-json", "-n", "2", "-ttl", "1h")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var g generated
+		require.NoError(t, json.Unmarshal([]byte(line), &g))
+		assert.True(t, rigid.IsWellFormed(g.ID))
+		require.NotNil(t, g.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *g.ExpiresAt, 2*time.Second)
+	}
+
+	res = runCLI(t, keyEnv, "", "generate", "-json")
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.NotContains(t, res.stdout, "expires_at")
+}
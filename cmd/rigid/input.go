@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// maxLineSize bounds the length of lines read from standard input.
+const maxLineSize = 1 << 20
+
+// eachInput calls fn for each argument or, if args is empty or just "-", for each non-empty
+// line of standard input. Inputs are processed as they are read, so arbitrarily long streams
+// are handled in constant memory.
+func (c *cli) eachInput(args []string, fn func(string) error) error {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		for _, arg := range args {
+			if err := fn(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return c.eachLine(func(line string) error {
+		if line == "" {
+			return nil
+		}
+		return fn(line)
+	})
+}
+
+// eachLine calls fn for each line of standard input, without its line terminator.
+func (c *cli) eachLine(fn func(string) error) error {
+	sc := bufio.NewScanner(c.stdin)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for sc.Scan() {
+		if err := fn(strings.TrimSuffix(sc.Text(), "\r")); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read standard input: %w", err)
+	}
+	return nil
+}
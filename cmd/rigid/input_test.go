@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T, c *cli, args []string) ([]string, error) {
+	t.Helper()
+
+	var got []string
+	err := c.eachInput(args, func(s string) error {
+		got = append(got, s)
+		return nil
+	})
+	return got, err
+}
+
+func TestEachInput(t *testing.T) {
+	c := &cli{stdin: strings.NewReader("a\r\n\nb\nc")}
+
+	got, err := collect(t, c, []string{"x", "y"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, got)
+
+	got, err = collect(t, c, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+}
+
+func TestEachInputLongLines(t *testing.T) {
+	long := strings.Repeat("m", 200_000)
+	c := &cli{stdin: strings.NewReader(long + "\n")}
+
+	got, err := collect(t, c, []string{"-"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{long}, got)
+
+	c = &cli{stdin: strings.NewReader(strings.Repeat("m", maxLineSize+1))}
+	_, err = collect(t, c, nil)
+	assert.ErrorContains(t, err, "read standard input")
+}
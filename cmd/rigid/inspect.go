@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/server"
+)
+
+// inspect prints the segments of each ID, read from the arguments or standard input.
+// IDs are verified when a secret key is configured, using the prefix found in the ID and the
+// signature length of -sig-len; without a key only their structure is checked.
+func (c *cli) inspect(args []string) error {
+	fs := c.flagSet("inspect", "[id...]")
+	var rf rigidFlags
+	rf.registerKey(fs)
+	fs.IntVar(&rf.sigLen, "sig-len", rigid.DefaultSignatureLength, "signature length in `bytes`")
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil && !errors.Is(err, errNoKey) {
+		return err
+	}
+
+	failed, first := false, true
+	err = c.eachInput(fs.Args(), func(id string) error {
+		in := server.Inspect(r, id)
+		if in.Error != "" || (in.Verified != nil && !*in.Verified) {
+			failed = true
+		}
+
+		if *asJSON {
+			return writeJSON(c.stdout, in)
+		}
+		if !first {
+			fmt.Fprintln(c.stdout)
+		}
+		first = false
+		return writeInspection(c.stdout, in)
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		return errInvalid
+	}
+	return nil
+}
+
+// writeInspection writes in as aligned "field: value" lines.
+func writeInspection(w io.Writer, in server.InspectResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "id:\t%s\n", in.ID)
+
+	if in.ULID == "" {
+		fmt.Fprintf(tw, "error:\t%s\n", in.Error)
+		return tw.Flush()
+	}
+
+	if in.Prefix != "" {
+		fmt.Fprintf(tw, "prefix:\t%s\n", in.Prefix)
+	}
+	fmt.Fprintf(tw, "ulid:\t%s\n", in.ULID)
+	fmt.Fprintf(tw, "timestamp:\t%s\n", in.Timestamp.Format(time.RFC3339Nano))
+	fmt.Fprintf(tw, "signature:\t%s (%d bytes)\n", in.Signature, in.SignatureLength)
+	if in.Metadata != "" {
+		fmt.Fprintf(tw, "metadata:\t%s\n", in.Metadata)
+	}
+
+	switch {
+	case in.Verified == nil:
+		fmt.Fprintf(tw, "verified:\tunknown (no secret key)\n")
+	case *in.Verified:
+		fmt.Fprintf(tw, "verified:\ttrue\n")
+	default:
+		fmt.Fprintf(tw, "verified:\tfalse (%s)\n", in.Error)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestInspect(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey), 12)
+	require.NoError(t, err)
+	r, err = r.WithPrefix("usr")
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, "", "inspect", "-sig-len", "12", id)
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "prefix:     usr\n")
+	assert.Contains(t, res.stdout, "ulid:       "+id[4:30]+"\n")
+	assert.Contains(t, res.stdout, "(12 bytes)\n")
+	assert.Contains(t, res.stdout, "metadata:   user:alice\n")
+	assert.Contains(t, res.stdout, "verified:   true\n")
+
+	// Signatures are only verified at the configured length
+	res = runCLI(t, keyEnv, "", "inspect", id)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stdout, "verified:   false (integrity verification failed: signature length mismatch)\n")
+
+	res = runCLI(t, nil, "", "inspect", id)
+	assert.Equal(t, 0, res.code)
+	assert.Contains(t, res.stdout, "verified:   unknown (no secret key)\n")
+
+	res = runCLI(t, map[string]string{envSecretKey: "another-key"}, "", "inspect", "-sig-len", "12", id)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stdout, "verified:   false (integrity verification failed)\n")
+}
+
+func TestInspectMalformed(t *testing.T) {
+	res := runCLI(t, nil, "", "inspect", "not-an-id")
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, "id:     not-an-id\nerror:  invalid ULID\n", res.stdout)
+}
+
+func TestInspectStdin(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, id+"\nnot-an-id\n", "inspect")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stdout, "id:         "+id+"\n")
+	assert.Contains(t, res.stdout, "verified:   true\n\nid:     not-an-id\nerror:  invalid ULID\n")
+}
+
+func TestInspectJSON(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.GenerateAt(time.Date(2016, 7, 30, 23, 54, 10, 259_000_000, time.UTC), "user:alice")
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, "", "inspect", "-json", id, "bad")
+	assert.Equal(t, 1, res.code)
+
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"id": "`+id+`",
+		"ulid": "`+id[:26]+`",
+		"timestamp": "2016-07-30T23:54:10.259Z",
+		"signature": "`+id[27:40]+`",
+		"signature_length": 8,
+		"metadata": "user:alice",
+		"verified": true
+	}`, lines[0])
+	assert.JSONEq(t, `{"id":"bad","error":"invalid rigid format"}`, lines[1])
+
+	res = runCLI(t, nil, "", "inspect", "-json", id)
+	assert.Equal(t, 0, res.code)
+	assert.NotContains(t, res.stdout, "verified")
+}
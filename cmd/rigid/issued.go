@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// envIssueLog names the issue log shared by generate, serve and issued.
+const envIssueLog = "RIGID_ISSUE_LOG"
+
+// registerIssueLog registers the -issue-log flag.
+func registerIssueLog(fs *flag.FlagSet) *string {
+	return fs.String("issue-log", "", "record every issued ID to the JSON lines `file` (default $"+envIssueLog+")")
+}
+
+// issueLogPath returns the issue log named by spec or, if spec is empty, by the environment.
+func (c *cli) issueLogPath(spec string) string {
+	if spec == "" {
+		return c.getenv(envIssueLog)
+	}
+	return spec
+}
+
+// withIssueLog returns r recording issued IDs to the issue log named by spec, if any, and a
+// function closing the log.
+func (c *cli) withIssueLog(r *rigid.Rigid, spec string) (*rigid.Rigid, func() error, error) {
+	path := c.issueLogPath(spec)
+	if path == "" {
+		return r, func() error { return nil }, nil
+	}
+	rec, err := rigid.OpenIssueFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.WithIssueRecorder(rec), rec.Close, nil
+}
+
+// csvHeader is the header row of issued export -csv.
+var csvHeader = []string{"ulid", "prefix", "metadata_hash", "key_id", "time"}
+
+// issued runs the subcommands of rigid issued. export prints the records of the issue log issued
+// within the -since and -until bounds, in the order they were recorded, as tab-separated text,
+// JSON lines or CSV.
+func (c *cli) issued(args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		c.flagSet("issued export", "").Usage()
+		return errors.New("expected the export subcommand")
+	}
+	fs := c.flagSet("issued export", "")
+	spec := registerIssueLog(fs)
+	asJSON := registerJSON(fs)
+	asCSV := fs.Bool("csv", false, "write CSV with a header row instead of text")
+	since := fs.String("since", "", "export IDs issued at or after `time`, in RFC 3339 format")
+	until := fs.String("until", "", "export IDs issued before `time`, in RFC 3339 format")
+	prefix := fs.String("prefix", "", "export IDs with the type `prefix` only")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *asJSON && *asCSV {
+		return errors.New("-json and -csv are mutually exclusive")
+	}
+
+	var from, to time.Time
+	var err error
+	if *since != "" {
+		if from, err = time.Parse(time.RFC3339, *since); err != nil {
+			return fmt.Errorf("-since: %w", err)
+		}
+	}
+	if *until != "" {
+		if to, err = time.Parse(time.RFC3339, *until); err != nil {
+			return fmt.Errorf("-until: %w", err)
+		}
+	}
+
+	path := c.issueLogPath(*spec)
+	if path == "" {
+		return errors.New("no issue log: set -issue-log or " + envIssueLog)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w *csv.Writer
+	if *asCSV {
+		w = csv.NewWriter(c.stdout)
+		if err := w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	err = rigid.ReadIssueRecords(f, func(rec rigid.IssueRecord) error {
+		if (!from.IsZero() && rec.Time.Before(from)) || (!to.IsZero() && !rec.Time.Before(to)) ||
+			(*prefix != "" && rec.Prefix != *prefix) {
+			return nil
+		}
+		issuedAt := rec.Time.UTC().Format(time.RFC3339Nano)
+		switch {
+		case *asJSON:
+			return writeJSON(c.stdout, rec)
+		case w != nil:
+			return w.Write([]string{rec.ULID, rec.Prefix, rec.MetadataHash, rec.KeyID, issuedAt})
+		default:
+			_, err := fmt.Fprintf(c.stdout, "%s\t%s\t%s\t%s\t%s\n", rec.ULID, issuedAt, rec.KeyID, orDash(rec.Prefix), orDash(rec.MetadataHash))
+			return err
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if w != nil {
+		w.Flush()
+		return w.Error()
+	}
+	return nil
+}
+
+// orDash returns s, or "-" if s is empty, for columns of text output.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
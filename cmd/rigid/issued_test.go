@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestIssued(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "issued.jsonl")
+	env := map[string]string{envSecretKey: testSecretKey, envIssueLog: log}
+
+	res := runCLI(t, env, "", "generate", "-n", "2", "-m", "user:alice")
+	require.Equal(t, 0, res.code, res.stderr)
+	ids := strings.Fields(res.stdout)
+	res = runCLI(t, keyEnv, "", "generate", "-prefix", "ord", "-issue-log", log)
+	require.Equal(t, 0, res.code, res.stderr)
+	ids = append(ids, strings.TrimSpace(res.stdout))
+	require.Len(t, ids, 3)
+
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+	hash := r.MetadataHash("user:alice")
+
+	res = runCLI(t, env, "", "issued", "export")
+	require.Equal(t, 0, res.code, res.stderr)
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 3)
+	fields := strings.Split(lines[0], "\t")
+	require.Len(t, fields, 5)
+	assert.Equal(t, ids[0][:26], fields[0])
+	assert.Equal(t, r.KeyID(), fields[2])
+	assert.Equal(t, "-", fields[3])
+	assert.Equal(t, hash, fields[4])
+	assert.True(t, strings.HasPrefix(lines[2], ids[2][4:30]+"\t"))
+	assert.True(t, strings.HasSuffix(lines[2], "\tord\t-"))
+
+	res = runCLI(t, env, "", "issued", "export", "-json", "-prefix", "ord")
+	require.Equal(t, 0, res.code, res.stderr)
+	var rec rigid.IssueRecord
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &rec))
+	assert.Equal(t, ids[2][4:30], rec.ULID)
+
+	res = runCLI(t, env, "", "issued", "export", "-csv", "-until", time.Now().Add(-time.Hour).Format(time.RFC3339))
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Equal(t, "ulid,prefix,metadata_hash,key_id,time\n", res.stdout)
+
+	res = runCLI(t, env, "", "issued", "export", "-csv", "-since", time.Now().Add(-time.Hour).Format(time.RFC3339))
+	require.Equal(t, 0, res.code, res.stderr)
+	assert.Len(t, strings.Split(strings.TrimSpace(res.stdout), "\n"), 4)
+}
+
+func TestIssuedErrors(t *testing.T) {
+	res := runCLI(t, nil, "", "issued")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "expected the export subcommand")
+
+	res = runCLI(t, nil, "", "issued", "export")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "no issue log")
+
+	env := map[string]string{envIssueLog: filepath.Join(t.TempDir(), "missing.jsonl")}
+	res = runCLI(t, env, "", "issued", "export", "-since", "yesterday")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "-since")
+	res = runCLI(t, env, "", "issued", "export", "-json", "-csv")
+	assert.Equal(t, 1, res.code)
+	res = runCLI(t, env, "", "issued", "export")
+	assert.Equal(t, 1, res.code)
+
+	// Generation fails rather than issuing unrecorded IDs
+	env = map[string]string{envSecretKey: testSecretKey, envIssueLog: filepath.Join(t.TempDir(), "missing", "issued.jsonl")}
+	res = runCLI(t, env, "", "generate")
+	assert.Equal(t, 1, res.code)
+	assert.Empty(t, res.stdout)
+}
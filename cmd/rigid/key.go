@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// Environment variables supplying the secret key.
+const (
+	envSecretKey = "RIGID_SECRET_KEY"
+	envKeyFile   = "RIGID_KEY_FILE"
+)
+
+// errNoKey is returned when no secret key is configured.
+var errNoKey = errors.New("no secret key: set " + envSecretKey + ", " + envKeyFile + " or -key-file")
+
+// rigidFlags are the flags configuring the Rigid instance shared by the subcommands.
+type rigidFlags struct {
+	keyFile string
+	sigLen  int
+	prefix  string
+	strict  bool
+	ttl     time.Duration
+}
+
+func (f *rigidFlags) register(fs *flag.FlagSet) {
+	f.registerKey(fs)
+	fs.IntVar(&f.sigLen, "sig-len", rigid.DefaultSignatureLength, "signature length in `bytes`")
+	fs.StringVar(&f.prefix, "prefix", "", "ID type `prefix`")
+	fs.BoolVar(&f.strict, "strict", false, "only accept IDs in canonical form")
+	fs.DurationVar(&f.ttl, "ttl", 0, "ID lifetime; expired IDs fail verification")
+}
+
+// registerKey registers only the key flag, for commands taking the other settings from the IDs.
+func (f *rigidFlags) registerKey(fs *flag.FlagSet) {
+	fs.StringVar(&f.keyFile, "key-file", "", "read the secret key from `file` (default $"+envKeyFile+")")
+}
+
+// secretKey returns the configured secret key. Trailing newlines are stripped from key files.
+func (f *rigidFlags) secretKey(c *cli) ([]byte, error) {
+	path := f.keyFile
+	if path == "" {
+		path = c.getenv(envKeyFile)
+	}
+	if path != "" {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key = bytes.TrimRight(key, "\r\n")
+		if len(key) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", path)
+		}
+		return key, nil
+	}
+
+	if key := c.getenv(envSecretKey); key != "" {
+		return []byte(key), nil
+	}
+	return nil, errNoKey
+}
+
+// rigid returns the Rigid instance described by the flags.
+func (f *rigidFlags) rigid(c *cli) (*rigid.Rigid, error) {
+	key, err := f.secretKey(c)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := rigid.NewRigid(key, f.sigLen)
+	if err != nil {
+		return nil, err
+	}
+	if r, err = r.WithPrefix(f.prefix); err != nil {
+		return nil, err
+	}
+	if f.strict {
+		r = r.WithStrict()
+	}
+	return r.WithTTL(f.ttl), nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretKeySources(t *testing.T) {
+	res := runCLI(t, nil, "", "generate")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "no secret key")
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(testSecretKey+"\n"), 0o600))
+
+	fromEnv := runCLI(t, keyEnv, "", "generate")
+	require.Equal(t, 0, fromEnv.code, fromEnv.stderr)
+	id := fromEnv.stdout[:len(fromEnv.stdout)-1]
+
+	// Key files take precedence over the key variable, with trailing newlines stripped.
+	env := map[string]string{envKeyFile: path, envSecretKey: "another-key"}
+	assert.Equal(t, 0, runCLI(t, env, "", "verify", id).code)
+	assert.Equal(t, 0, runCLI(t, map[string]string{envSecretKey: "another-key"}, "", "verify", "-key-file", path, id).code)
+	assert.Equal(t, 1, runCLI(t, map[string]string{envSecretKey: "another-key"}, "", "verify", id).code)
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	res = runCLI(t, nil, "", "generate", "-key-file", empty)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "is empty")
+}
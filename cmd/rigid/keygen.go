@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// minKeyBytes is the smallest key size keygen produces; shorter keys offer less than 128 bits of security.
+const minKeyBytes = 16
+
+// keystore is the JSON document written by keygen -keystore.
+type keystore struct {
+	Keys []keystoreEntry `json:"keys"`
+}
+
+// keystoreEntry is a key in a keystore. Key holds the encoded key, which is used as the secret
+// key verbatim, exactly as when it is placed in RIGID_SECRET_KEY.
+type keystoreEntry struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// generatedKey is the JSON form of keygen output. Key is omitted when the key was written to a keystore.
+type generatedKey struct {
+	ID       string `json:"id"`
+	Key      string `json:"key,omitempty"`
+	Keystore string `json:"keystore,omitempty"`
+}
+
+// keygen prints a new random secret key, or appends it to a keystore file and prints its key ID.
+func (c *cli) keygen(args []string) error {
+	fs := c.flagSet("keygen", "")
+	size := fs.Int("bytes", 32, "number of random `bytes` in the key")
+	encoding := fs.String("encoding", "base64", "key encoding: base64 or hex")
+	path := fs.String("keystore", "", "append the key to the keystore `file` instead of printing it")
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+	if *size < minKeyBytes {
+		return fmt.Errorf("-bytes must be at least %d", minKeyBytes)
+	}
+
+	raw := make([]byte, *size)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+
+	var key string
+	switch *encoding {
+	case "base64":
+		key = base64.StdEncoding.EncodeToString(raw)
+	case "hex":
+		key = hex.EncodeToString(raw)
+	default:
+		return fmt.Errorf("unknown encoding %q", *encoding)
+	}
+
+	r, err := rigid.NewRigid([]byte(key))
+	if err != nil {
+		return err
+	}
+	out := generatedKey{ID: r.KeyID(), Key: key}
+
+	if *path != "" {
+		entry := keystoreEntry{ID: out.ID, Key: key, CreatedAt: time.Now().UTC()}
+		if err := appendKeystore(*path, entry); err != nil {
+			return err
+		}
+		out.Key, out.Keystore = "", *path
+	}
+
+	switch {
+	case *asJSON:
+		return writeJSON(c.stdout, out)
+	case out.Key != "":
+		_, err = fmt.Fprintln(c.stdout, out.Key)
+	default:
+		_, err = fmt.Fprintln(c.stdout, out.ID)
+	}
+	return err
+}
+
+// appendKeystore adds entry to the keystore at path, creating it with owner-only permissions if needed.
+func appendKeystore(path string, entry keystoreEntry) error {
+	var ks keystore
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &ks); err != nil {
+			return fmt.Errorf("read keystore %s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	ks.Keys = append(ks.Keys, entry)
+	data, err = json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestKeygen(t *testing.T) {
+	res := runCLI(t, nil, "", "keygen")
+	require.Equal(t, 0, res.code, res.stderr)
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(res.stdout))
+	require.NoError(t, err)
+	assert.Len(t, raw, 32)
+
+	res = runCLI(t, nil, "", "keygen", "-encoding", "hex", "-bytes", "48")
+	require.Equal(t, 0, res.code, res.stderr)
+	raw, err = hex.DecodeString(strings.TrimSpace(res.stdout))
+	require.NoError(t, err)
+	assert.Len(t, raw, 48)
+
+	assert.NotEqual(t, runCLI(t, nil, "", "keygen").stdout, runCLI(t, nil, "", "keygen").stdout)
+}
+
+func TestKeygenErrors(t *testing.T) {
+	assert.Equal(t, 1, runCLI(t, nil, "", "keygen", "-bytes", "8").code)
+	assert.Equal(t, 1, runCLI(t, nil, "", "keygen", "-encoding", "base58").code)
+}
+
+func TestKeygenKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	first := runCLI(t, nil, "", "keygen", "-keystore", path)
+	require.Equal(t, 0, first.code, first.stderr)
+	second := runCLI(t, nil, "", "keygen", "-keystore", path)
+	require.Equal(t, 0, second.code, second.stderr)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var ks keystore
+	require.NoError(t, json.Unmarshal(data, &ks))
+	require.Len(t, ks.Keys, 2)
+
+	for i, out := range []string{first.stdout, second.stdout} {
+		entry := ks.Keys[i]
+		assert.Equal(t, strings.TrimSpace(out), entry.ID)
+		assert.False(t, entry.CreatedAt.IsZero())
+
+		r, err := rigid.NewRigid([]byte(entry.Key))
+		require.NoError(t, err)
+		assert.Equal(t, entry.ID, r.KeyID())
+	}
+}
+
+func TestKeygenKeystoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	res := runCLI(t, nil, "", "keygen", "-keystore", path)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "read keystore")
+}
+
+func TestKeygenJSON(t *testing.T) {
+	res := runCLI(t, nil, "", "keygen", "-json")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	var out generatedKey
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &out))
+	r, err := rigid.NewRigid([]byte(out.Key))
+	require.NoError(t, err)
+	assert.Equal(t, r.KeyID(), out.ID)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	res = runCLI(t, nil, "", "keygen", "-json", "-keystore", path)
+	require.Equal(t, 0, res.code, res.stderr)
+	out = generatedKey{}
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &out))
+	assert.Empty(t, out.Key)
+	assert.Equal(t, path, out.Keystore)
+	assert.Len(t, out.ID, 16)
+}
@@ -0,0 +1,141 @@
+// Command rigid generates, verifies and inspects rigid IDs from the command line.
+//
+// Usage:
+//
+//	rigid <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	audit     summarize the validity of IDs, such as those of a data migration
+//	bench     measure generation and verification performance
+//	generate  generate signed IDs
+//	verify    verify IDs, exiting with status 1 if any is invalid
+//	inspect   show the segments of IDs, verifying them if a key is available
+//	issued    export the IDs recorded in an issue log
+//	keygen    generate a random secret key
+//	revoke    revoke IDs in a revocation store
+//	revoked   list the revocations in a revocation store, or compact a revocation file
+//	serve     serve the generate, verify and inspect HTTP API
+//	vectors   generate or verify cross-language test vectors
+//
+// Without ID arguments, verify, inspect and audit read IDs from standard input, one per line,
+// and generate -m - reads metadata values the same way, so the commands work in pipelines:
+//
+//	cat ids.txt | rigid verify > results.tsv
+//
+// The secret key is read from the file named by -key-file or RIGID_KEY_FILE, or taken from
+// RIGID_SECRET_KEY. Passing keys as command-line arguments is deliberately not supported,
+// as arguments are visible to other users of the system.
+//
+// Revocations are kept in the store named by -revocation-store or RIGID_REVOCATION_STORE: a file
+// path, or a redis:// URL. When a store is configured, verify and serve reject revoked IDs.
+//
+// With -issue-log or RIGID_ISSUE_LOG, generate and serve record every ID they issue to a file
+// of JSON lines, which issued export enumerates.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// cli holds the process environment, replaced in tests.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// command is a rigid subcommand.
+type command struct {
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"audit":    {"summarize the validity of IDs, such as those of a data migration", (*cli).audit},
+	"bench":    {"measure generation and verification performance", (*cli).bench},
+	"generate": {"generate signed IDs", (*cli).generate},
+	"verify":   {"verify IDs, exiting with status 1 if any is invalid", (*cli).verify},
+	"inspect":  {"show the segments of IDs, verifying them if a key is available", (*cli).inspect},
+	"issued":   {"export the IDs recorded in an issue log", (*cli).issued},
+	"keygen":   {"generate a random secret key", (*cli).keygen},
+	"revoke":   {"revoke IDs in a revocation store", (*cli).revoke},
+	"revoked":  {"list the revocations in a revocation store, or compact a revocation file", (*cli).revoked},
+	"serve":    {"serve the generate, verify and inspect HTTP API", (*cli).serve},
+	"vectors":  {"generate or verify cross-language test vectors", (*cli).vectors},
+}
+
+// errInvalid reports that at least one ID failed verification; the details have already been printed.
+var errInvalid = errors.New("invalid IDs")
+
+func main() {
+	stdout := bufio.NewWriter(os.Stdout)
+	c := &cli{stdin: os.Stdin, stdout: stdout, stderr: os.Stderr, getenv: os.Getenv}
+	code := c.run(os.Args[1:])
+	if err := stdout.Flush(); err != nil && code == 0 {
+		fmt.Fprintf(os.Stderr, "rigid: %v\n", err)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit status.
+func (c *cli) run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		c.usage()
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "rigid: unknown command %q\n", args[0])
+		c.usage()
+		return 2
+	}
+
+	err := cmd.run(c, args[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	case errors.Is(err, errInvalid):
+		return 1
+	default:
+		fmt.Fprintf(c.stderr, "rigid %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "usage: rigid <command> [flags] [arguments]")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// flagSet returns a flag set for the named command writing its usage to stderr.
+func (c *cli) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: rigid %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecretKey = "test-secret-key-for-rigid-testing"
+
+// result is the outcome of a CLI invocation.
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+// runCLI runs the command line args with env as the environment and stdin as standard input.
+func runCLI(t *testing.T, env map[string]string, stdin string, args ...string) result {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string { return env[k] },
+	}
+	code := c.run(args)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+// keyEnv is an environment supplying the test secret key.
+var keyEnv = map[string]string{envSecretKey: testSecretKey}
+
+func TestUsage(t *testing.T) {
+	res := runCLI(t, nil, "")
+	assert.Equal(t, 2, res.code)
+	assert.Contains(t, res.stderr, "usage: rigid <command>")
+	for name := range commands {
+		assert.Contains(t, res.stderr, name)
+	}
+
+	res = runCLI(t, nil, "", "frobnicate")
+	assert.Equal(t, 2, res.code)
+	assert.Contains(t, res.stderr, `unknown command "frobnicate"`)
+
+	res = runCLI(t, nil, "", "generate", "-h")
+	assert.Equal(t, 2, res.code)
+	assert.Contains(t, res.stderr, "usage: rigid generate")
+}
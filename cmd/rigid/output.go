@@ -0,0 +1,20 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+)
+
+// registerJSON registers the -json flag shared by all subcommands.
+func registerJSON(fs *flag.FlagSet) *bool {
+	return fs.Bool("json", false, "write newline-delimited JSON objects instead of text")
+}
+
+// writeJSON writes v to w as a single line of JSON. HTML characters are not escaped,
+// so IDs and metadata appear exactly as they are.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
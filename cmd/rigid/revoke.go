@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidfile"
+	"github.com/bahadrix/rigid-go/rigidredis"
+)
+
+// envRevocationStore names the revocation store shared by revoke, revoked, verify and serve.
+const envRevocationStore = "RIGID_REVOCATION_STORE"
+
+// errNoStore is returned by commands that need a revocation store when none is configured.
+var errNoStore = errors.New("no revocation store: set -revocation-store or " + envRevocationStore)
+
+// revocationStore is a revocation store opened from the command line.
+type revocationStore interface {
+	rigid.RevocationStore
+	rigid.RevocationLister
+	Close() error
+}
+
+// redisStore closes the client of a rigidredis.Store opened from a URL.
+type redisStore struct {
+	*rigidredis.Store
+	client *redis.Client
+}
+
+func (s redisStore) Close() error {
+	return s.client.Close()
+}
+
+// registerStore registers the -revocation-store flag.
+func registerStore(fs *flag.FlagSet) *string {
+	return fs.String("revocation-store", "", "revocation store: a file `path` or a redis:// URL (default $"+envRevocationStore+")")
+}
+
+// openStore opens the revocation store named by spec or, if spec is empty, by the environment.
+// It returns a nil store if neither names one.
+func (c *cli) openStore(spec string) (revocationStore, error) {
+	if spec == "" {
+		spec = c.getenv(envRevocationStore)
+	}
+	if spec == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(spec, "redis://") || strings.HasPrefix(spec, "rediss://") {
+		opts, err := redis.ParseURL(spec)
+		if err != nil {
+			return nil, err
+		}
+		client := redis.NewClient(opts)
+		return redisStore{Store: rigidredis.New(client), client: client}, nil
+	}
+	return rigidfile.Open(spec)
+}
+
+// revokedID is the JSON form of a revoke result.
+type revokedID struct {
+	ID        string     `json:"id"`
+	ULID      string     `json:"ulid,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Error describes why the ID could not be revoked.
+	Error string `json:"error,omitempty"`
+}
+
+// revoke verifies IDs and records them in the revocation store, printing one line per ID.
+// With -ttl, revocations expire with the IDs they cover; otherwise they are kept indefinitely.
+// Without arguments, IDs are read from standard input, one per line.
+func (c *cli) revoke(args []string) error {
+	fs := c.flagSet("revoke", "[id...]")
+	var rf rigidFlags
+	rf.register(fs)
+	spec := registerStore(fs)
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil {
+		return err
+	}
+	store, err := c.openStore(*spec)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errNoStore
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	failed := false
+	err = c.eachInput(fs.Args(), func(id string) error {
+		out := revokedID{ID: id}
+
+		// IDs are verified without the store, so revoking an ID twice is not an error
+		result, err := r.Verify(id)
+		if err == nil {
+			out.ULID = result.ULID
+			var ttl time.Duration
+			if !result.ExpiresAt.IsZero() {
+				expiresAt := result.ExpiresAt.UTC()
+				out.ExpiresAt = &expiresAt
+				ttl = time.Until(expiresAt) + time.Second
+			}
+			err = store.Revoke(ctx, result.ULID, ttl)
+		}
+		if err != nil {
+			failed = true
+			out.Error = err.Error()
+		}
+
+		switch {
+		case *asJSON:
+			err = writeJSON(c.stdout, out)
+		case out.Error != "":
+			_, err = fmt.Fprintf(c.stdout, "%s\tinvalid: %s\n", id, out.Error)
+		default:
+			_, err = fmt.Fprintf(c.stdout, "%s\trevoked\n", id)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		return errInvalid
+	}
+	return nil
+}
+
+// revocation is the JSON form of a listed revocation.
+type revocation struct {
+	ULID      string     `json:"ulid"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// compacter is implemented by revocation stores that can drop expired revocations, such as
+// rigidfile.Store.
+type compacter interface {
+	Compact(ctx context.Context) error
+}
+
+// revoked runs the subcommands of rigid revoked. list prints the ULID of each revoked ID and the
+// time its revocation expires, or "never"; compact rewrites a revocation file without expired
+// and repeated revocations.
+func (c *cli) revoked(args []string) error {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "compact") {
+		c.flagSet("revoked list|compact", "").Usage()
+		return errors.New("expected the list or compact subcommand")
+	}
+	fs := c.flagSet("revoked "+args[0], "")
+	spec := registerStore(fs)
+	var asJSON *bool
+	if args[0] == "list" {
+		asJSON = registerJSON(fs)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	store, err := c.openStore(*spec)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errNoStore
+	}
+	defer store.Close()
+
+	if args[0] == "compact" {
+		s, ok := store.(compacter)
+		if !ok {
+			return errors.New("the revocation store does not need compaction")
+		}
+		return s.Compact(context.Background())
+	}
+
+	revocations, err := store.ListRevoked(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for _, rev := range revocations {
+		out := revocation{ULID: rev.ULID}
+		expires := "never"
+		if !rev.ExpiresAt.IsZero() {
+			expiresAt := rev.ExpiresAt.UTC()
+			out.ExpiresAt = &expiresAt
+			expires = expiresAt.Format(time.RFC3339)
+		}
+
+		if *asJSON {
+			err = writeJSON(c.stdout, out)
+		} else {
+			_, err = fmt.Fprintf(c.stdout, "%s\t%s\n", rev.ULID, expires)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// storeEnv returns keyEnv with the revocation store set to spec.
+func storeEnv(spec string) map[string]string {
+	return map[string]string{envSecretKey: testSecretKey, envRevocationStore: spec}
+}
+
+func TestRevokeFile(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+	id, err := r.Generate("user")
+	require.NoError(t, err)
+	other, err := r.Generate()
+	require.NoError(t, err)
+
+	env := storeEnv(filepath.Join(t.TempDir(), "revocations.jsonl"))
+
+	res := runCLI(t, env, "", "revoke", id, "not-an-id")
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, id+"\trevoked\nnot-an-id\tinvalid: invalid ULID\n", res.stdout)
+
+	// Revoking again is not an error
+	res = runCLI(t, env, id+"\n", "revoke")
+	assert.Equal(t, 0, res.code, res.stderr)
+
+	res = runCLI(t, env, "", "verify", id, other)
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, id+"\tinvalid: rigid ID has been revoked\n"+other+"\tvalid\n", res.stdout)
+
+	res = runCLI(t, env, "", "revoked", "list")
+	assert.Equal(t, 0, res.code, res.stderr)
+	assert.Equal(t, id[:26]+"\tnever\n", res.stdout)
+
+	res = runCLI(t, env, "", "revoked", "compact")
+	assert.Equal(t, 0, res.code, res.stderr)
+	data, err := os.ReadFile(env[envRevocationStore])
+	require.NoError(t, err)
+	assert.Equal(t, `{"ulid":"`+id[:26]+`"}`+"\n", string(data), "the repeated revocation is dropped")
+}
+
+func TestRevokeRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	env := storeEnv("redis://" + mr.Addr())
+
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	// With -ttl, revocations last as long as the ID
+	res := runCLI(t, env, "", "revoke", "-ttl", "1h", "-json", id)
+	require.Equal(t, 0, res.code, res.stderr)
+	var out revokedID
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &out))
+	assert.Equal(t, id[:26], out.ULID)
+	require.NotNil(t, out.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *out.ExpiresAt, time.Minute)
+	assert.InDelta(t, time.Hour.Seconds(), mr.TTL("rigid:revoked:"+id[:26]).Seconds(), 60)
+
+	res = runCLI(t, env, "", "revoked", "list", "-json")
+	require.Equal(t, 0, res.code, res.stderr)
+	var listed revocation
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &listed))
+	assert.Equal(t, id[:26], listed.ULID)
+	assert.NotNil(t, listed.ExpiresAt)
+
+	res = runCLI(t, env, "", "revoked", "compact")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "does not need compaction")
+}
+
+func TestRevokeWithoutStore(t *testing.T) {
+	res := runCLI(t, keyEnv, "", "revoke", "x")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "no revocation store")
+
+	res = runCLI(t, nil, "", "revoked")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "usage: rigid revoked list")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bahadrix/rigid-go/server"
+)
+
+// envAPIKeyFile names the file of API keys accepted by rigid serve.
+const envAPIKeyFile = "RIGID_API_KEY_FILE"
+
+// maxRevocationBatch bounds the revocation lookups batched by -revocation-batch.
+const maxRevocationBatch = 1000
+
+// shutdownTimeout bounds how long serve waits for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// serve runs the HTTP API of package server until interrupted. IDs revoked in the configured
+// revocation store, if any, fail verification.
+func (c *cli) serve(args []string) error {
+	fs := c.flagSet("serve", "")
+	var rf rigidFlags
+	rf.register(fs)
+	spec := registerStore(fs)
+	addr := fs.String("addr", ":8080", "listen `address`")
+	keyFile := fs.String("api-key-file", "", "accept the API keys listed in `file`, one per line (default $"+envAPIKeyFile+")")
+	noAuth := fs.Bool("no-auth", false, "serve without authentication")
+	debug := fs.Bool("debug", false, "serve configuration and statistics at /debug/rigid")
+	tlsCert := fs.String("tls-cert", "", "serve over TLS with the PEM certificate chain in `file`")
+	tlsKey := fs.String("tls-key", "", "PEM private key of -tls-cert in `file`")
+	cacheTTL := fs.Duration("cache-ttl", 0, "cache successful verifications for `duration`, for sidecar deployments; revocations take up to this long to apply")
+	cacheSize := fs.Int("cache-size", 100000, "keep at most `n` cached verifications")
+	batchWindow := fs.Duration("revocation-batch", 0, "batch the revocation lookups made within `duration`")
+	issueLog := registerIssueLog(fs)
+	clientCA := fs.String("client-ca", "", "require client certificates issued by the PEM CA certificates in `file`; needs -tls-cert")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil {
+		return err
+	}
+	r, closeLog, err := c.withIssueLog(r, *issueLog)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	store, err := c.openStore(*spec)
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer store.Close()
+		if *batchWindow > 0 {
+			r = r.WithRevocationStore(server.NewRevocationBatcher(store, *batchWindow, maxRevocationBatch))
+		} else {
+			r = r.WithRevocationStore(store)
+		}
+	}
+
+	if *keyFile == "" {
+		*keyFile = c.getenv(envAPIKeyFile)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return errors.New("-tls-cert and -tls-key must be given together")
+	}
+	if *clientCA != "" && *tlsCert == "" {
+		return errors.New("-client-ca needs -tls-cert and -tls-key")
+	}
+
+	var opts []server.Option
+	var cas *x509.CertPool
+	if *clientCA != "" {
+		if cas, err = readCertPool(*clientCA); err != nil {
+			return err
+		}
+		opts = append(opts, server.WithClientCertificates(cas))
+	}
+	switch {
+	case *keyFile != "":
+		keys, err := readAPIKeys(*keyFile)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, server.WithAPIKeys(keys...))
+	case cas == nil && !*noAuth:
+		return errors.New("no API keys or client CA: set -api-key-file or " + envAPIKeyFile + ", or -client-ca, or pass -no-auth")
+	}
+	if *debug {
+		opts = append(opts, server.WithDebug())
+	}
+	if *cacheTTL > 0 {
+		opts = append(opts, server.WithVerifyCache(*cacheTTL, *cacheSize))
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	if *tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, server.TLSConfig(cert, cas))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return c.runServer(ctx, ln, server.New(r, opts...))
+}
+
+// runServer serves h on ln until ctx is done, then shuts down gracefully.
+func (c *cli) runServer(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	fmt.Fprintf(c.stderr, "rigid: serving on %s\n", ln.Addr())
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// readCertPool reads the PEM certificates in path into a pool.
+func readCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// readAPIKeys reads API keys from path, one per line, ignoring blank lines and # comments.
+func readAPIKeys(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			keys = append(keys, line)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("API key file %s lists no keys", path)
+	}
+	return keys, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeRequiresAuth(t *testing.T) {
+	res := runCLI(t, keyEnv, "", "serve", "-addr", "127.0.0.1:0")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "no API keys")
+
+	res = runCLI(t, nil, "", "serve", "-no-auth")
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "no secret key")
+}
+
+func TestReadAPIKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "keys")
+	require.NoError(t, os.WriteFile(path, []byte("# clients\nkey-one\n\n  key-two  \n"), 0o600))
+	keys, err := readAPIKeys(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-one", "key-two"}, keys)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("# none\n"), 0o600))
+	_, err = readAPIKeys(empty)
+	assert.Error(t, err)
+
+	res := runCLI(t, keyEnv, "", "serve", "-api-key-file", filepath.Join(dir, "missing"))
+	assert.Equal(t, 1, res.code)
+}
+
+func TestServeTLSFlags(t *testing.T) {
+	dir := t.TempDir()
+
+	res := runCLI(t, keyEnv, "", "serve", "-no-auth", "-tls-cert", filepath.Join(dir, "cert.pem"))
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "must be given together")
+
+	res = runCLI(t, keyEnv, "", "serve", "-client-ca", filepath.Join(dir, "ca.pem"))
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stderr, "needs -tls-cert")
+
+	res = runCLI(t, keyEnv, "", "serve", "-addr", "127.0.0.1:0", "-tls-cert", filepath.Join(dir, "cert.pem"),
+		"-tls-key", filepath.Join(dir, "key.pem"), "-client-ca", filepath.Join(dir, "ca.pem"))
+	assert.Equal(t, 1, res.code, "missing files fail before serving")
+}
+
+func TestReadCertPool(t *testing.T) {
+	dir := t.TempDir()
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a certificate\n"), 0o600))
+	_, err := readCertPool(invalid)
+	assert.ErrorContains(t, err, "no PEM certificates")
+
+	_, err = readCertPool(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestRunServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &cli{stderr: io.Discard}
+	done := make(chan error, 1)
+	go func() {
+		done <- c.runServer(ctx, ln, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+	}()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// vectorResult is the JSON form of a checked vector.
+type vectorResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// Inputs covered by generated vectors. They exercise non-ASCII keys and metadata, metadata
+// containing the segment separator, and the extreme signature lengths.
+var (
+	vectorKeys = []string{
+		"test-secret-key-for-rigid-testing",
+		"k",
+		"0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0",
+		"ключ-🔑",
+	}
+	vectorSignatureLengths = []int{rigid.MinSignatureLength, rigid.DefaultSignatureLength, 16, rigid.MaxSignatureLength}
+	vectorPrefixes         = []string{"", "ord"}
+	vectorMetadata         = []string{"", "user:alice", "a-b-c", "ünïcödé ✓", "with spaces & <html>"}
+)
+
+// vectors writes a test vector document generated with this implementation, or with -verify,
+// checks the vectors in a document produced by any implementation.
+func (c *cli) vectors(args []string) error {
+	fs := c.flagSet("vectors", "[file]")
+	check := fs.Bool("verify", false, "verify the vectors in `file`, or standard input if absent or -, instead of generating")
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !*check {
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("unexpected arguments")
+		}
+		doc, err := generateVectors()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(c.stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errors.New("too many arguments")
+	}
+	in := c.stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	return c.verifyVectors(in, *asJSON)
+}
+
+// generateVectors returns a vector for every combination of the vector inputs.
+func generateVectors() (rigid.TestVectors, error) {
+	doc := rigid.TestVectors{Version: rigid.TestVectorsVersion}
+	for _, key := range vectorKeys {
+		for _, sigLen := range vectorSignatureLengths {
+			for _, prefix := range vectorPrefixes {
+				r, err := rigid.NewRigid([]byte(key), sigLen)
+				if err != nil {
+					return rigid.TestVectors{}, err
+				}
+				if r, err = r.WithPrefix(prefix); err != nil {
+					return rigid.TestVectors{}, err
+				}
+
+				for _, metadata := range vectorMetadata {
+					id, err := r.Generate(metadata)
+					if err != nil {
+						return rigid.TestVectors{}, err
+					}
+					parts, err := rigid.Parse(id)
+					if err != nil {
+						return rigid.TestVectors{}, err
+					}
+					doc.Vectors = append(doc.Vectors, rigid.TestVector{
+						Key:             key,
+						SignatureLength: sigLen,
+						Prefix:          prefix,
+						ULID:            parts.ULID,
+						Metadata:        metadata,
+						ID:              id,
+					})
+				}
+			}
+		}
+	}
+	return doc, nil
+}
+
+// verifyVectors reads a vector document from in and checks each vector, printing the
+// failures and a summary.
+func (c *cli) verifyVectors(in io.Reader, asJSON bool) error {
+	var doc rigid.TestVectors
+	if err := json.NewDecoder(in).Decode(&doc); err != nil {
+		return fmt.Errorf("read vectors: %w", err)
+	}
+	if doc.Version != rigid.TestVectorsVersion {
+		return fmt.Errorf("unsupported vectors version %d", doc.Version)
+	}
+
+	failed := 0
+	for i, v := range doc.Vectors {
+		res := vectorResult{Index: i, ID: v.ID, Valid: true}
+		if err := checkVector(v); err != nil {
+			res.Valid = false
+			res.Error = err.Error()
+			failed++
+		}
+
+		var err error
+		switch {
+		case asJSON:
+			err = writeJSON(c.stdout, res)
+		case !res.Valid:
+			_, err = fmt.Fprintf(c.stdout, "vector %d\t%s\tinvalid: %s\n", i, v.ID, res.Error)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !asJSON {
+		if _, err := fmt.Fprintf(c.stdout, "%d of %d vectors passed\n", len(doc.Vectors)-failed, len(doc.Vectors)); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+// checkVector verifies the vector's ID with its key, signature length and prefix, and checks
+// that the ID carries the vector's ULID and metadata.
+func checkVector(v rigid.TestVector) error {
+	r, err := rigid.NewRigid([]byte(v.Key), v.SignatureLength)
+	if err != nil {
+		return err
+	}
+	if r, err = r.WithPrefix(v.Prefix); err != nil {
+		return err
+	}
+
+	result, err := r.Verify(v.ID)
+	if err != nil {
+		return err
+	}
+	if result.ULID != v.ULID {
+		return fmt.Errorf("ULID %s, want %s", result.ULID, v.ULID)
+	}
+	if result.Metadata != v.Metadata {
+		return fmt.Errorf("metadata %q, want %q", result.Metadata, v.Metadata)
+	}
+	return nil
+}
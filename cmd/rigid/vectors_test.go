@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectors(t *testing.T) {
+	res := runCLI(t, nil, "", "vectors")
+	require.Equal(t, 0, res.code, res.stderr)
+
+	var doc rigid.TestVectors
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &doc))
+	assert.Equal(t, rigid.TestVectorsVersion, doc.Version)
+	assert.Len(t, doc.Vectors, len(vectorKeys)*len(vectorSignatureLengths)*len(vectorPrefixes)*len(vectorMetadata))
+	assert.Contains(t, res.stdout, "<html>", "metadata must not be HTML-escaped")
+
+	// Generated vectors verify, from a file or standard input
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	require.NoError(t, os.WriteFile(path, []byte(res.stdout), 0o600))
+
+	check := runCLI(t, nil, "", "vectors", "-verify", path)
+	assert.Equal(t, 0, check.code, check.stdout)
+	assert.Equal(t, "160 of 160 vectors passed\n", check.stdout)
+
+	check = runCLI(t, nil, res.stdout, "vectors", "-verify", "-json")
+	assert.Equal(t, 0, check.code)
+	assert.Equal(t, 160, strings.Count(check.stdout, `"valid":true`))
+}
+
+func TestVectorsVerifyFailures(t *testing.T) {
+	res := runCLI(t, nil, "", "vectors")
+	require.Equal(t, 0, res.code)
+
+	var doc rigid.TestVectors
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &doc))
+	doc.Vectors = doc.Vectors[:3]
+	doc.Vectors[1].Metadata = "tampered"
+	doc.Vectors[2].Key = "wrong-key"
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	check := runCLI(t, nil, string(data), "vectors", "-verify")
+	assert.Equal(t, 1, check.code)
+	assert.Contains(t, check.stdout, "vector 1\t")
+	assert.Contains(t, check.stdout, `metadata "user:alice", want "tampered"`)
+	assert.Contains(t, check.stdout, "vector 2\t")
+	assert.Contains(t, check.stdout, "integrity")
+	assert.Contains(t, check.stdout, "1 of 3 vectors passed")
+
+	check = runCLI(t, nil, `{"version":2,"vectors":[]}`, "vectors", "-verify")
+	assert.Equal(t, 1, check.code)
+	assert.Contains(t, check.stderr, "unsupported vectors version 2")
+}
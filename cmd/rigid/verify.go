@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bahadrix/rigid-go/server"
+)
+
+// verify prints one line per ID: the ID, a tab, and "valid" or the reason it is invalid.
+// Without arguments, IDs are read from standard input, one per line. IDs revoked in the
+// configured revocation store, if any, are invalid.
+func (c *cli) verify(args []string) error {
+	fs := c.flagSet("verify", "[id...]")
+	var rf rigidFlags
+	rf.register(fs)
+	spec := registerStore(fs)
+	asJSON := registerJSON(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := rf.rigid(c)
+	if err != nil {
+		return err
+	}
+	store, err := c.openStore(*spec)
+	if err != nil {
+		return err
+	}
+	if store != nil {
+		defer store.Close()
+		r = r.WithRevocationStore(store)
+	}
+
+	failed := false
+	err = c.eachInput(fs.Args(), func(id string) error {
+		v := server.Verify(r, id)
+		if v.Error != "" {
+			failed = true
+		}
+
+		var err error
+		switch {
+		case *asJSON:
+			err = writeJSON(c.stdout, v)
+		case v.Error != "":
+			_, err = fmt.Fprintf(c.stdout, "%s\tinvalid: %s\n", id, v.Error)
+		default:
+			_, err = fmt.Fprintf(c.stdout, "%s\tvalid\n", id)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		return errInvalid
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/server"
+)
+
+func TestVerify(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.Generate("order:42")
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, "", "verify", id)
+	assert.Equal(t, 0, res.code)
+	assert.Equal(t, id+"\tvalid\n", res.stdout)
+
+	forged := id[:27] + "AAAAAAAAAAAAA-order:42"
+	res = runCLI(t, keyEnv, "", "verify", id, forged)
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, id+"\tvalid\n"+forged+"\tinvalid: integrity verification failed\n", res.stdout)
+	assert.Empty(t, res.stderr)
+}
+
+func TestVerifyTTL(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.GenerateAt(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, runCLI(t, keyEnv, "", "verify", "-ttl", "3h", id).code)
+
+	res := runCLI(t, keyEnv, "", "verify", "-ttl", "1h", id)
+	assert.Equal(t, 1, res.code)
+	assert.Contains(t, res.stdout, "expired")
+}
+
+func TestVerifyStdin(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	var in, want strings.Builder
+	for i := range 100 {
+		id, err := r.Generate()
+		require.NoError(t, err)
+		if i == 50 {
+			id = id[:27] + "AAAAAAAAAAAAA"
+			fmt.Fprintf(&want, "%s\tinvalid: integrity verification failed\n", id)
+		} else {
+			fmt.Fprintf(&want, "%s\tvalid\n", id)
+		}
+		fmt.Fprintf(&in, "%s\r\n\n", id)
+	}
+
+	res := runCLI(t, keyEnv, in.String(), "verify")
+	assert.Equal(t, 1, res.code)
+	assert.Equal(t, want.String(), res.stdout)
+
+	assert.Equal(t, res.stdout, runCLI(t, keyEnv, in.String(), "verify", "-").stdout)
+}
+
+func TestVerifyJSON(t *testing.T) {
+	r, err := rigid.NewRigid([]byte(testSecretKey))
+	require.NoError(t, err)
+
+	id, err := r.Generate("<user>&co")
+	require.NoError(t, err)
+
+	res := runCLI(t, keyEnv, id+"\nbad\n", "verify", "--json")
+	assert.Equal(t, 1, res.code)
+
+	lines := strings.Split(strings.TrimSpace(res.stdout), "\n")
+	require.Len(t, lines, 2)
+
+	var v server.VerifyResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &v))
+	assert.Equal(t, id, v.ID)
+	assert.True(t, v.Result.Valid)
+	assert.Equal(t, "<user>&co", v.Result.Metadata)
+	assert.Empty(t, v.Error)
+	assert.Contains(t, lines[0], `"metadata":"<user>&co"`)
+
+	assert.JSONEq(t, `{"id":"bad","result":{"valid":false,"ulid":"","metadata":""},"error":"invalid rigid format"}`, lines[1])
+}
@@ -0,0 +1,94 @@
+package rigid
+
+import (
+	"crypto/subtle"
+	"slices"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Compare orders two rigid IDs by their ULID component, returning -1, 0, or +1.
+// Signatures and metadata are ignored, so IDs sharing a ULID compare as equal.
+// IDs whose ULID cannot be parsed sort before valid IDs and are ordered lexically among themselves.
+// Compare does not verify signatures.
+func Compare(a, b string) int {
+	ua, okA := sortKey(a)
+	ub, okB := sortKey(b)
+
+	switch {
+	case okA && okB:
+		return ua.Compare(ub)
+	case okA:
+		return 1
+	case okB:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// Before reports whether rigid ID a sorts before rigid ID b, as defined by Compare.
+func Before(a, b string) bool {
+	return Compare(a, b) < 0
+}
+
+// After reports whether rigid ID a sorts after rigid ID b, as defined by Compare.
+func After(a, b string) bool {
+	return Compare(a, b) > 0
+}
+
+// Equal reports whether two rigid IDs are byte-for-byte identical, including signature and metadata.
+// The comparison runs in constant time with respect to the contents of the IDs, making it suitable
+// where IDs are used as bearer secrets. Only the lengths of the inputs may leak through timing.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// SortByTime sorts rigid IDs in place by creation time, oldest first, as defined by Compare.
+// The sort is stable, so IDs sharing a ULID keep their relative order.
+func SortByTime(ids []string) {
+	type entry struct {
+		id    string
+		key   ulid.ULID
+		valid bool
+	}
+
+	entries := make([]entry, len(ids))
+	for i, id := range ids {
+		key, valid := sortKey(id)
+		entries[i] = entry{id: id, key: key, valid: valid}
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		switch {
+		case a.valid && b.valid:
+			return a.key.Compare(b.key)
+		case a.valid:
+			return 1
+		case b.valid:
+			return -1
+		default:
+			return strings.Compare(a.id, b.id)
+		}
+	})
+
+	for i, e := range entries {
+		ids[i] = e.id
+	}
+}
+
+// sortKey extracts the ULID used for ordering a rigid ID.
+func sortKey(secureULID string) (ulid.ULID, bool) {
+	seg, err := splitID(secureULID)
+	if err != nil {
+		return ulid.ULID{}, false
+	}
+
+	ulidObj, err := ulid.ParseStrict(seg.ulid)
+	if err != nil {
+		return ulid.ULID{}, false
+	}
+
+	return ulidObj, true
+}
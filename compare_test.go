@@ -0,0 +1,71 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	first, err := r.Generate()
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	second, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	assert.Equal(t, -1, Compare(first, second))
+	assert.Equal(t, 1, Compare(second, first))
+	assert.Equal(t, 0, Compare(first, first))
+	assert.True(t, Before(first, second))
+	assert.True(t, After(second, first))
+	assert.False(t, Before(second, first))
+
+	// Signature and metadata do not take part in ordering
+	assert.Equal(t, 0, Compare(second, second[:26]+"-AAAAAAAA"))
+
+	// Malformed IDs sort first
+	assert.Equal(t, -1, Compare("garbage", first))
+	assert.Equal(t, 1, Compare(first, "garbage"))
+	assert.Equal(t, -1, Compare("a", "b"))
+}
+
+func TestSortByTime(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var expected []string
+	for i := 0; i < 5; i++ {
+		id, err := r.Generate()
+		require.NoError(t, err)
+		expected = append(expected, id)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	ids := []string{expected[3], expected[0], "not-an-id", expected[4], expected[2], expected[1]}
+	SortByTime(ids)
+
+	assert.Equal(t, append([]string{"not-an-id"}, expected...), ids)
+}
+
+func TestEqual(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate("session:12345")
+	require.NoError(t, err)
+
+	assert.True(t, Equal(id, id))
+	assert.True(t, Equal("", ""))
+	assert.False(t, Equal(id, id[:len(id)-1]))
+	assert.False(t, Equal(id, id[:len(id)-1]+"9"))
+
+	// Unlike Compare, Equal takes the signature and metadata into account
+	other := id[:26] + "-AAAAAAAA-session:12345"
+	assert.Equal(t, 0, Compare(id, other))
+	assert.False(t, Equal(id, other))
+}
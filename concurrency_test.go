@@ -0,0 +1,214 @@
+package rigid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests hammer shared state from many goroutines and are meant to be run with -race.
+
+// hammer runs f from goroutines goroutines, n times each, and returns the IDs they produce.
+func hammer(t *testing.T, goroutines, n int, f func(g, i int) (string, error)) [][]string {
+	t.Helper()
+
+	ids := make([][]string, goroutines)
+	errs := make(chan error, goroutines)
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				id, err := f(g, i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				ids[g] = append(ids[g], id)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("concurrent call failed: %v", err)
+	}
+	return ids
+}
+
+// requireUnique fails the test unless all ULIDs of ids are distinct.
+func requireUnique(t *testing.T, ids [][]string) {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	for _, batch := range ids {
+		for _, id := range batch {
+			seg, err := splitID(id)
+			require.NoError(t, err)
+			require.False(t, seen[seg.ulid], "duplicate ULID %s", seg.ulid)
+			seen[seg.ulid] = true
+		}
+	}
+}
+
+func TestConcurrentGenerateDerivedInstances(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	long, err := r.WithSignatureLength(16)
+	require.NoError(t, err)
+
+	// Derived instances share the entropy source, so their IDs are unique across all of them
+	instances := []*Rigid{r, typed, long, r.WithTTL(time.Hour), r.WithStrict()}
+	ids := hammer(t, 1000, 20, func(g, _ int) (string, error) {
+		return instances[g%len(instances)].Generate()
+	})
+	requireUnique(t, ids)
+
+	for g, batch := range ids {
+		for _, id := range batch {
+			_, err := instances[g%len(instances)].Verify(id)
+			require.NoError(t, err, id)
+		}
+	}
+}
+
+func TestConcurrentGenerateMonotonic(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// With a single shard, the IDs each goroutine sees are strictly increasing
+	ids := hammer(t, 1000, 20, func(_, _ int) (string, error) {
+		return r.Generate()
+	})
+	requireUnique(t, ids)
+	for _, batch := range ids {
+		for i := 1; i < len(batch); i++ {
+			require.Less(t, batch[i-1][:ulid.EncodedSize], batch[i][:ulid.EncodedSize])
+		}
+	}
+}
+
+func TestConcurrentGenerateSharded(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	sharded := r.WithEntropyShards(8)
+
+	ids := hammer(t, 1000, 20, func(_, _ int) (string, error) {
+		return sharded.Generate("user:alice")
+	})
+	requireUnique(t, ids)
+}
+
+func TestConcurrentGenerateSameMillisecond(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	sharded := r.WithEntropyShards(4)
+
+	// Every ID shares one timestamp, so all of them come from the monotonic sequences
+	at := time.UnixMilli(time.Now().UnixMilli())
+	for _, g := range []*Rigid{r, sharded} {
+		ids := hammer(t, 1000, 20, func(_, _ int) (string, error) {
+			return g.GenerateAt(at)
+		})
+		requireUnique(t, ids)
+	}
+}
+
+func TestConcurrentGenerateOverflow(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// Start the sequence one step short of overflowing, so concurrent callers cross the
+	// overflow within the same millisecond
+	r.gen.shards[0].entropy = ulid.Monotonic(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)), 1)
+
+	at := time.UnixMilli(time.Now().UnixMilli())
+	ids := hammer(t, 1000, 5, func(_, _ int) (string, error) {
+		return r.GenerateAt(at)
+	})
+	requireUnique(t, ids)
+}
+
+func TestConcurrentGeneratorsDoNotShareSequences(t *testing.T) {
+	// Generators created at the same instant are seeded independently, so their shards
+	// produce different IDs for the same millisecond
+	at := time.UnixMilli(time.Now().UnixMilli())
+	ids := hammer(t, 200, 5, func(_, _ int) (string, error) {
+		r, err := NewRigid(testSecretKey)
+		if err != nil {
+			return "", err
+		}
+		return r.WithEntropyShards(4).GenerateAt(at)
+	})
+	requireUnique(t, ids)
+}
+
+func TestConcurrentMixedOperations(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithRevocationStore(newMapStore()).WithFailureHook(func(string, error, any) {})
+
+	// Generation, verification, derivation and revocation running side by side
+	ctx := context.Background()
+	hammer(t, 1000, 10, func(g, i int) (string, error) {
+		d := r
+		if g%2 == 0 {
+			var err error
+			if d, err = r.WithPrefix("p"); err != nil {
+				return "", err
+			}
+		}
+
+		buf, err := d.AppendGenerate(nil, "user:alice")
+		if err != nil {
+			return "", err
+		}
+		id := string(buf)
+		if _, err := d.VerifyBytes(buf); err != nil {
+			return "", err
+		}
+		if i%5 == 0 {
+			if err := d.Revoke(ctx, id, time.Minute); err != nil {
+				return "", err
+			}
+			if _, err := d.VerifyContext(ctx, id); !errors.Is(err, ErrRevoked) {
+				return "", fmt.Errorf("verify revoked ID: %v", err)
+			}
+		} else if _, err := d.Verify(id); err != nil {
+			return "", err
+		}
+		_, _ = d.Verify(id[:27] + "AAAAAAAAAAAAA")
+		return id, nil
+	})
+}
+
+func TestConcurrentVerifyBatchParallel(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	ids := make([]string, 2000)
+	for i := range ids {
+		if ids[i], err = r.Generate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hammer(t, 20, 5, func(_, _ int) (string, error) {
+		_, stats, err := r.VerifyBatchParallel(context.Background(), ids, 8)
+		if err == nil && stats.Valid != len(ids) {
+			t.Errorf("%d of %d IDs valid", stats.Valid, len(ids))
+		}
+		return "", err
+	})
+}
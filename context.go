@@ -0,0 +1,46 @@
+package rigid
+
+import "context"
+
+type requestIDKey struct{}
+
+type remoteKey struct{}
+
+type callerKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id.
+// Transport integrations such as rigidhttp and rigidgrpc store verified or freshly generated
+// request IDs this way, so an ID received on one protocol is propagated on outgoing calls of another.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by ContextWithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ContextWithRemote returns a copy of ctx identifying the remote party presenting IDs, such as a
+// client address. It is passed to the failure hook of VerifyContext, see WithFailureHook.
+func ContextWithRemote(ctx context.Context, remote any) context.Context {
+	return context.WithValue(ctx, remoteKey{}, remote)
+}
+
+// RemoteFromContext returns the remote party stored in ctx by ContextWithRemote, or nil.
+func RemoteFromContext(ctx context.Context) any {
+	return ctx.Value(remoteKey{})
+}
+
+// ContextWithCaller returns a copy of ctx identifying the caller verifying IDs, such as an API key
+// ID, user or client address. The caller is opaque to rigid and keys the rate limiter of
+// VerifyContext, see WithRateLimiter.
+func ContextWithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller stored in ctx by ContextWithCaller, if any.
+func CallerFromContext(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerKey{}).(string)
+	return caller, ok && caller != ""
+}
@@ -0,0 +1,33 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDContext(t *testing.T) {
+	_, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := ContextWithRequestID(context.Background(), "01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA")
+	id, ok := RequestIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA", id)
+
+	_, ok = RequestIDFromContext(ContextWithRequestID(ctx, ""))
+	assert.False(t, ok)
+}
+
+func TestCallerContext(t *testing.T) {
+	_, ok := CallerFromContext(context.Background())
+	assert.False(t, ok)
+
+	caller, ok := CallerFromContext(ContextWithCaller(context.Background(), "key_42"))
+	assert.True(t, ok)
+	assert.Equal(t, "key_42", caller)
+
+	_, ok = CallerFromContext(ContextWithCaller(context.Background(), ""))
+	assert.False(t, ok)
+}
@@ -0,0 +1,166 @@
+package rigid
+
+import (
+	"expvar"
+	"time"
+)
+
+// Config describes the configuration of an instance, leaving out the secret key.
+type Config struct {
+	// KeyID identifies the secret key, see Rigid.KeyID.
+	KeyID              string
+	Issuer             string
+	Prefix             string
+	Namespace          string
+	SignatureLength    int
+	FormatVersion      FormatVersion
+	LegacyVerification bool
+	Strict             bool
+	PrintableMetadata  bool
+	NormalizedMetadata bool
+	// MaxLength is the maximum ID length, or zero if unlimited.
+	MaxLength int
+	// TTL is the lifetime of IDs, or zero if they do not expire.
+	TTL time.Duration
+	// NotBefore, NotAfter and MaxClockSkew are the timestamp bounds, zero where not set.
+	NotBefore, NotAfter time.Time
+	MaxClockSkew        time.Duration
+	EntropyShards       int
+	// EntropySources is the number of sources set with WithEntropySource, zero if not set.
+	EntropySources int
+	// Unordered reports whether IDs are unordered, see WithUnordered.
+	Unordered bool
+	// BoundContext reports whether signatures bind a context, see WithContext.
+	BoundContext bool
+	// NodeID and NodeIDBits are the node ID set with WithNodeID and its width, zero if not set.
+	NodeID     uint32
+	NodeIDBits int
+	// TimestampGranularity is the granularity of obfuscated timestamps, or zero if not obfuscated.
+	TimestampGranularity time.Duration
+	// KeyBucketPeriod and KeyBucketWindow are the key buckets set with WithKeyBuckets, zero if not set.
+	KeyBucketPeriod time.Duration
+	KeyBucketWindow int
+	// The remaining fields report which optional components are configured.
+	RevocationStore bool
+	TrustPolicy     bool
+	ReplayStore     bool
+	StateStore      bool
+	RateLimiter     bool
+	AuditSink       bool
+	IssueRecorder   bool
+	BurstDetector   bool
+	Hooks           bool
+	FailureHook     bool
+}
+
+// Config returns the configuration of r.
+func (r *Rigid) Config() Config {
+	return Config{
+		KeyID:                r.KeyID(),
+		Issuer:               r.issuer,
+		Prefix:               unqualifyPrefix(r.issuer, r.prefix),
+		Namespace:            r.namespace,
+		SignatureLength:      r.signatureLength,
+		FormatVersion:        r.version,
+		LegacyVerification:   r.legacy,
+		Strict:               r.strict,
+		PrintableMetadata:    r.printable,
+		NormalizedMetadata:   r.normalize,
+		MaxLength:            r.maxLength,
+		TTL:                  r.ttl,
+		NotBefore:            r.notBefore,
+		NotAfter:             r.notAfter,
+		MaxClockSkew:         r.maxSkew,
+		EntropyShards:        len(r.gen.shards),
+		EntropySources:       r.gen.entropySources(),
+		Unordered:            r.unordered,
+		BoundContext:         r.boundContext != nil,
+		NodeID:               r.gen.node.id,
+		NodeIDBits:           r.gen.node.bits,
+		TimestampGranularity: r.timestampGranularity(),
+		KeyBucketPeriod:      r.buckets.configuredPeriod(),
+		KeyBucketWindow:      r.buckets.configuredWindow(),
+		RevocationStore:      r.revocations != nil,
+		TrustPolicy:          r.trust != nil,
+		ReplayStore:          r.replays != nil,
+		StateStore:           r.gen.state != nil,
+		RateLimiter:          r.limiter != nil,
+		AuditSink:            r.audit != nil,
+		IssueRecorder:        r.issues != nil,
+		BurstDetector:        r.bursts != nil,
+		Hooks:                r.hooks != nil,
+		FailureHook:          r.onFailure != nil,
+	}
+}
+
+// DebugVars returns the configuration and statistics of r as JSON-friendly values for debug
+// endpoints, with durations and times rendered as strings. The secret key is never included.
+func (r *Rigid) DebugVars() map[string]any {
+	c := r.Config()
+	s := r.Stats()
+	return map[string]any{
+		"config": map[string]any{
+			"key_id":                c.KeyID,
+			"issuer":                c.Issuer,
+			"prefix":                c.Prefix,
+			"namespace":             c.Namespace,
+			"signature_length":      c.SignatureLength,
+			"format_version":        c.FormatVersion,
+			"legacy_verification":   c.LegacyVerification,
+			"strict":                c.Strict,
+			"printable_metadata":    c.PrintableMetadata,
+			"normalized_metadata":   c.NormalizedMetadata,
+			"max_length":            c.MaxLength,
+			"ttl":                   c.TTL.String(),
+			"not_before":            debugTime(c.NotBefore),
+			"not_after":             debugTime(c.NotAfter),
+			"max_clock_skew":        c.MaxClockSkew.String(),
+			"entropy_shards":        c.EntropyShards,
+			"entropy_sources":       c.EntropySources,
+			"unordered":             c.Unordered,
+			"bound_context":         c.BoundContext,
+			"node_id":               c.NodeID,
+			"node_id_bits":          c.NodeIDBits,
+			"timestamp_granularity": c.TimestampGranularity.String(),
+			"key_bucket_period":     c.KeyBucketPeriod.String(),
+			"key_bucket_window":     c.KeyBucketWindow,
+			"revocation_store":      c.RevocationStore,
+			"trust_policy":          c.TrustPolicy,
+			"replay_store":          c.ReplayStore,
+			"state_store":           c.StateStore,
+			"rate_limiter":          c.RateLimiter,
+			"audit_sink":            c.AuditSink,
+			"issue_recorder":        c.IssueRecorder,
+			"burst_detector":        c.BurstDetector,
+			"hooks":                 c.Hooks,
+			"failure_hook":          c.FailureHook,
+		},
+		"stats": map[string]any{
+			"since":                s.Since.UTC().Format(time.RFC3339Nano),
+			"generated":            s.Generated,
+			"generate_failures":    s.GenerateFailures,
+			"verified":             s.Verified,
+			"verify_failures":      s.VerifyFailures,
+			"failures_by_reason":   s.FailuresByReason,
+			"avg_generate_latency": s.AvgGenerateLatency.String(),
+			"avg_verify_latency":   s.AvgVerifyLatency.String(),
+		},
+	}
+}
+
+// debugTime renders t for DebugVars, or "" for the zero time.
+func debugTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// PublishExpvar publishes the DebugVars of r under name in the expvar package, served at
+// /debug/vars by the default HTTP mux once expvar is imported. Values are computed on each read.
+// Like expvar.Publish, it panics if name is already in use.
+func (r *Rigid) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return r.DebugVars()
+	}))
+}
@@ -0,0 +1,93 @@
+package rigid
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 16)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		KeyID:           r.KeyID(),
+		SignatureLength: 16,
+		FormatVersion:   FormatV1,
+		MaxLength:       DefaultMaxLength,
+		EntropyShards:   1,
+	}, r.Config())
+
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	typed, err = typed.WithFormatVersion(FormatV2)
+	require.NoError(t, err)
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	typed = typed.WithLegacyVerification().WithStrict().WithTTL(time.Hour).
+		WithTimestampBounds(notBefore, time.Time{}).WithMaxClockSkew(time.Minute).
+		WithEntropyShards(4).WithRevocationStore(newMapStore()).WithRateLimiter(NewMemoryRateLimiter(1, 1))
+
+	c := typed.Config()
+	assert.Equal(t, "ord", c.Prefix)
+	assert.Equal(t, FormatV2, c.FormatVersion)
+	assert.True(t, c.LegacyVerification)
+	assert.True(t, c.Strict)
+	assert.Equal(t, time.Hour, c.TTL)
+	assert.Equal(t, notBefore, c.NotBefore)
+	assert.Equal(t, time.Minute, c.MaxClockSkew)
+	assert.Equal(t, 4, c.EntropyShards)
+	assert.True(t, c.RevocationStore)
+	assert.True(t, c.RateLimiter)
+	assert.False(t, c.ReplayStore)
+}
+
+func TestDebugVars(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour)
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	_, _ = r.Verify(id)
+	_, _ = r.Verify(id[:27] + "AAAAAAAAAAAAA")
+
+	b, err := json.Marshal(r.DebugVars())
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), string(testSecretKey))
+	assert.NotContains(t, string(b), fmt.Sprintf("%x", testSecretKey))
+
+	var vars struct {
+		Config map[string]any `json:"config"`
+		Stats  map[string]any `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal(b, &vars))
+	assert.Equal(t, r.KeyID(), vars.Config["key_id"])
+	assert.Equal(t, "1h0m0s", vars.Config["ttl"])
+	assert.Equal(t, "", vars.Config["not_before"])
+	assert.EqualValues(t, 1, vars.Stats["generated"])
+	assert.EqualValues(t, 1, vars.Stats["verified"])
+	assert.Equal(t, map[string]any{ReasonIntegrity: 1.0}, vars.Stats["failures_by_reason"])
+}
+
+func TestPublishExpvar(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	// Names are global, so repeated runs need their own
+	name := fmt.Sprintf("rigid_test_%d", time.Now().UnixNano())
+	r.PublishExpvar(name)
+
+	_, err = r.Generate()
+	require.NoError(t, err)
+
+	// Values are read live
+	var vars struct {
+		Stats map[string]any `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars))
+	assert.EqualValues(t, 1, vars.Stats["generated"])
+
+	assert.Panics(t, func() { r.PublishExpvar(name) })
+}
@@ -0,0 +1,89 @@
+package dynamotype
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+type order struct {
+	ID     ID  `dynamodbav:"pk"`
+	Parent ID  `dynamodbav:"parent"`
+	Amount int `dynamodbav:"amount"`
+}
+
+func TestMarshalMapRoundTrip(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate("order:42")
+	require.NoError(t, err)
+
+	item, err := attributevalue.MarshalMap(order{ID: ID(generated), Amount: 7})
+	require.NoError(t, err)
+
+	pk, ok := item["pk"].(*types.AttributeValueMemberB)
+	require.True(t, ok, "IDs must be stored as binary attributes")
+	assert.Less(t, len(pk.Value), len(generated))
+	assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, item["parent"])
+
+	var decoded order
+	require.NoError(t, attributevalue.UnmarshalMap(item, &decoded))
+	assert.Equal(t, ID(generated), decoded.ID)
+	assert.Equal(t, ID(""), decoded.Parent)
+	assert.Equal(t, 7, decoded.Amount)
+}
+
+func TestBinarySortsByTime(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	older, err := r.GenerateAt(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	newer, err := r.Generate()
+	require.NoError(t, err)
+
+	a, err := ID(older).MarshalDynamoDBAttributeValue()
+	require.NoError(t, err)
+	b, err := ID(newer).MarshalDynamoDBAttributeValue()
+	require.NoError(t, err)
+
+	assert.Equal(t, -1, bytes.Compare(a.(*types.AttributeValueMemberB).Value, b.(*types.AttributeValueMemberB).Value))
+}
+
+func TestUnmarshalText(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate()
+	require.NoError(t, err)
+
+	var id ID
+	require.NoError(t, id.UnmarshalDynamoDBAttributeValue(&types.AttributeValueMemberS{Value: generated}))
+	assert.Equal(t, generated, id.String())
+}
+
+func TestInvalidValues(t *testing.T) {
+	var id ID
+
+	err := id.UnmarshalDynamoDBAttributeValue(&types.AttributeValueMemberS{Value: "garbage"})
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+
+	err = id.UnmarshalDynamoDBAttributeValue(&types.AttributeValueMemberB{Value: []byte{1, 2, 3}})
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+
+	err = id.UnmarshalDynamoDBAttributeValue(&types.AttributeValueMemberN{Value: "42"})
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+
+	_, err = ID("garbage").MarshalDynamoDBAttributeValue()
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+}
@@ -0,0 +1,44 @@
+package rigid
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNoSequence indicates a sequence number was requested from an instance generating unordered
+// IDs, which carry none.
+var ErrNoSequence = errors.New("unordered IDs carry no sequence")
+
+// ExtractEntropy extracts the random component of the ULID of a rigid ID: the 10 bytes following
+// the timestamp, or all 16 bytes if r generates unordered IDs. Like ExtractULID, it does not
+// verify the ID.
+func (r *Rigid) ExtractEntropy(secureULID string) ([]byte, error) {
+	ulidObj, err := r.ExtractULID(secureULID)
+	if err != nil {
+		return nil, err
+	}
+	if r.unordered {
+		return ulidObj[:], nil
+	}
+	return ulidObj[6:], nil
+}
+
+// ExtractSequence extracts the position of a rigid ID in the monotonic sequence of its
+// millisecond: the entropy below the node ID, if r has one, as an unsigned integer. Of the IDs an
+// entropy source generates within a millisecond, later ones have greater sequence numbers, so
+// debugging tools can reconstruct their generation order; the numbers do not count IDs and start
+// at a random value in every millisecond. With several entropy shards (see WithEntropyShards),
+// sequence numbers are only ordered per shard. Like ExtractULID, it does not verify the ID.
+// Returns ErrNoSequence if r generates unordered IDs.
+func (r *Rigid) ExtractSequence(secureULID string) (*big.Int, error) {
+	if r.unordered {
+		return nil, ErrNoSequence
+	}
+	entropy, err := r.ExtractEntropy(secureULID)
+	if err != nil {
+		return nil, err
+	}
+	// Clear the node ID bits
+	nodeID{bits: r.gen.node.bits}.embed(entropy)
+	return new(big.Int).SetBytes(entropy), nil
+}
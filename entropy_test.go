@@ -0,0 +1,63 @@
+package rigid
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractEntropy(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	entropy, err := r.ExtractEntropy(id)
+	require.NoError(t, err)
+	u := ulid.MustParse(id[:ulid.EncodedSize])
+	assert.Equal(t, u.Entropy(), entropy)
+
+	_, err = r.ExtractEntropy("invalid")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = r.ExtractEntropy("!!!!!!!!!!!!!!!!!!!!!!!!!!-AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrInvalidULID)
+
+	unordered := r.WithUnordered()
+	id, err = unordered.Generate()
+	require.NoError(t, err)
+	entropy, err = unordered.ExtractEntropy(id)
+	require.NoError(t, err)
+	assert.Len(t, entropy, 16, "unordered IDs are random throughout")
+	_, err = unordered.ExtractSequence(id)
+	assert.ErrorIs(t, err, ErrNoSequence)
+}
+
+func TestExtractSequence(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	n, err := r.WithNodeID(0xFF, 8)
+	require.NoError(t, err)
+
+	limit := new(big.Int).Lsh(big.NewInt(1), 72)
+	for _, g := range []*Rigid{r, n} {
+		now := time.Now()
+		var prev *big.Int
+		for range 100 {
+			id, err := g.GenerateAt(now)
+			require.NoError(t, err)
+			seq, err := g.ExtractSequence(id)
+			require.NoError(t, err)
+			if prev != nil {
+				assert.Positive(t, seq.Cmp(prev), "sequence numbers increase within a millisecond")
+			}
+			prev = seq
+		}
+		if g == n {
+			assert.Negative(t, prev.Cmp(limit), "the node ID is not part of the sequence")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package rigid
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrEntropyUnavailable indicates no entropy source could supply random bytes. IDs are never
+// generated from weaker randomness instead.
+var ErrEntropyUnavailable = errors.New("entropy source unavailable")
+
+// EntropySource supplies the random bytes of the ULIDs an instance generates, see
+// WithEntropySource. Read must fill p entirely or return an error, and must be safe for
+// concurrent use.
+type EntropySource interface {
+	io.Reader
+	// HealthCheck reports whether the source can currently supply good entropy, returning an
+	// error describing the problem if it cannot.
+	HealthCheck() error
+}
+
+// entropySampleSize is the number of bytes HealthCheck of SystemEntropy reads.
+const entropySampleSize = 32
+
+// SystemEntropy returns the entropy source of the operating system, read through crypto/rand.
+// Its health check reads a sample and fails if the read fails or the sample repeats the
+// previous one, as a stuck generator would.
+func SystemEntropy() EntropySource {
+	return &systemEntropy{}
+}
+
+type systemEntropy struct {
+	mu   sync.Mutex
+	last []byte
+}
+
+func (s *systemEntropy) Read(p []byte) (int, error) {
+	return io.ReadFull(cryptorand.Reader, p)
+}
+
+func (s *systemEntropy) HealthCheck() error {
+	sample := make([]byte, entropySampleSize)
+	if _, err := s.Read(sample); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(sample, s.last) {
+		return errors.New("entropy sample repeats the previous one")
+	}
+	s.last = sample
+	return nil
+}
+
+// WithEntropySource returns a copy of r generating ULIDs from the first of sources, failing over
+// to the next source passing its health check whenever a read fails. Once all sources fail,
+// generation fails with ErrEntropyUnavailable rather than falling back to weaker randomness.
+// Without a source, IDs are generated from a pseudo-random sequence seeded from crypto/rand,
+// which is faster; with one, every new millisecond sequence and every unordered ID is drawn from
+// the source. Unlike the other With methods, the returned instance does not share its entropy
+// source with r. Returns ErrEntropyUnavailable if no source is given or none passes its health check.
+func (r *Rigid) WithEntropySource(sources ...EntropySource) (*Rigid, error) {
+	f := &failoverEntropy{sources: append([]EntropySource(nil), sources...), active: -1}
+	if err := f.failover(); err != nil {
+		return nil, err
+	}
+
+	c := r.clone()
+	c.gen = newGenerator(len(r.gen.shards), r.gen.node, r.gen.state, f)
+	return c, nil
+}
+
+// CheckEntropy runs the health check of every entropy source of r, for readiness probes and
+// alerting. Generation continues as long as one source is healthy, so a non-nil result, joining
+// the errors of the failing sources, may announce a failover before IDs fail. Instances without
+// WithEntropySource check SystemEntropy.
+func (r *Rigid) CheckEntropy() error {
+	sources := []EntropySource{SystemEntropy()}
+	if r.gen.source != nil {
+		sources = r.gen.source.sources
+	}
+
+	var errs []error
+	for i, s := range sources {
+		if err := s.HealthCheck(); err != nil {
+			errs = append(errs, fmt.Errorf("%w: source %d: %w", ErrEntropyUnavailable, i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// entropySources returns the number of entropy sources of g set with WithEntropySource.
+func (g *generator) entropySources() int {
+	if g.source == nil {
+		return 0
+	}
+	return len(g.source.sources)
+}
+
+// failoverEntropy reads from the active one of its sources, switching to the first healthy
+// source when a read fails. It has no active source once all have failed.
+type failoverEntropy struct {
+	sources []EntropySource
+	mu      sync.Mutex
+	active  int
+}
+
+func (f *failoverEntropy) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Try each source at most once, in case health checks pass while reads fail
+	readErr := ErrEntropyUnavailable
+	for range len(f.sources) {
+		if f.active < 0 {
+			if err := f.failover(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := f.sources[f.active].Read(p)
+		if err == nil && n == len(p) {
+			return n, nil
+		}
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		readErr = fmt.Errorf("%w: source %d: %w", ErrEntropyUnavailable, f.active, err)
+		if err := f.failover(); err != nil {
+			return 0, errors.Join(readErr, err)
+		}
+	}
+	return 0, readErr
+}
+
+// readULID returns a ULID read entirely from f, for unordered IDs. Reading in a separate
+// function keeps the ULIDs of other IDs off the heap.
+func (f *failoverEntropy) readULID() (ulid.ULID, error) {
+	var u ulid.ULID
+	_, err := io.ReadFull(f, u[:])
+	return u, err
+}
+
+// failover makes the first source passing its health check active, skipping the active source
+// whose read just failed unless it is the only one. f.mu must be held, unless f is not shared yet.
+func (f *failoverEntropy) failover() error {
+	failed := f.active
+	if len(f.sources) == 1 {
+		failed = -1
+	}
+	f.active = -1
+
+	var errs []error
+	for i, s := range f.sources {
+		if i == failed {
+			continue
+		}
+		err := s.HealthCheck()
+		if err == nil {
+			f.active = i
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%w: source %d: %w", ErrEntropyUnavailable, i, err))
+	}
+	if len(errs) == 0 {
+		return ErrEntropyUnavailable
+	}
+	return errors.Join(errs...)
+}
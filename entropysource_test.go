@@ -0,0 +1,107 @@
+package rigid
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEntropy is an EntropySource reading from SystemEntropy until it is broken.
+type testEntropy struct {
+	broken    atomic.Bool
+	unhealthy atomic.Bool
+	reads     atomic.Int64
+}
+
+var errBrokenEntropy = errors.New("broken entropy")
+
+func (e *testEntropy) Read(p []byte) (int, error) {
+	if e.broken.Load() {
+		return 0, errBrokenEntropy
+	}
+	e.reads.Add(1)
+	return SystemEntropy().Read(p)
+}
+
+func (e *testEntropy) HealthCheck() error {
+	if e.broken.Load() || e.unhealthy.Load() {
+		return errBrokenEntropy
+	}
+	return nil
+}
+
+func TestWithEntropySource(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	primary, backup := &testEntropy{}, &testEntropy{}
+	e, err := r.WithEntropySource(primary, backup)
+	require.NoError(t, err)
+	assert.NoError(t, e.CheckEntropy())
+	assert.Equal(t, 2, e.Config().EntropySources)
+	assert.Equal(t, 2, e.WithEntropyShards(4).Config().EntropySources, "sharding keeps the sources")
+
+	id, err := e.Generate()
+	require.NoError(t, err)
+	_, err = r.Verify(id)
+	assert.NoError(t, err, "the key is shared with r")
+	assert.Positive(t, primary.reads.Load())
+	assert.Zero(t, backup.reads.Load())
+
+	// A failing primary fails over to the backup, and the health check tells
+	primary.broken.Store(true)
+	for range 3 {
+		_, err := e.Generate()
+		require.NoError(t, err)
+		_, err = e.WithUnordered().Generate()
+		require.NoError(t, err)
+	}
+	assert.Positive(t, backup.reads.Load())
+	assert.ErrorIs(t, e.CheckEntropy(), ErrEntropyUnavailable)
+
+	// Without any healthy source, generation fails instead of degrading
+	backup.broken.Store(true)
+	_, err = e.WithUnordered().Generate()
+	assert.ErrorIs(t, err, ErrEntropyUnavailable)
+	assert.ErrorIs(t, err, errBrokenEntropy)
+
+	// Recovered sources are used again
+	primary.broken.Store(false)
+	_, err = e.WithUnordered().Generate()
+	assert.NoError(t, err)
+}
+
+func TestWithEntropySourceUnhealthy(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	_, err = r.WithEntropySource()
+	assert.ErrorIs(t, err, ErrEntropyUnavailable)
+
+	bad := &testEntropy{}
+	bad.unhealthy.Store(true)
+	_, err = r.WithEntropySource(bad)
+	assert.ErrorIs(t, err, ErrEntropyUnavailable)
+
+	// Sources failing their health check are skipped
+	good := &testEntropy{}
+	e, err := r.WithEntropySource(bad, good)
+	require.NoError(t, err)
+	_, err = e.Generate()
+	require.NoError(t, err)
+	assert.Zero(t, bad.reads.Load())
+	assert.Positive(t, good.reads.Load())
+}
+
+func TestSystemEntropy(t *testing.T) {
+	s := SystemEntropy()
+	assert.NoError(t, s.HealthCheck())
+	assert.NoError(t, s.HealthCheck())
+
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	assert.NoError(t, r.CheckEntropy())
+}
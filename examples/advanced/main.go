@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/session"
 )
 
 type UserService struct {
@@ -22,7 +24,7 @@ func NewUserService(secretKey []byte) *UserService {
 }
 
 func (s *UserService) CreateUser(username, role string) string {
-	metadata := fmt.Sprintf("user:%s:role:%s", username, role)
+	metadata := rigid.Meta().Str("user", username).Str("role", role).String()
 	userID, err := s.rigid.Generate(metadata)
 	if err != nil {
 		log.Fatal(err)
@@ -40,42 +42,7 @@ func (s *UserService) ValidateUser(userID string) (bool, string, string, error)
 		return false, "", "", nil
 	}
 
-	timestamp, err := s.rigid.ExtractTimestamp(userID)
-	if err != nil {
-		return false, "", "", err
-	}
-
-	return true, result.Metadata, timestamp.Format("2006-01-02 15:04:05"), nil
-}
-
-type SessionManager struct {
-	rigid *rigid.Rigid
-}
-
-func NewSessionManager(secretKey []byte) *SessionManager {
-	r, err := rigid.NewRigid(secretKey, 12)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	return &SessionManager{rigid: r}
-}
-
-func (s *SessionManager) CreateSession(userID, ipAddress string) string {
-	metadata := fmt.Sprintf("session:%s:ip:%s", userID, ipAddress)
-	sessionID, err := s.rigid.Generate(metadata)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return sessionID
-}
-
-func (s *SessionManager) ValidateSession(sessionID string) bool {
-	result, err := s.rigid.Verify(sessionID)
-	if err != nil {
-		return false
-	}
-	return result.Valid
+	return true, result.Metadata, result.Timestamp.Format("2006-01-02 15:04:05"), nil
 }
 
 func main() {
@@ -116,20 +83,33 @@ func main() {
 	}
 
 	fmt.Println("\n3. Session Management:")
-	sessionManager := NewSessionManager(secretKey)
+	sessionRigid, err := rigid.NewRigid(secretKey, 12)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sessionManager, err := session.NewManager(sessionRigid, session.WithTTL(time.Hour))
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	sessions := make([]string, len(userIDs))
+	ctx := context.Background()
+	sessions := make([]session.Session, len(userIDs))
 	for i, userID := range userIDs {
 		ipAddress := fmt.Sprintf("192.168.1.%d", i+10)
-		sessions[i] = sessionManager.CreateSession(userID, ipAddress)
-		fmt.Printf("   Session for user %d: %s\n", i+1, sessions[i])
+		sessions[i], err = sessionManager.Create(ctx, session.Claims{UserID: userID[:26], IP: ipAddress})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   Session for user %d: %s\n", i+1, sessions[i].ID)
 	}
 
 	fmt.Println("\n4. Session Validation:")
-	for i, sessionID := range sessions {
-		valid := sessionManager.ValidateSession(sessionID)
-		fmt.Printf("   Session %d: %t\n", i+1, valid)
+	for i, s := range sessions {
+		_, err := sessionManager.Validate(ctx, s.ID, s.IP)
+		fmt.Printf("   Session %d: %t (expires %s)\n", i+1, err == nil, s.ExpiresAt.Format("15:04:05"))
 	}
+	_, err = sessionManager.Validate(ctx, sessions[0].ID, "10.0.0.1")
+	fmt.Printf("   Session 1 from another IP: %v\n", err)
 
 	fmt.Println("\n5. Multi-instance compatibility:")
 
@@ -0,0 +1,31 @@
+// Command tiny verifies a device-provisioning ID with rigidtiny, and builds with TinyGo:
+//
+//	tinygo build -target=wasip1 -o tiny.wasm ./examples/tiny
+package main
+
+import (
+	"os"
+
+	"github.com/bahadrix/rigid-go/rigidtiny"
+)
+
+func main() {
+	secretKey := []byte("your-secret-key-here")
+	id := "dev_01ARZ3NDEKTSV4RRFFQ69G5FAV-ZAEKV4XUSN75U-device:42"
+	if len(os.Args) > 1 {
+		id = os.Args[1]
+	}
+
+	v, err := rigidtiny.NewVerifier(secretKey, rigidtiny.WithPrefix("dev"))
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
+
+	result, err := v.Verify(id)
+	if err != nil {
+		println("invalid:", err.Error())
+		os.Exit(1)
+	}
+	println("valid:", result.Metadata, "issued at", result.Timestamp)
+}
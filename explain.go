@@ -0,0 +1,161 @@
+package rigid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Checks reported by Explain, in the order Verify performs them.
+const (
+	CheckLength          = "length"
+	CheckFormat          = "format"
+	CheckPrefix          = "prefix"
+	CheckMetadata        = "metadata"
+	CheckULID            = "ulid"
+	CheckSignatureLength = "signature_length"
+	CheckSignature       = "signature"
+	CheckTimestamp       = "timestamp"
+	CheckExpiry          = "expiry"
+)
+
+// Segment locates a segment of an ID.
+type Segment struct {
+	// Offset is the byte offset of the segment in the ID, or -1 if the segment is absent.
+	Offset int
+	// Value is the segment.
+	Value string
+}
+
+// Report is a breakdown of the verification of an ID, see Rigid.Explain.
+type Report struct {
+	// ID is the explained ID.
+	ID string
+	// Length is the length of the ID in bytes, and MaxLength the maximum length of the instance,
+	// or zero if unlimited.
+	Length, MaxLength int
+
+	// Prefix, ULID, Signature and Metadata are the segments of the ID, all absent if the ID
+	// cannot be split.
+	Prefix, ULID, Signature, Metadata Segment
+	// ExpectedPrefix is the prefix of the instance.
+	ExpectedPrefix string
+
+	// ULIDValid reports whether the ULID segment parses, and ULIDCanonical whether it is in the
+	// canonical upper-case form Generate produces.
+	ULIDValid, ULIDCanonical bool
+	// Timestamp is the creation time embedded in a valid ULID, or the zero time for instances
+	// generating unordered IDs.
+	Timestamp time.Time
+
+	// SignatureLength is the signature length in bytes the signature segment was produced
+	// with, or zero if no allowed signature length produces a segment of its length.
+	SignatureLength int
+	// ExpectedSignatureLength is the signature length of the instance.
+	ExpectedSignatureLength int
+	// SignatureValid reports whether the signature segment is the one the instance computes
+	// for the other segments. Like Verify, Explain does not compute the signature of an ID
+	// failing an earlier check, so it is false then.
+	SignatureValid bool
+
+	// ExpiresAt is the expiry of an ID of an instance with a TTL, or the zero time.
+	ExpiresAt time.Time
+
+	// FailedCheck is the first check the ID failed, one of the Check constants, or empty if
+	// the ID is valid.
+	FailedCheck string
+	// Err is the error Verify returns for the ID, ignoring revocation and replay stores.
+	Err error
+}
+
+// Valid reports whether the ID passed all checks.
+func (rep Report) Valid() bool {
+	return rep.Err == nil
+}
+
+// String returns a multi-line, human-readable rendering of the report for logs and support
+// tickets. The signature segment is omitted, so the rendering cannot be used to replay the ID.
+func (rep Report) String() string {
+	var b strings.Builder
+	if rep.Valid() {
+		b.WriteString("valid\n")
+	} else {
+		fmt.Fprintf(&b, "invalid: %s check failed: %v\n", rep.FailedCheck, rep.Err)
+	}
+
+	fmt.Fprintf(&b, "length:    %d", rep.Length)
+	if rep.MaxLength > 0 {
+		fmt.Fprintf(&b, " (max %d)", rep.MaxLength)
+	}
+	fmt.Fprintf(&b, "\nprefix:    %q at %d (expected %q)\n", rep.Prefix.Value, rep.Prefix.Offset, rep.ExpectedPrefix)
+	fmt.Fprintf(&b, "ulid:      %q at %d (valid %t, canonical %t)\n", rep.ULID.Value, rep.ULID.Offset, rep.ULIDValid, rep.ULIDCanonical)
+	if !rep.Timestamp.IsZero() {
+		fmt.Fprintf(&b, "timestamp: %s\n", rep.Timestamp.UTC().Format(time.RFC3339Nano))
+	}
+	fmt.Fprintf(&b, "signature: %d chars at %d, %d bytes (expected %d, valid %t)\n", len(rep.Signature.Value),
+		rep.Signature.Offset, rep.SignatureLength, rep.ExpectedSignatureLength, rep.SignatureValid)
+	fmt.Fprintf(&b, "metadata:  %q at %d", rep.Metadata.Value, rep.Metadata.Offset)
+	if !rep.ExpiresAt.IsZero() {
+		fmt.Fprintf(&b, "\nexpires:   %s", rep.ExpiresAt.UTC().Format(time.RFC3339Nano))
+	}
+	return b.String()
+}
+
+// Explain verifies id like Verify and returns a breakdown of the checks, to answer why an ID is
+// rejected. Unlike Verify, it parses every segment even after a check has failed, but the
+// signature is only computed when Verify would compute it. Explain does
+// not consult the revocation and replay stores, and is not counted or reported to hooks, audit
+// sinks or detectors.
+func (r *Rigid) Explain(id string) Report {
+	rep := Report{
+		ID:                      id,
+		Length:                  len(id),
+		MaxLength:               r.maxLength,
+		Prefix:                  Segment{Offset: -1},
+		ULID:                    Segment{Offset: -1},
+		Signature:               Segment{Offset: -1},
+		Metadata:                Segment{Offset: -1},
+		ExpectedPrefix:          r.prefix,
+		ExpectedSignatureLength: r.signatureLength,
+	}
+
+	if seg, err := splitID(id); err == nil {
+		offset := 0
+		if seg.prefix != "" {
+			rep.Prefix = Segment{Offset: 0, Value: seg.prefix}
+			offset = len(seg.prefix) + 1
+		}
+		rep.ULID = Segment{Offset: offset, Value: seg.ulid}
+		offset += len(seg.ulid) + 1
+		rep.Signature = Segment{Offset: offset, Value: seg.signature}
+		if seg.hasMetadata {
+			rep.Metadata = Segment{Offset: offset + len(seg.signature) + 1, Value: seg.metadata}
+		}
+
+		if ulidObj, err := ulid.ParseStrict(seg.ulid); err == nil {
+			rep.ULIDValid = true
+			rep.ULIDCanonical = canonicalULID(ulidObj, seg.ulid) == seg.ulid
+			if !r.unordered {
+				rep.Timestamp = ulid.Time(ulidObj.Time())
+			}
+			if r.ttl > 0 && !r.unordered {
+				rep.ExpiresAt = rep.Timestamp.Add(r.ttl)
+			}
+		}
+
+		if validSignatureLength(len(seg.signature)) {
+			rep.SignatureLength = signatureEncoding.DecodedLen(len(seg.signature))
+		}
+	}
+
+	// The verdict is the one of Verify, so the signature is only computed once the checks
+	// before it have passed
+	_, rep.FailedCheck, rep.Err = r.verifySegments(id, true)
+	switch rep.FailedCheck {
+	case "", CheckTimestamp, CheckExpiry:
+		rep.SignatureValid = true
+	}
+	return rep
+}
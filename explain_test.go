@@ -0,0 +1,129 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainValid(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err := base.WithPrefix("ord")
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	seg, _ := splitID(id)
+
+	rep := r.Explain(id)
+	require.True(t, rep.Valid(), rep.String())
+	assert.Empty(t, rep.FailedCheck)
+	assert.Equal(t, Segment{Offset: 0, Value: "ord"}, rep.Prefix)
+	assert.Equal(t, Segment{Offset: 4, Value: seg.ulid}, rep.ULID)
+	assert.Equal(t, Segment{Offset: 31, Value: seg.signature}, rep.Signature)
+	assert.Equal(t, Segment{Offset: 31 + len(seg.signature) + 1, Value: "user:alice"}, rep.Metadata)
+	assert.Equal(t, "user:alice", id[rep.Metadata.Offset:])
+	assert.True(t, rep.ULIDValid)
+	assert.True(t, rep.ULIDCanonical)
+	assert.True(t, rep.SignatureValid)
+	assert.Equal(t, r.signatureLength, rep.SignatureLength)
+	assert.Equal(t, rep.Timestamp.Add(time.Hour), rep.ExpiresAt)
+
+	// The rendering omits the signature
+	s := rep.String()
+	assert.True(t, strings.HasPrefix(s, "valid\n"), s)
+	assert.NotContains(t, s, seg.signature)
+	assert.Contains(t, s, seg.ulid)
+}
+
+func TestExplainFailures(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	long, err := r.WithSignatureLength(16)
+	require.NoError(t, err)
+	longID, err := long.Generate()
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	typedID, err := typed.Generate()
+	require.NoError(t, err)
+	old, err := r.GenerateAt(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		r     *Rigid
+		id    string
+		check string
+		err   error
+	}{
+		{"too long", r.WithMaxLength(40), id, CheckLength, ErrTooLong},
+		{"unsplittable", r, "garbage", CheckFormat, ErrInvalidFormat},
+		{"prefix", r, typedID, CheckPrefix, ErrInvalidFormat},
+		{"metadata", r.WithPrintableMetadata(), id[:len(id)-5] + "\x00lice", CheckMetadata, ErrInvalidMetadata},
+		{"ulid", r, "!" + id[1:], CheckULID, ErrInvalidULID},
+		{"strict ulid", r.WithStrict(), strings.ToLower(id[:26]) + id[26:], CheckULID, ErrInvalidULID},
+		{"strict trailing", r.WithStrict(), id[:strings.LastIndex(id, "-")+1], CheckFormat, ErrInvalidFormat},
+		{"signature length", r, longID, CheckSignatureLength, ErrSignatureLengthMismatch},
+		{"signature", r, id[:27] + "AAAAAAAAAAAAA" + id[40:], CheckSignature, ErrIntegrityFailure},
+		{"metadata tampered", r, strings.Replace(id, "alice", "mallory", 1), CheckSignature, ErrIntegrityFailure},
+		{"timestamp", r.WithTimestampBounds(time.Now().Add(-time.Hour), time.Time{}), old, CheckTimestamp, ErrTimestampOutOfRange},
+		{"expiry", r.WithTTL(time.Hour), old, CheckExpiry, ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := tt.r.Explain(tt.id)
+			assert.False(t, rep.Valid())
+			assert.Equal(t, tt.check, rep.FailedCheck)
+			assert.ErrorIs(t, rep.Err, tt.err)
+			assert.Contains(t, rep.String(), "invalid: "+tt.check+" check failed")
+
+			// Explain agrees with Verify
+			_, err := tt.r.Verify(tt.id)
+			assert.Equal(t, err, rep.Err)
+		})
+	}
+
+	// Segments are examined after a check has failed
+	rep := r.Explain(longID)
+	assert.True(t, rep.ULIDValid)
+	assert.Equal(t, 16, rep.SignatureLength)
+	assert.Equal(t, r.signatureLength, rep.ExpectedSignatureLength)
+	assert.False(t, rep.SignatureValid)
+
+	// The signature is not computed for IDs failing an earlier check, as Verify does not
+	rep = r.WithMaxLength(40).Explain(id)
+	assert.Equal(t, CheckLength, rep.FailedCheck)
+	assert.True(t, rep.ULIDValid)
+	assert.False(t, rep.SignatureValid)
+
+	rep = r.WithTTL(time.Hour).Explain(old)
+	assert.True(t, rep.SignatureValid)
+	assert.False(t, rep.ExpiresAt.IsZero())
+
+	rep = r.Explain("garbage")
+	assert.Equal(t, -1, rep.ULID.Offset)
+	assert.Equal(t, -1, rep.Metadata.Offset)
+}
+
+func TestExplainAgreesWithVerify(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, g := range []*Rigid{r, r.WithStrict(), r.WithPrintableMetadata().WithNormalizedMetadata()} {
+		for _, id := range fuzzSeeds(t, r) {
+			for _, id := range []string{id, resign(g, id)} {
+				_, err := g.Verify(id)
+				rep := g.Explain(id)
+				assert.Equal(t, err, rep.Err, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rigid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxFilenameLength is the maximum length in bytes of a name returned by ID.Filename, the
+// file name limit of common filesystems.
+const MaxFilenameLength = 255
+
+// filenameEscape introduces an escaped byte in file names, followed by two upper-case hex digits.
+const filenameEscape = '.'
+
+// Filename returns a rendering of id safe as a file name and as an S3 object key segment, after
+// validating it like Validate does. ASCII letters, digits, '_' and '-' are kept, and every other
+// byte of the metadata, such as '/', ':' or '.', is written as '.' followed by two upper-case hex
+// digits, so names never contain path separators, never start or end with a dot, and differ
+// from the ID only where the metadata needs escaping. ParseFilename reverses it.
+// Returns an error wrapping ErrTooLong if the name would exceed MaxFilenameLength.
+func (id ID) Filename() (string, error) {
+	if err := id.Validate(); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(id))
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if filenameSafe(c) {
+			sb.WriteByte(c)
+			continue
+		}
+		sb.WriteByte(filenameEscape)
+		sb.WriteByte(upperhex[c>>4])
+		sb.WriteByte(upperhex[c&0xF])
+	}
+
+	if sb.Len() > MaxFilenameLength {
+		return "", fmt.Errorf("%w: file name of %d bytes", ErrTooLong, sb.Len())
+	}
+	return sb.String(), nil
+}
+
+// ParseFilename decodes a name returned by ID.Filename and validates the ID like decoding any
+// other value. Only the canonical rendering is accepted: bytes that need no escaping must not be
+// escaped, and escapes use upper-case hex digits.
+// Returns ErrInvalidFormat if name is not such a rendering.
+func ParseFilename(name string) (ID, error) {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c != filenameEscape {
+			if !filenameSafe(c) {
+				return "", ErrInvalidFormat
+			}
+			sb.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(name) {
+			return "", ErrInvalidFormat
+		}
+		hi, lo := unhexUpper(name[i+1]), unhexUpper(name[i+2])
+		if hi < 0 || lo < 0 || filenameSafe(byte(hi<<4|lo)) {
+			return "", ErrInvalidFormat
+		}
+		sb.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+
+	if sb.Len() == 0 {
+		return "", ErrInvalidFormat
+	}
+	var id ID
+	if err := id.decode(sb.String()); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+const upperhex = "0123456789ABCDEF"
+
+// filenameSafe reports whether c appears unescaped in file names.
+func filenameSafe(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// unhexUpper returns the value of the upper-case hex digit c, or -1.
+func unhexUpper(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10)
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilename(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithPrefix("doc")
+	require.NoError(t, err)
+
+	for _, metadata := range []string{"", "user:alice", "tenant/acme/file.pdf", "a.b-c_d", "..", "café \\ %2F"} {
+		generated, err := r.Generate(metadata)
+		require.NoError(t, err)
+		id := ID(generated)
+
+		name, err := id.Filename()
+		require.NoError(t, err)
+		assert.NotContains(t, name, "/")
+		assert.NotContains(t, name, ":")
+		assert.False(t, strings.HasSuffix(name, "."), name)
+		for _, c := range []byte(name) {
+			assert.True(t, filenameSafe(c) || c == filenameEscape, "%q in %q", c, name)
+		}
+
+		parsed, err := ParseFilename(name)
+		require.NoError(t, err)
+		assert.Equal(t, id, parsed)
+	}
+
+	id, err := r.Generate("tenant/acme:x.y")
+	require.NoError(t, err)
+	name, err := ID(id).Filename()
+	require.NoError(t, err)
+	assert.Equal(t, id[:strings.LastIndexByte(id, '-')]+"-tenant.2Facme.3Ax.2Ey", name)
+}
+
+func TestFilenameVerified(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	generated, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	forged := ID(generated[:len(generated)-5] + "bob")
+
+	_, err = ID("not a rigid id").Filename()
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	SetDefaultVerifier(r)
+	defer SetDefaultVerifier(nil)
+
+	_, err = forged.Filename()
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = ParseFilename(strings.ReplaceAll(string(forged), ":", ".3A"))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	name, err := ID(generated).Filename()
+	require.NoError(t, err)
+	parsed, err := ParseFilename(name)
+	require.NoError(t, err)
+	assert.Equal(t, ID(generated), parsed)
+}
+
+func TestFilenameTooLong(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate(strings.Repeat("/", 100))
+	require.NoError(t, err)
+
+	_, err = ID(id).Filename()
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestParseFilenameInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("a:b")
+	require.NoError(t, err)
+	name, err := ID(id).Filename()
+	require.NoError(t, err)
+
+	for _, bad := range []string{
+		"",
+		strings.ReplaceAll(name, ".3A", ":"),   // unescaped
+		strings.ReplaceAll(name, ".3A", ".3a"), // lower-case hex
+		strings.ReplaceAll(name, "-a", "-.61"), // needless escape
+		name + ".",                             // truncated escape
+		name + ".4",                            // truncated escape
+		name + ".ZZ",                           // not hex
+	} {
+		_, err := ParseFilename(bad)
+		assert.ErrorIs(t, err, ErrInvalidFormat, bad)
+	}
+}
@@ -0,0 +1,156 @@
+package rigid
+
+import (
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// FormatName is the name under which rigid IDs are registered as an OpenAPI string format
+// and as a validation tag, e.g. `format: rigid` or `validate:"rigid"`.
+const FormatName = "rigid"
+
+// Pattern is a regular expression matching the canonical form of rigid IDs: an optional
+// alphanumeric prefix, qualified by an alphanumeric issuer and a dot in IDs of issuers, an
+// upper-case ULID, an upper-case base32 signature, and optional metadata.
+// It is valid in both Go and ECMAScript syntax for use in OpenAPI and JSON Schema documents.
+// The pattern is slightly more permissive than ValidateFormat about signature lengths.
+const Pattern = `^(?:[A-Za-z0-9]+\.[A-Za-z0-9]*_|[A-Za-z0-9]+_)?[0-7][0-9A-HJKMNP-TV-Z]{25}-[A-Z2-7]{7,52}(?:-[\s\S]+)?$`
+
+// signatureEncoding is the base32 encoding used for signature segments.
+var signatureEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// IsWellFormed reports whether id has the structure of a rigid ID.
+// See ValidateFormat for the checks performed.
+func IsWellFormed(id string) bool {
+	return ValidateFormat(id) == nil
+}
+
+// ValidateFormat checks the structure of a rigid ID without a secret key.
+// It verifies that the type prefix, if any, is alphanumeric, that the ULID segment is a valid ULID, that the signature segment has a length
+// produced by some signature length between MinSignatureLength and MaxSignatureLength and uses
+// the upper-case base32 alphabet, and that a metadata segment, if present, is not empty.
+// A nil result does not mean the ID is authentic; use Verify for that.
+// Returns ErrInvalidFormat or ErrInvalidULID describing the first problem found.
+func ValidateFormat(id string) error {
+	seg, err := splitID(id)
+	if err != nil {
+		return err
+	}
+
+	if !validPrefixSegment(seg.prefix) {
+		return ErrInvalidFormat
+	}
+
+	if _, err := ulid.ParseStrict(seg.ulid); err != nil {
+		return ErrInvalidULID
+	}
+
+	if !validSignatureLength(len(seg.signature)) || !validSignatureChars(seg.signature) {
+		return ErrInvalidFormat
+	}
+
+	if seg.hasMetadata && seg.metadata == "" {
+		return ErrInvalidFormat
+	}
+
+	return nil
+}
+
+// Parts holds the segments of a structurally valid rigid ID.
+type Parts struct {
+	// Issuer is the issuer, or empty if the ID carries none.
+	Issuer string
+	// Prefix is the type prefix, or empty if the ID has none.
+	Prefix string
+	// ULID is the ULID segment.
+	ULID string
+	// Timestamp is the creation time embedded in the ULID.
+	Timestamp time.Time
+	// Signature is the base32 signature segment.
+	Signature string
+	// SignatureLength is the length of the signature in bytes.
+	SignatureLength int
+	// Metadata is the metadata segment, or empty if the ID has none.
+	Metadata string
+}
+
+// Parse splits id into its segments after checking its structure as ValidateFormat does.
+// Like ValidateFormat it does not need a secret key, and a nil error does not mean the ID is authentic.
+func Parse(id string) (Parts, error) {
+	if err := ValidateFormat(id); err != nil {
+		return Parts{}, err
+	}
+
+	seg, _ := splitID(id)
+	ulidObj, _ := ulid.ParseStrict(seg.ulid)
+	issuer := issuerOf(seg.prefix)
+
+	return Parts{
+		Issuer:          issuer,
+		Prefix:          unqualifyPrefix(issuer, seg.prefix),
+		ULID:            seg.ulid,
+		Timestamp:       ulid.Time(ulidObj.Time()),
+		Signature:       seg.signature,
+		SignatureLength: signatureEncoding.DecodedLen(len(seg.signature)),
+		Metadata:        seg.metadata,
+	}, nil
+}
+
+// EncodedLen returns the length of a rigid ID with the given prefix length, signature length in bytes,
+// and metadata length. A zero prefixLen or metadataLen means the segment is absent.
+// It is intended for sizing database columns and buffers.
+func EncodedLen(prefixLen, signatureLength, metadataLen int) int {
+	n := ulid.EncodedSize + 1 + signatureEncoding.EncodedLen(signatureLength)
+	if prefixLen > 0 {
+		n += prefixLen + 1
+	}
+	if metadataLen > 0 {
+		n += metadataLen + 1
+	}
+	return n
+}
+
+// validSignatureLength reports whether n is the encoded length of an allowed signature length.
+func validSignatureLength(n int) bool {
+	for sigLen := MinSignatureLength; sigLen <= MaxSignatureLength; sigLen++ {
+		if signatureEncoding.EncodedLen(sigLen) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// validSignatureChars reports whether signature only uses the upper-case base32 alphabet.
+func validSignatureChars(signature string) bool {
+	for i := 0; i < len(signature); i++ {
+		c := signature[i]
+		if (c < 'A' || c > 'Z') && (c < '2' || c > '7') {
+			return false
+		}
+	}
+	return true
+}
+
+// validPrefixSegment reports whether p is a valid prefix segment: a type prefix, optionally
+// qualified by an issuer as ISSUER.PREFIX.
+func validPrefixSegment(p string) bool {
+	if issuer, prefix, ok := strings.Cut(p, "."); ok {
+		return issuer != "" && validPrefix(issuer) && validPrefix(prefix)
+	}
+	return validPrefix(p)
+}
+
+// validPrefix reports whether p is usable as an ID type prefix.
+// The empty string is valid and means no prefix.
+func validPrefix(p string) bool {
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package rigid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFormat(t *testing.T) {
+	for _, sigLen := range []int{MinSignatureLength, 8, 12, 16, MaxSignatureLength} {
+		r, err := NewRigid(testSecretKey, sigLen)
+		require.NoError(t, err)
+
+		id, err := r.Generate()
+		require.NoError(t, err)
+		assert.NoError(t, ValidateFormat(id), "sigLen=%d", sigLen)
+
+		id, err = r.Generate("user:alice-smith")
+		require.NoError(t, err)
+		assert.True(t, IsWellFormed(id), "sigLen=%d", sigLen)
+	}
+}
+
+func TestValidateFormatInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	ulidStr, signature, _ := strings.Cut(id, "-")
+
+	tests := map[string]error{
+		"":                               ErrInvalidFormat,
+		"nohyphen":                       ErrInvalidFormat,
+		"12345-" + signature:             ErrInvalidULID,
+		"ZZZZZZZZZZZZZZZZZZZZZZZZZZ-SIG": ErrInvalidULID,
+		"01ARZ3NDEKTSV4RRFFQ69G5F!V-" + signature: ErrInvalidULID,
+		ulidStr + "-":    ErrInvalidFormat,
+		ulidStr + "-ABC": ErrInvalidFormat,
+		ulidStr + "-" + strings.ToLower(signature): ErrInvalidFormat,
+		ulidStr + "-" + signature + "1":            ErrInvalidFormat,
+		id + "-":                                   ErrInvalidFormat,
+	}
+
+	for input, expected := range tests {
+		assert.Equal(t, expected, ValidateFormat(input), "input: %q", input)
+		assert.False(t, IsWellFormed(input), "input: %q", input)
+	}
+}
+
+func TestEncodedLen(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, sigLen := range []int{MinSignatureLength, DefaultSignatureLength, 13, MaxSignatureLength} {
+		r, err := r.WithSignatureLength(sigLen)
+		require.NoError(t, err)
+
+		id, err := r.Generate()
+		require.NoError(t, err)
+		assert.Len(t, id, EncodedLen(0, sigLen, 0), "sigLen=%d", sigLen)
+
+		r, err = r.WithPrefix("usr")
+		require.NoError(t, err)
+		id, err = r.Generate("user:alice")
+		require.NoError(t, err)
+		assert.Len(t, id, EncodedLen(3, sigLen, 10), "sigLen=%d", sigLen)
+	}
+
+	assert.Equal(t, 40, EncodedLen(0, DefaultSignatureLength, 0))
+}
+
+func TestPattern(t *testing.T) {
+	re := regexp.MustCompile(Pattern)
+
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	prefixed, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	issuer, err := r.WithIssuer("acme")
+	require.NoError(t, err)
+	prefixedIssuer, err := issuer.WithPrefix("sk")
+	require.NoError(t, err)
+
+	for _, g := range []*Rigid{r, prefixed, issuer, prefixedIssuer} {
+		for _, metadata := range []string{"", "user:alice-smith", "multi\nline"} {
+			id, err := g.Generate(metadata)
+			require.NoError(t, err)
+			assert.True(t, re.MatchString(id), id)
+		}
+	}
+
+	for _, id := range []string{
+		"",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		"01arz3ndektsv4rrffq69g5fav-MFRGG2BA",
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2B1",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA-",
+		"usr-01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+		"_01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+		".sk_01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+		"acme.01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+		"acme.sk.x_01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA",
+	} {
+		assert.False(t, re.MatchString(id), id)
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 12)
+	require.NoError(t, err)
+	prefixed, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(time.Millisecond)
+	id, err := prefixed.GenerateAt(now, "user:alice-smith")
+	require.NoError(t, err)
+
+	parts, err := Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, "usr", parts.Prefix)
+	assert.Equal(t, id[4:30], parts.ULID)
+	assert.True(t, now.Equal(parts.Timestamp))
+	assert.Equal(t, 12, parts.SignatureLength)
+	assert.Equal(t, id[31:51], parts.Signature)
+	assert.Equal(t, "user:alice-smith", parts.Metadata)
+
+	_, err = Parse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
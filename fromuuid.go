@@ -0,0 +1,30 @@
+package rigid
+
+import (
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+// FromUUID wraps an existing UUID, such as a UUIDv1 or UUIDv4 key of a legacy system, in a signed
+// rigid ID with optional metadata, for migrating UUID-keyed systems. The 128 bits of the UUID become
+// the ULID component unchanged, so ToUUID recovers the UUID. Wrapped UUIDs carry no meaningful
+// timestamp; verify them with an instance configured with WithUnordered, which skips the TTL and
+// timestamp bound checks.
+// Like Sign, wrapping is counted, audited and reported to hooks like Generate.
+func (r *Rigid) FromUUID(u uuid.UUID, metadata ...string) (string, error) {
+	return r.Sign(ulid.ULID(u), metadata...)
+}
+
+// ToUUID verifies id like Verify and returns its ULID component as a UUID, unwrapping IDs made by
+// FromUUID. IDs generated by r convert too, for storing them in UUID columns.
+func (r *Rigid) ToUUID(id string) (uuid.UUID, error) {
+	result, err := r.Verify(id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	ulidObj, err := ulid.ParseStrict(result.ULID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.UUID(ulidObj), nil
+}
@@ -0,0 +1,53 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromUUID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	legacy := r.WithUnordered().WithTTL(time.Hour)
+
+	v1, err := uuid.NewUUID()
+	require.NoError(t, err)
+	for _, u := range []uuid.UUID{uuid.New(), v1, uuid.Nil, uuid.Max} {
+		id, err := legacy.FromUUID(u, "user:alice")
+		require.NoError(t, err)
+
+		result, err := legacy.Verify(id)
+		require.NoError(t, err, "UUID %s", u)
+		assert.Equal(t, "user:alice", result.Metadata)
+
+		got, err := legacy.ToUUID(id)
+		require.NoError(t, err)
+		assert.Equal(t, u, got)
+	}
+
+	id, err := legacy.FromUUID(uuid.New())
+	require.NoError(t, err)
+	_, err = legacy.ToUUID(id[:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	assert.Equal(t, uint64(5), legacy.Stats().Generated)
+
+	typed, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	_, err = typed.WithMaxLength(10).FromUUID(uuid.New())
+	assert.ErrorIs(t, err, ErrTooLong)
+	_, err = typed.WithPrintableMetadata().FromUUID(uuid.New(), "\x00")
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	// IDs generated natively convert as well
+	native, err := typed.Generate()
+	require.NoError(t, err)
+	u, err := typed.ToUUID(native)
+	require.NoError(t, err)
+	ulidObj, err := typed.ExtractULID(native)
+	require.NoError(t, err)
+	assert.Equal(t, ulidObj[:], u[:])
+}
@@ -0,0 +1,165 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// fuzzSeeds returns IDs covering each form Verify and Parse accept, plus near misses.
+func fuzzSeeds(t testing.TB, r *Rigid) []string {
+	typed, err := r.WithPrefix("ord")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var seeds []string
+	for _, g := range []*Rigid{r, typed} {
+		for _, metadata := range []string{"", "user:alice-admin", "\x00", "é"} {
+			id, err := g.Generate(metadata)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seeds = append(seeds, id, strings.ToLower(id), id[:len(id)-1], id+"-")
+		}
+	}
+	return append(seeds,
+		"", "-", "--", "_-", "a_-", "01ARZ3NDEKTSV4RRFFQ69G5FAV-", "01ARZ3NDEKTSV4RRFFQ69G5FAV--meta",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA", "ZZZZZZZZZZZZZZZZZZZZZZZZZZ-MFRGG2BA", "01ARZ3NDEKTSV4RRFFQ69G5F!V-MFRGG2BA",
+	)
+}
+
+func FuzzVerify(f *testing.F) {
+	r, err := NewRigid(testSecretKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	strict := r.WithStrict()
+	for _, id := range fuzzSeeds(f, r) {
+		f.Add(id, false)
+		f.Add(id, true)
+	}
+
+	f.Fuzz(func(t *testing.T, id string, sign bool) {
+		// Forged signatures are out of the fuzzer's reach, so it may have the segments signed
+		// to exercise what Verify accepts
+		if sign {
+			id = resign(r, id)
+		}
+
+		result, err := r.Verify(id)
+		if rep := r.Explain(id); rep.Err != err {
+			t.Fatalf("Explain(%q) error %v, Verify returned %v", id, rep.Err, err)
+		}
+		if err != nil {
+			if result != (VerifyResult{}) {
+				t.Fatalf("Verify(%q) returned %+v with error %v", id, result, err)
+			}
+			if _, err := strict.Verify(id); err == nil {
+				t.Fatalf("strict Verify(%q) accepted an ID rejected by Verify", id)
+			}
+			return
+		}
+
+		// Accepted IDs carry a valid ULID and signature, and their segments are those of id,
+		// with the ULID in canonical form
+		if !result.Valid || !strings.HasSuffix(id, result.Metadata) {
+			t.Fatalf("Verify(%q) = %+v", id, result)
+		}
+		seg, _ := splitID(id)
+		ulidObj, err := ulid.ParseStrict(seg.ulid)
+		if err != nil {
+			t.Fatalf("Verify(%q) accepted ULID %q: %v", id, seg.ulid, err)
+		}
+		if result.ULID != ulidObj.String() {
+			t.Fatalf("Verify(%q) ULID %q, canonical form is %q", id, result.ULID, ulidObj.String())
+		}
+		if !result.Timestamp.Equal(ulid.Time(ulidObj.Time())) {
+			t.Fatalf("Verify(%q) timestamp %v", id, result.Timestamp)
+		}
+		if _, err := r.VerifyBytes([]byte(id)); err != nil {
+			t.Fatalf("VerifyBytes(%q) = %v, Verify accepted it", id, err)
+		}
+
+		// Strictly verified IDs are exactly those Generate produces
+		if _, err := strict.Verify(id); err == nil {
+			if err := ValidateFormat(id); err != nil {
+				t.Fatalf("strict Verify accepted %q failing ValidateFormat: %v", id, err)
+			}
+			regenerated := rebuild(t, r, result)
+			if regenerated != id {
+				t.Fatalf("strict Verify accepted %q, Generate renders it %q", id, regenerated)
+			}
+		}
+	})
+}
+
+// resign replaces the signature segment of id with the one r computes for its other segments.
+func resign(r *Rigid, id string) string {
+	seg, err := splitID(id)
+	if err != nil {
+		return id
+	}
+	mac := r.getMAC(r.version)
+	defer r.putMAC(r.version, mac)
+	signature := string(mac.signature(r.version, seg.prefix, seg.ulid, seg.metadata, r.signatureLength))
+	i := strings.Index(id, "-")
+	return id[:i+1] + signature + id[i+1+len(seg.signature):]
+}
+
+// rebuild renders the ID with the ULID and metadata of result as Generate would.
+func rebuild(t *testing.T, r *Rigid, result VerifyResult) string {
+	mac := r.getMAC(r.version)
+	defer r.putMAC(r.version, mac)
+	id := result.ULID + "-" + string(mac.signature(r.version, r.prefix, result.ULID, result.Metadata, r.signatureLength))
+	if result.Metadata != "" {
+		id += "-" + result.Metadata
+	}
+	return id
+}
+
+func FuzzParse(f *testing.F) {
+	r, err := NewRigid(testSecretKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, id := range fuzzSeeds(f, r) {
+		f.Add(id)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		parts, err := Parse(id)
+		if (err == nil) != IsWellFormed(id) {
+			t.Fatalf("Parse(%q) error %v disagrees with IsWellFormed", id, err)
+		}
+		if err != nil {
+			if parts != (Parts{}) {
+				t.Fatalf("Parse(%q) returned %+v with error %v", id, parts, err)
+			}
+			return
+		}
+
+		// The segments of a well-formed ID reassemble into it
+		rendered := parts.ULID + "-" + parts.Signature
+		if parts.Prefix != "" {
+			rendered = parts.Prefix + "_" + rendered
+		}
+		if parts.Metadata != "" {
+			rendered += "-" + parts.Metadata
+		}
+		if rendered != id {
+			t.Fatalf("Parse(%q) = %+v, which renders as %q", id, parts, rendered)
+		}
+		if parts.SignatureLength < MinSignatureLength || parts.SignatureLength > MaxSignatureLength {
+			t.Fatalf("Parse(%q) signature length %d", id, parts.SignatureLength)
+		}
+		if parts.Timestamp.After(time.UnixMilli(int64(ulid.MaxTime()))) {
+			t.Fatalf("Parse(%q) timestamp %v", id, parts.Timestamp)
+		}
+		if _, err := ulid.ParseStrict(parts.ULID); err != nil {
+			t.Fatalf("Parse(%q) accepted ULID %q: %v", id, parts.ULID, err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Package gormtype provides a rigid ID type for GORM and sqlx models.
+//
+// Declare model fields as gormtype.ID to have rigid IDs validated whenever rows are scanned:
+//
+//	type Order struct {
+//		ID     gormtype.ID `gorm:"primaryKey;size:64"`
+//		Amount int
+//	}
+//
+// ID implements sql.Scanner and driver.Valuer, so the same type works unchanged with sqlx
+// and database/sql. Validation follows rigid.ID: a structural check by default, or full
+// signature verification once rigid.SetDefaultVerifier has been called.
+//
+// # Column Sizing
+//
+// Without metadata, a rigid ID is 26 characters of ULID, a hyphen, and the base32 signature:
+// 40 characters with the default 8-byte signature and 79 with a 32-byte signature. Use
+// rigid.EncodedLen to compute the size for a given prefix, signature length, and maximum
+// metadata length, and declare it with the `size` tag. Fields without a size use DefaultSize.
+package gormtype
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// DefaultSize is the column size used for ID fields without an explicit `size` tag.
+// It accommodates every signature length together with a moderately sized prefix and metadata.
+const DefaultSize = 255
+
+// ID is a rigid ID usable as a GORM or sqlx model field type.
+type ID rigid.ID
+
+// String returns the ID as a string.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Scan implements sql.Scanner, validating the scanned value as rigid.ID does.
+func (id *ID) Scan(src any) error {
+	return (*rigid.ID)(id).Scan(src)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the ID as a JSON string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return rigid.ID(id).MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, validating the decoded value as rigid.ID does.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	return (*rigid.ID)(id).UnmarshalJSON(data)
+}
+
+// Value implements driver.Valuer. The empty ID is stored as NULL.
+func (id ID) Value() (driver.Value, error) {
+	return rigid.ID(id).Value()
+}
+
+// GormDataType returns the general data type used by GORM for migrations.
+func (ID) GormDataType() string {
+	return string(schema.String)
+}
+
+// GormDBDataType returns the column type for the dialect in use, sized by the field's
+// `size` tag or DefaultSize. IDs are ASCII apart from metadata, so a variable-length
+// character column is used on every dialect.
+func (ID) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	size := DefaultSize
+	if field != nil && field.Size > 0 {
+		size = field.Size
+	}
+
+	switch db.Dialector.Name() {
+	case "sqlserver":
+		return fmt.Sprintf("nvarchar(%d)", size)
+	default:
+		return fmt.Sprintf("varchar(%d)", size)
+	}
+}
@@ -0,0 +1,74 @@
+package gormtype
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+type order struct {
+	ID     ID `gorm:"primaryKey;size:64"`
+	Parent ID
+	Amount int
+}
+
+func TestScanValue(t *testing.T) {
+	r, err := rigid.NewRigid([]byte("test-secret-key-for-rigid-testing"))
+	require.NoError(t, err)
+
+	generated, err := r.Generate("order:42")
+	require.NoError(t, err)
+
+	var id ID
+	require.NoError(t, id.Scan([]byte(generated)))
+	assert.Equal(t, generated, id.String())
+
+	v, err := id.Value()
+	require.NoError(t, err)
+	assert.Equal(t, generated, v)
+
+	assert.Equal(t, rigid.ErrInvalidFormat, id.Scan("garbage"))
+	assert.Equal(t, generated, id.String(), "failed scans must not modify the ID")
+
+	v, err = ID("").Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestJSON(t *testing.T) {
+	var o struct {
+		ID ID `json:"id"`
+	}
+	err := json.Unmarshal([]byte(`{"id":"not-an-id"}`), &o)
+	assert.ErrorIs(t, err, rigid.ErrInvalidULID)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BAMFRGG"}`), &o))
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BAMFRGG"}`, string(data))
+}
+
+func TestGormDataTypes(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{})
+	require.NoError(t, err)
+
+	s, err := schema.Parse(&order{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	idField := s.LookUpField("ID")
+	require.NotNil(t, idField)
+	assert.Equal(t, schema.String, idField.DataType)
+	assert.Equal(t, "varchar(64)", ID("").GormDBDataType(db, idField))
+
+	parentField := s.LookUpField("Parent")
+	require.NotNil(t, parentField)
+	assert.Equal(t, "varchar(255)", ID("").GormDBDataType(db, parentField))
+}
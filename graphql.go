@@ -0,0 +1,31 @@
+package rigid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MarshalGQL implements the gqlgen Marshaler interface, writing the ID as a GraphQL string.
+//
+// To use ID as a custom scalar, declare it in the schema and map it in gqlgen.yml:
+//
+//	scalar RigidID
+//
+//	models:
+//	  RigidID:
+//	    model: github.com/bahadrix/rigid-go.ID
+func (id ID) MarshalGQL(w io.Writer) {
+	b, _ := json.Marshal(string(id))
+	_, _ = w.Write(b)
+}
+
+// UnmarshalGQL implements the gqlgen Unmarshaler interface. The input must be a string and is
+// validated like any other decoded ID, so invalid IDs are rejected at the schema boundary.
+func (id *ID) UnmarshalGQL(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%w: RigidID must be a string, got %T", ErrInvalidFormat, v)
+	}
+	return id.decode(s)
+}
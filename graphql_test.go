@@ -0,0 +1,45 @@
+package rigid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDGraphQL(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate(`quote:"hi"`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	ID(generated).MarshalGQL(&buf)
+	assert.Equal(t, `"`+generated[:len(generated)-4]+`\"hi\""`, buf.String())
+
+	var id ID
+	require.NoError(t, id.UnmarshalGQL(generated))
+	assert.Equal(t, ID(generated), id)
+
+	assert.Equal(t, ErrInvalidULID, id.UnmarshalGQL("forged-id"))
+	assert.ErrorIs(t, id.UnmarshalGQL(42), ErrInvalidFormat)
+	assert.Equal(t, ID(generated), id)
+}
+
+func TestIDGraphQLWithDefaultVerifier(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	SetDefaultVerifier(r)
+	defer SetDefaultVerifier(nil)
+
+	forged, err := other.Generate()
+	require.NoError(t, err)
+
+	var id ID
+	assert.Equal(t, ErrIntegrityFailure, id.UnmarshalGQL(forged))
+}
@@ -0,0 +1,250 @@
+package rigid
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrInvalidHierarchy indicates the levels passed to Rigid.Hierarchy are invalid, or an ID was
+// asked to descend below the deepest level of its hierarchy.
+var ErrInvalidHierarchy = errors.New("invalid ID hierarchy")
+
+// MaxHierarchyDepth is the maximum number of levels of a Hierarchy.
+const MaxHierarchyDepth = 8
+
+// Hierarchy generates and verifies hierarchical IDs, which name a path of entities such as
+// organization, project and resource, each level with its own ULID, under one signature covering
+// the whole path:
+//
+//	org_01ARZ3NDEKTSV4RRFFQ69G5FAV_prj_01BX5ZZKBKACTAV9WEVGEMMVRZ-MFRGG2BAMFRGG2BA[-metadata]
+//
+// An ID of any depth verifies only as a whole, so a resource ID cannot be moved under another
+// project or organization, and the ULIDs of its ancestors can be trusted without further lookups.
+// Hierarchical IDs are signed with a key derived from the signing key of the instance for the
+// level names, so they never verify as plain rigid IDs or as IDs of another hierarchy. The
+// signature length, maximum length, metadata checks and ULID generation of the instance apply.
+// Its type prefix, issuers, format version, key buckets and strict mode are not used, and Verify
+// enforces neither its TTL, timestamp bounds and maximum clock skew nor its revocation, replay
+// and rate limiting, stats or hooks; check the level timestamps where these matter.
+// Hierarchy is safe for concurrent use.
+type Hierarchy struct {
+	r      *Rigid
+	levels []string
+	macs   *sync.Pool
+}
+
+// HierarchyLevel is a level of a verified hierarchical ID.
+type HierarchyLevel struct {
+	// Name is the name of the level, such as "org".
+	Name string
+	// ULID is the ULID of the entity at this level, in canonical encoding.
+	ULID string
+	// Timestamp is the creation time embedded in the ULID, or the zero time for unordered instances.
+	Timestamp time.Time
+}
+
+// HierarchyResult is the result of verifying a hierarchical ID.
+type HierarchyResult struct {
+	// Valid indicates whether the ID passed integrity verification.
+	Valid bool
+	// Levels are the levels of the ID from the root down; the last one is the entity the ID names.
+	Levels []HierarchyLevel
+	// Metadata is the metadata bound to the ID, if any.
+	Metadata string
+}
+
+// Level returns the level with the given name, and whether the ID reaches that deep.
+func (res HierarchyResult) Level(name string) (HierarchyLevel, bool) {
+	for _, l := range res.Levels {
+		if l.Name == name {
+			return l, true
+		}
+	}
+	return HierarchyLevel{}, false
+}
+
+// ULID returns the ULID of the entity the ID names, at its deepest level.
+func (res HierarchyResult) ULID() string {
+	if len(res.Levels) == 0 {
+		return ""
+	}
+	return res.Levels[len(res.Levels)-1].ULID
+}
+
+// Hierarchy returns a Hierarchy whose IDs have the given levels, from the root down, such as
+// "org", "project" and "resource". Level names must be distinct, non-empty and ASCII alphanumeric.
+// Returns ErrInvalidHierarchy if they are not, or if there are none or more than MaxHierarchyDepth.
+func (r *Rigid) Hierarchy(levels ...string) (*Hierarchy, error) {
+	if len(levels) == 0 || len(levels) > MaxHierarchyDepth {
+		return nil, ErrInvalidHierarchy
+	}
+	for i, l := range levels {
+		if l == "" || !validPrefix(l) || slices.Contains(levels[:i], l) {
+			return nil, ErrInvalidHierarchy
+		}
+	}
+
+	key := hkdf(r.macKey, formatDomain+"hierarchy\x00"+strings.Join(levels, "\x00"))
+	return &Hierarchy{r: r, levels: append([]string(nil), levels...), macs: newMACPool(key)}, nil
+}
+
+// Levels returns the level names of h.
+func (h *Hierarchy) Levels() []string {
+	return append([]string(nil), h.levels...)
+}
+
+// Generate creates the ID of a new entity at the root level with optional metadata, as
+// Rigid.Generate does.
+func (h *Hierarchy) Generate(metadata ...string) (string, error) {
+	return h.generate("", 0, metadata)
+}
+
+// Child verifies parent and creates the ID of a new entity one level below it, carrying the
+// ULIDs of parent and optional metadata. The metadata of parent is not inherited.
+// Returns the verification error of parent, or ErrInvalidHierarchy if parent is at the deepest level.
+func (h *Hierarchy) Child(parent string, metadata ...string) (string, error) {
+	res, err := h.Verify(parent)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Levels) == len(h.levels) {
+		return "", ErrInvalidHierarchy
+	}
+	return h.generate(canonicalHead(res.Levels)+"_", len(res.Levels), metadata)
+}
+
+// Parent verifies id and returns the ID of its parent entity, signed anew and without metadata.
+// Returns ErrInvalidHierarchy if id is at the root level.
+func (h *Hierarchy) Parent(id string) (string, error) {
+	res, err := h.Verify(id)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Levels) == 1 {
+		return "", ErrInvalidHierarchy
+	}
+	return h.render(canonicalHead(res.Levels[:len(res.Levels)-1]), "")
+}
+
+// Verify checks the integrity and authenticity of a hierarchical ID of h, at any depth.
+// Returns ErrInvalidFormat or ErrInvalidULID if id is malformed or its levels do not follow
+// those of h, ErrTooLong if it exceeds the maximum length of the instance, ErrInvalidMetadata if
+// the instance requires printable metadata and it is not, and ErrIntegrityFailure if the
+// signature does not match. Metadata is normalized before verification if the instance
+// normalizes it. The TTL and timestamp bounds of the instance are not checked.
+func (h *Hierarchy) Verify(id string) (HierarchyResult, error) {
+	if h.r.maxLength > 0 && len(id) > h.r.maxLength {
+		return HierarchyResult{}, ErrTooLong
+	}
+
+	head, rest, ok := strings.Cut(id, "-")
+	if !ok {
+		return HierarchyResult{}, ErrInvalidFormat
+	}
+	signature, metadata, hasMetadata := strings.Cut(rest, "-")
+	if signature == "" || (hasMetadata && metadata == "") {
+		return HierarchyResult{}, ErrInvalidFormat
+	}
+
+	// Metadata is checked as Rigid.Verify does, so hierarchical IDs do not bypass the policy
+	if h.r.normalize {
+		metadata = norm.NFC.String(metadata)
+	}
+	if h.r.printable && !printable(metadata) {
+		return HierarchyResult{}, ErrInvalidMetadata
+	}
+
+	parts := strings.Split(head, "_")
+	if len(parts)%2 != 0 || len(parts)/2 > len(h.levels) {
+		return HierarchyResult{}, ErrInvalidFormat
+	}
+	levels := make([]HierarchyLevel, len(parts)/2)
+	for i := range levels {
+		if parts[2*i] != h.levels[i] {
+			return HierarchyResult{}, ErrInvalidFormat
+		}
+		u, err := ulid.ParseStrict(parts[2*i+1])
+		if err != nil {
+			return HierarchyResult{}, ErrInvalidULID
+		}
+		levels[i] = HierarchyLevel{Name: h.levels[i], ULID: u.String()}
+		if !h.r.unordered {
+			levels[i].Timestamp = ulid.Time(u.Time())
+		}
+	}
+
+	if len(signature) != signatureEncoding.EncodedLen(h.r.signatureLength) {
+		if validSignatureLength(len(signature)) {
+			return HierarchyResult{}, ErrSignatureLengthMismatch
+		}
+		return HierarchyResult{}, ErrIntegrityFailure
+	}
+	mac := h.macs.Get().(*macState)
+	defer putMAC(h.macs, mac)
+	if !equalSignature(signature, mac.signature(FormatV2, "", head, metadata, h.r.signatureLength)) {
+		return HierarchyResult{}, ErrIntegrityFailure
+	}
+
+	return HierarchyResult{Valid: true, Levels: levels, Metadata: metadata}, nil
+}
+
+// generate creates the ID of a new entity at the given level, below the ancestors rendered in
+// parents, which is empty or ends with an underscore.
+func (h *Hierarchy) generate(parents string, level int, metadata []string) (string, error) {
+	var metadataStr string
+	if len(metadata) > 0 {
+		metadataStr = metadata[0]
+	}
+	metadataStr, err := h.r.prepareMetadata(metadataStr)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := h.r.newULID(time.Now())
+	if err != nil {
+		return "", err
+	}
+	return h.render(parents+h.levels[level]+"_"+u.String(), metadataStr)
+}
+
+// render returns the signed ID of the levels rendered in head and metadata.
+func (h *Hierarchy) render(head, metadata string) (string, error) {
+	mac := h.macs.Get().(*macState)
+	defer putMAC(h.macs, mac)
+	signature := mac.signature(FormatV2, "", head, metadata, h.r.signatureLength)
+
+	var sb strings.Builder
+	sb.Grow(len(head) + 1 + len(signature) + 1 + len(metadata))
+	sb.WriteString(head)
+	sb.WriteByte('-')
+	sb.Write(signature)
+	if metadata != "" {
+		sb.WriteByte('-')
+		sb.WriteString(metadata)
+	}
+
+	if h.r.maxLength > 0 && sb.Len() > h.r.maxLength {
+		return "", ErrTooLong
+	}
+	return sb.String(), nil
+}
+
+// canonicalHead renders levels as they appear in an ID.
+func canonicalHead(levels []HierarchyLevel) string {
+	var sb strings.Builder
+	for i, l := range levels {
+		if i > 0 {
+			sb.WriteByte('_')
+		}
+		sb.WriteString(l.Name)
+		sb.WriteByte('_')
+		sb.WriteString(l.ULID)
+	}
+	return sb.String()
+}
@@ -0,0 +1,154 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHierarchy(t *testing.T) *Hierarchy {
+	t.Helper()
+
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	h, err := r.Hierarchy("org", "prj", "res")
+	require.NoError(t, err)
+	return h
+}
+
+func TestHierarchy(t *testing.T) {
+	h := newTestHierarchy(t)
+	assert.Equal(t, []string{"org", "prj", "res"}, h.Levels())
+
+	org, err := h.Generate("name:acme")
+	require.NoError(t, err)
+	assert.Regexp(t, `^org_[0-9A-Z]{26}-[A-Z2-7]+-name:acme$`, org)
+
+	prj, err := h.Child(org)
+	require.NoError(t, err)
+	res, err := h.Child(prj, "kind:bucket")
+	require.NoError(t, err)
+	assert.Regexp(t, `^org_[0-9A-Z]{26}_prj_[0-9A-Z]{26}_res_[0-9A-Z]{26}-[A-Z2-7]+-kind:bucket$`, res)
+
+	orgResult, err := h.Verify(org)
+	require.NoError(t, err)
+	result, err := h.Verify(res)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, "kind:bucket", result.Metadata)
+	require.Len(t, result.Levels, 3)
+
+	level, ok := result.Level("org")
+	require.True(t, ok)
+	assert.Equal(t, orgResult.ULID(), level.ULID)
+	assert.WithinDuration(t, time.Now(), level.Timestamp, time.Minute)
+	assert.Equal(t, result.Levels[2].ULID, result.ULID())
+	_, ok = orgResult.Level("prj")
+	assert.False(t, ok)
+
+	parent, err := h.Parent(res)
+	require.NoError(t, err)
+	assert.Equal(t, prj, parent)
+	root, err := h.Parent(parent)
+	require.NoError(t, err)
+	rootResult, err := h.Verify(root)
+	require.NoError(t, err)
+	assert.Equal(t, orgResult.Levels, rootResult.Levels)
+	assert.Empty(t, rootResult.Metadata, "parents are signed without metadata")
+
+	_, err = h.Child(res)
+	assert.ErrorIs(t, err, ErrInvalidHierarchy)
+	_, err = h.Parent(org)
+	assert.ErrorIs(t, err, ErrInvalidHierarchy)
+}
+
+func TestHierarchyTamper(t *testing.T) {
+	h := newTestHierarchy(t)
+	orgA, err := h.Generate()
+	require.NoError(t, err)
+	orgB, err := h.Generate()
+	require.NoError(t, err)
+	prj, err := h.Child(orgA)
+	require.NoError(t, err)
+
+	// Moving the project under another organization breaks the signature
+	moved := orgB[:30] + prj[30:]
+	_, err = h.Verify(moved)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	for _, bad := range []string{
+		"prj" + prj[3:],
+		"org_" + strings.Repeat("Z", 26) + prj[30:],
+		strings.Replace(prj, "_prj_", "_res_", 1),
+		strings.Replace(prj, "-", "_", 1),
+		"org_01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	} {
+		_, err := h.Verify(bad)
+		assert.Error(t, err, bad)
+	}
+
+	// Hierarchical IDs are not plain IDs, and plain IDs are not hierarchical ones
+	_, err = h.r.Verify(prj)
+	assert.Error(t, err)
+	prefixed, err := h.r.WithPrefix("org")
+	require.NoError(t, err)
+	plain, err := prefixed.Generate()
+	require.NoError(t, err)
+	_, err = h.Verify(plain)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	other, err := h.r.Hierarchy("org", "team")
+	require.NoError(t, err)
+	_, err = other.Verify(orgA)
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "keys are derived for the level names")
+}
+
+func TestHierarchyMetadata(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	h, err := r.Hierarchy("org", "prj")
+	require.NoError(t, err)
+	org, err := h.Generate()
+	require.NoError(t, err)
+
+	// Printable metadata is enforced on verification, not only on generation
+	control, err := h.render(org[:30], "a\x00b")
+	require.NoError(t, err)
+	_, err = h.Verify(control)
+	require.NoError(t, err)
+	strict, err := r.WithPrintableMetadata().Hierarchy("org", "prj")
+	require.NoError(t, err)
+	_, err = strict.Verify(control)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	// Metadata in another normalization form verifies as its NFC form
+	normalized, err := r.WithNormalizedMetadata().Hierarchy("org", "prj")
+	require.NoError(t, err)
+	composed, err := normalized.Child(org, "caf\u00e9")
+	require.NoError(t, err)
+	decomposed := strings.Replace(composed, "caf\u00e9", "cafe\u0301", 1)
+	res, err := normalized.Verify(decomposed)
+	require.NoError(t, err)
+	assert.Equal(t, "caf\u00e9", res.Metadata)
+	_, err = h.Verify(decomposed)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+}
+
+func TestHierarchyInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, levels := range [][]string{
+		nil,
+		{"org", ""},
+		{"org", "org"},
+		{"org", "a_b"},
+		{"a", "b", "c", "d", "e", "f", "g", "h", "i"},
+	} {
+		_, err := r.Hierarchy(levels...)
+		assert.ErrorIs(t, err, ErrInvalidHierarchy, "%v", levels)
+	}
+}
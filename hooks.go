@@ -0,0 +1,133 @@
+package rigid
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are functions called after every generation and verification, for building metrics,
+// auditing and rate limiting on top of rigid. Hooks are called synchronously on the calling
+// goroutine and must be safe for concurrent use. Nil hooks are skipped.
+type Hooks struct {
+	// OnGenerate is called after every Generate, GenerateAt and AppendGenerate call.
+	OnGenerate func(GenerateEvent)
+	// OnVerify is called after every verification, including those made by VerifyInto and
+	// the batch verification methods.
+	OnVerify func(VerifyEvent)
+}
+
+// GenerateEvent describes an ID generation.
+type GenerateEvent struct {
+	// ID is the generated ID, or empty if generation failed.
+	ID string
+	// KeyID is the key ID of the generating instance, see Rigid.KeyID.
+	KeyID string
+	// Prefix is the type prefix of the generating instance.
+	Prefix string
+	// Duration is the time generation took.
+	Duration time.Duration
+	// Err is the error generation failed with, or nil.
+	Err error
+}
+
+// VerifyEvent describes a verification.
+type VerifyEvent struct {
+	// ID is the verified ID as presented.
+	ID string
+	// KeyID is the key ID of the verifying instance, see Rigid.KeyID.
+	KeyID string
+	// Result is the verification result, which is zero if verification failed.
+	Result VerifyResult
+	// Duration is the time verification took, including revocation and replay checks.
+	Duration time.Duration
+	// Err is the error verification failed with, or nil.
+	Err error
+	// Remote is the remote party stored in the context by ContextWithRemote, or nil.
+	Remote any
+}
+
+// hooks are the hooks of an instance along with its key ID, computed once.
+type hooks struct {
+	Hooks
+	keyID string
+}
+
+// WithHooks returns a copy of r calling the functions of h after every generation and
+// verification, replacing any hooks set before. The returned instance shares the secret key
+// and entropy source with r.
+func (r *Rigid) WithHooks(h Hooks) *Rigid {
+	c := r.clone()
+	c.hooks = nil
+	if h.OnGenerate != nil || h.OnVerify != nil {
+		c.hooks = &hooks{Hooks: h, keyID: r.KeyID()}
+	}
+	return c
+}
+
+// generateObserved reports whether generated IDs are passed to the hooks, audit sink or issue
+// recorder of r, so callers can skip preparing the ID for observeGenerate.
+func (r *Rigid) generateObserved() bool {
+	return r.audit != nil || r.issues != nil || (r.hooks != nil && r.hooks.OnGenerate != nil)
+}
+
+// observeGenerate records a generation started at start to r's issue recorder and audit sink,
+// counts it and reports it to r's hooks. It returns err, or the error recording the issuance of
+// id failed with. id is only read by the issue recorder, audit sink and hooks.
+func (r *Rigid) observeGenerate(start time.Time, id string, err error) error {
+	if err == nil && r.issues != nil {
+		if err = r.issues.issued(id); err != nil {
+			id = ""
+		}
+	}
+	if err == nil && r.audit != nil {
+		if err = r.audit.issued(id); err != nil {
+			id = ""
+		}
+	}
+
+	d := time.Since(start)
+	r.stats.generate(d, err)
+	if r.hooks != nil && r.hooks.OnGenerate != nil {
+		r.hooks.OnGenerate(GenerateEvent{
+			ID:       id,
+			KeyID:    r.hooks.keyID,
+			Prefix:   r.prefix,
+			Duration: d,
+			Err:      err,
+		})
+	}
+	return err
+}
+
+// verifyObserved reports whether verifications of r are passed to the failure hook, hooks or
+// audit sink, so callers can skip preparing the ID for observeVerify.
+func (r *Rigid) verifyObserved() bool {
+	return r.onFailure != nil || r.audit != nil || (r.hooks != nil && r.hooks.OnVerify != nil)
+}
+
+// observeVerify counts a verification started at start and reports it to r's failure hook,
+// audit sink and hooks. id is only read by those.
+func (r *Rigid) observeVerify(ctx context.Context, start time.Time, id string, result VerifyResult, err error) {
+	d := time.Since(start)
+	r.stats.verify(d, err)
+	if err != nil && r.audit != nil {
+		r.audit.rejected(ctx, r, id, err)
+	}
+	if err != nil && r.bursts != nil {
+		r.bursts.failed(ctx, err)
+	}
+	if err != nil && r.onFailure != nil {
+		r.onFailure(id, err, RemoteFromContext(ctx))
+	}
+	if r.hooks == nil || r.hooks.OnVerify == nil {
+		return
+	}
+	r.hooks.OnVerify(VerifyEvent{
+		ID:       id,
+		KeyID:    r.hooks.keyID,
+		Result:   result,
+		Duration: d,
+		Err:      err,
+		Remote:   RemoteFromContext(ctx),
+	})
+}
@@ -0,0 +1,97 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHooksGenerate(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := base.WithPrefix("ord")
+	require.NoError(t, err)
+
+	var events []GenerateEvent
+	r := typed.WithHooks(Hooks{OnGenerate: func(e GenerateEvent) { events = append(events, e) }})
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	buf, err := r.AppendGenerate([]byte("prefix:"))
+	require.NoError(t, err)
+	_, err = r.WithMaxLength(40).Generate("user:alice-with-long-metadata")
+	require.ErrorIs(t, err, ErrTooLong)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, id, events[0].ID)
+	assert.Equal(t, string(buf[len("prefix:"):]), events[1].ID)
+	assert.Empty(t, events[2].ID)
+	assert.ErrorIs(t, events[2].Err, ErrTooLong)
+	for _, e := range events {
+		assert.Equal(t, base.KeyID(), e.KeyID)
+		assert.Equal(t, "ord", e.Prefix)
+		assert.Positive(t, e.Duration)
+	}
+
+	// The instance hooks were set on is unaffected
+	_, err = typed.Generate()
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+}
+
+func TestWithHooksVerify(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var events []VerifyEvent
+	r := base.WithRevocationStore(newMapStore()).WithHooks(Hooks{OnVerify: func(e VerifyEvent) { events = append(events, e) }})
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	tampered := id[:27] + "AAAAAAAAAAAAA"
+	ctx := ContextWithRemote(context.Background(), "192.0.2.1:4711")
+
+	result, err := r.VerifyContext(ctx, id)
+	require.NoError(t, err)
+	_, err = r.VerifyBytes([]byte(tampered))
+	require.ErrorIs(t, err, ErrIntegrityFailure)
+	require.NoError(t, r.Revoke(ctx, id, 0))
+	_, err = r.Verify(id)
+	require.ErrorIs(t, err, ErrRevoked)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, VerifyEvent{ID: id, KeyID: base.KeyID(), Result: result, Duration: events[0].Duration, Remote: "192.0.2.1:4711"}, events[0])
+	assert.Equal(t, tampered, events[1].ID)
+	assert.ErrorIs(t, events[1].Err, ErrIntegrityFailure)
+	assert.Equal(t, VerifyResult{}, events[1].Result)
+	assert.Nil(t, events[1].Remote)
+	assert.ErrorIs(t, events[2].Err, ErrRevoked)
+	assert.Equal(t, VerifyResult{}, events[2].Result)
+	for _, e := range events {
+		assert.Positive(t, e.Duration)
+	}
+}
+
+func TestWithHooksKeepsFailureHook(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var failures, verified int
+	r = r.WithFailureHook(func(string, error, any) { failures++ }).
+		WithHooks(Hooks{OnVerify: func(VerifyEvent) { verified++ }})
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	_, _ = r.Verify(id)
+	_, _ = r.Verify(id[:27] + "AAAAAAAAAAAAA")
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, 2, verified)
+
+	// Setting empty hooks removes them
+	r = r.WithHooks(Hooks{})
+	_, _ = r.Verify(id)
+	assert.Equal(t, 2, verified)
+	assert.Nil(t, r.hooks)
+}
@@ -0,0 +1,200 @@
+package rigid
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ID is a rigid ID string that validates itself when decoded at system boundaries,
+// such as when scanned from a database column or unmarshaled from a JSON request body.
+// The empty ID represents an absent value and is never validated.
+//
+// By default decoding only checks the structure of the ID with ValidateFormat.
+// After SetDefaultVerifier is called, decoding fully verifies the signature instead.
+type ID string
+
+// Verifier verifies rigid IDs. *Rigid implements Verifier.
+type Verifier interface {
+	Verify(secureULID string, opts ...VerifyOption) (VerifyResult, error)
+}
+
+// verifierHolder wraps a Verifier so it can be stored in an atomic.Pointer.
+type verifierHolder struct {
+	v Verifier
+}
+
+var defaultVerifier atomic.Pointer[verifierHolder]
+
+// SetDefaultVerifier sets the Verifier used when decoding ID values.
+// Passing nil restores the default structural check.
+// It is safe to call concurrently with decoding, but is intended to be called once at startup.
+func SetDefaultVerifier(v Verifier) {
+	if v == nil {
+		defaultVerifier.Store(nil)
+		return
+	}
+	defaultVerifier.Store(&verifierHolder{v: v})
+}
+
+// String returns the ID as a string.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Validate checks id with the default verifier if one is set, or with ValidateFormat otherwise.
+func (id ID) Validate() error {
+	if h := defaultVerifier.Load(); h != nil {
+		_, err := h.v.Verify(string(id))
+		return err
+	}
+	return ValidateFormat(string(id))
+}
+
+// Scan implements sql.Scanner. NULL scans into the empty ID; any other value is validated.
+func (id *ID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = ""
+		return nil
+	case string:
+		return id.decode(v)
+	case []byte:
+		return id.decode(string(v))
+	default:
+		return fmt.Errorf("%w: cannot scan %T into rigid.ID", ErrInvalidFormat, src)
+	}
+}
+
+// MarshalJSON implements json.Marshaler, encoding the ID as a JSON string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. JSON null leaves the ID unchanged,
+// an empty string decodes into the empty ID, and any other string is validated.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+	return id.decode(s)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the ID unchanged.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text decodes into the empty ID;
+// anything else is validated.
+func (id *ID) UnmarshalText(text []byte) error {
+	return id.decode(string(text))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the compact binary format:
+//
+//	ULID (16 bytes) | signature length (1 byte) | signature | prefix length (1 byte) | prefix | metadata
+//
+// The ULID and signature are stored as raw bytes rather than base32 text, and the ULID comes first
+// so that encoded IDs sort by creation time. The empty ID encodes as an empty slice.
+// Only IDs in canonical form, as produced by Generate, can be encoded; others return ErrInvalidFormat
+// or ErrInvalidULID.
+func (id ID) MarshalBinary() ([]byte, error) {
+	if id == "" {
+		return []byte{}, nil
+	}
+
+	if err := ValidateFormat(string(id)); err != nil {
+		return nil, err
+	}
+
+	seg, _ := splitID(string(id))
+
+	ulidObj, err := ulid.ParseStrict(seg.ulid)
+	if err != nil || ulidObj.String() != seg.ulid {
+		return nil, ErrInvalidULID
+	}
+
+	sig, err := signatureEncoding.DecodeString(seg.signature)
+	if err != nil || signatureEncoding.EncodeToString(sig) != seg.signature || len(seg.prefix) > 255 {
+		return nil, ErrInvalidFormat
+	}
+
+	b := make([]byte, 0, len(ulidObj)+len(sig)+len(seg.prefix)+len(seg.metadata)+2)
+	b = append(b, ulidObj[:]...)
+	b = append(b, byte(len(sig)))
+	b = append(b, sig...)
+	b = append(b, byte(len(seg.prefix)))
+	b = append(b, seg.prefix...)
+	b = append(b, seg.metadata...)
+
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler for the compact binary format
+// produced by MarshalBinary. The decoded ID is validated like any other decoded value.
+func (id *ID) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		*id = ""
+		return nil
+	}
+
+	var ulidObj ulid.ULID
+	if len(data) < len(ulidObj)+1 {
+		return ErrInvalidFormat
+	}
+	copy(ulidObj[:], data)
+	data = data[len(ulidObj):]
+
+	sigLen := int(data[0])
+	if len(data) < 1+sigLen+1 {
+		return ErrInvalidFormat
+	}
+	sig := data[1 : 1+sigLen]
+	data = data[1+sigLen:]
+
+	prefixLen := int(data[0])
+	if len(data) < 1+prefixLen {
+		return ErrInvalidFormat
+	}
+	prefix := string(data[1 : 1+prefixLen])
+	metadata := string(data[1+prefixLen:])
+
+	s := ulidObj.String() + "-" + signatureEncoding.EncodeToString(sig)
+	if prefix != "" {
+		s = prefix + "_" + s
+	}
+	if metadata != "" {
+		s += "-" + metadata
+	}
+
+	return id.decode(s)
+}
+
+// Value implements driver.Valuer. The empty ID is stored as NULL.
+func (id ID) Value() (driver.Value, error) {
+	if id == "" {
+		return nil, nil
+	}
+	return string(id), nil
+}
+
+// decode validates s and stores it in id. The empty string is stored without validation.
+func (id *ID) decode(s string) error {
+	if s != "" {
+		if err := ID(s).Validate(); err != nil {
+			return err
+		}
+	}
+
+	*id = ID(s)
+	return nil
+}
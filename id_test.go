@@ -0,0 +1,218 @@
+package rigid
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ sql.Scanner   = (*ID)(nil)
+	_ driver.Valuer = ID("")
+	_ Verifier      = (*Rigid)(nil)
+)
+
+func TestIDScan(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	var id ID
+	require.NoError(t, id.Scan(generated))
+	assert.Equal(t, generated, id.String())
+
+	id = ""
+	require.NoError(t, id.Scan([]byte(generated)))
+	assert.Equal(t, ID(generated), id)
+
+	require.NoError(t, id.Scan(nil))
+	assert.Empty(t, id)
+
+	assert.Equal(t, ErrInvalidFormat, id.Scan("not a rigid id"))
+	assert.ErrorIs(t, id.Scan(42), ErrInvalidFormat)
+	assert.Empty(t, id)
+}
+
+func TestIDScanWithDefaultVerifier(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	forged, err := other.Generate()
+	require.NoError(t, err)
+
+	// Structurally valid IDs pass without a verifier
+	var id ID
+	require.NoError(t, id.Scan(forged))
+
+	SetDefaultVerifier(r)
+	defer SetDefaultVerifier(nil)
+
+	assert.Equal(t, ErrIntegrityFailure, id.Scan(forged))
+
+	genuine, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, id.Scan(genuine))
+	assert.Equal(t, ID(genuine), id)
+}
+
+func TestIDValue(t *testing.T) {
+	v, err := ID("").Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	v, err = ID("01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA", v)
+}
+
+func TestIDJSON(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	type request struct {
+		OrderID  ID   `json:"order_id"`
+		ParentID ID   `json:"parent_id,omitempty"`
+		Refs     []ID `json:"refs,omitempty"`
+	}
+
+	data, err := json.Marshal(request{OrderID: ID(generated)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order_id":"`+generated+`"}`, string(data))
+
+	var req request
+	require.NoError(t, json.Unmarshal(data, &req))
+	assert.Equal(t, ID(generated), req.OrderID)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"order_id":"","parent_id":null}`), &req))
+	assert.Empty(t, req.OrderID)
+
+	err = json.Unmarshal([]byte(`{"order_id":"forged-id"}`), &req)
+	assert.ErrorIs(t, err, ErrInvalidULID)
+
+	err = json.Unmarshal([]byte(`{"refs":[42]}`), &req)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestIDJSONWithDefaultVerifier(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	SetDefaultVerifier(r)
+	defer SetDefaultVerifier(nil)
+
+	forged, err := other.Generate()
+	require.NoError(t, err)
+
+	var id ID
+	err = json.Unmarshal([]byte(`"`+forged+`"`), &id)
+	assert.Equal(t, ErrIntegrityFailure, err)
+
+	genuine, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(`"`+genuine+`"`), &id))
+	assert.Equal(t, ID(genuine), id)
+}
+
+func TestIDText(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	a, err := r.Generate()
+	require.NoError(t, err)
+	b, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	// IDs work as JSON map keys through TextMarshaler
+	counts := map[ID]int{ID(a): 1, ID(b): 2}
+	data, err := json.Marshal(counts)
+	require.NoError(t, err)
+
+	var decoded map[ID]int
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, counts, decoded)
+
+	err = json.Unmarshal([]byte(`{"bogus":1}`), &decoded)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	// And as flag values
+	var id ID
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.TextVar(&id, "id", ID(""), "rigid ID")
+	require.NoError(t, fs.Parse([]string{"-id", b}))
+	assert.Equal(t, ID(b), id)
+}
+
+func TestIDBinary(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	prefixed, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	long, err := r.WithSignatureLength(MaxSignatureLength)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, g := range []*Rigid{r, prefixed, long} {
+		for _, metadata := range []string{"", "user:alice-smith"} {
+			generated, err := g.Generate(metadata)
+			require.NoError(t, err)
+			ids = append(ids, generated)
+		}
+	}
+
+	for _, generated := range ids {
+		data, err := ID(generated).MarshalBinary()
+		require.NoError(t, err, generated)
+		assert.Less(t, len(data), len(generated), generated)
+
+		var id ID
+		require.NoError(t, id.UnmarshalBinary(data), generated)
+		assert.Equal(t, ID(generated), id)
+	}
+
+	data, err := ID("").MarshalBinary()
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	var id ID
+	require.NoError(t, id.UnmarshalBinary(nil))
+	assert.Empty(t, id)
+}
+
+func TestIDBinaryInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate()
+	require.NoError(t, err)
+
+	_, err = ID("garbage").MarshalBinary()
+	assert.Equal(t, ErrInvalidFormat, err)
+
+	// Non-canonical ULIDs cannot survive the round trip
+	_, err = ID(strings.ToLower(generated[:26]) + generated[26:]).MarshalBinary()
+	assert.Equal(t, ErrInvalidULID, err)
+
+	data, err := ID(generated).MarshalBinary()
+	require.NoError(t, err)
+
+	var id ID
+	for _, truncated := range [][]byte{data[:10], data[:17], data[:len(data)-1]} {
+		assert.Error(t, id.UnmarshalBinary(truncated))
+	}
+	assert.Empty(t, id)
+}
@@ -0,0 +1,178 @@
+// Package benchmarks defines the benchmark suite shared by the rigid package tests and the
+// rigid bench command, so results from go test and from released binaries are comparable.
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// Key is the secret key used by the benchmarks.
+var Key = []byte("benchmark-secret-key-for-rigid-ids")
+
+// BatchSize is the number of IDs verified per VerifyBatchParallel operation.
+const BatchSize = 1000
+
+// Benchmark is a named benchmark of the suite.
+type Benchmark struct {
+	Name string
+	F    func(b *testing.B)
+}
+
+// Metadata values of increasing size, the longest close to rigid.DefaultMaxLength.
+var (
+	shortMetadata = "user:alice"
+	longMetadata  = strings.Repeat("m", 960)
+)
+
+// All returns the benchmark suite: generation and verification at every signature length,
+// with short and long metadata, concurrent generation, and the byte-slice and batch APIs.
+// Every benchmark reports allocations.
+func All() []Benchmark {
+	var all []Benchmark
+	for _, sigLen := range []int{rigid.MinSignatureLength, rigid.DefaultSignatureLength, 16, rigid.MaxSignatureLength} {
+		all = append(all,
+			Benchmark{fmt.Sprintf("Generate/sig=%d", sigLen), generate(sigLen, "")},
+			Benchmark{fmt.Sprintf("Verify/sig=%d", sigLen), verify(sigLen, "")},
+		)
+	}
+	return append(all,
+		Benchmark{"Generate/metadata=short", generate(rigid.DefaultSignatureLength, shortMetadata)},
+		Benchmark{"Generate/metadata=long", generate(rigid.DefaultSignatureLength, longMetadata)},
+		Benchmark{"Verify/metadata=short", verify(rigid.DefaultSignatureLength, shortMetadata)},
+		Benchmark{"Verify/metadata=long", verify(rigid.DefaultSignatureLength, longMetadata)},
+		Benchmark{"GenerateParallel/shards=1", generateParallel(1)},
+		Benchmark{"GenerateParallel/shards=cpu", generateParallel(0)},
+		Benchmark{"AppendGenerate", appendGenerate},
+		Benchmark{"VerifyBytes", verifyBytes},
+		Benchmark{"VerifyParallel", verifyParallel},
+		Benchmark{"VerifyBatchParallel", verifyBatchParallel},
+	)
+}
+
+func newRigid(b *testing.B, sigLen int) *rigid.Rigid {
+	b.Helper()
+
+	r, err := rigid.NewRigid(Key, sigLen)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return r
+}
+
+func newID(b *testing.B, r *rigid.Rigid, metadata string) string {
+	b.Helper()
+
+	id, err := r.Generate(metadata)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return id
+}
+
+func generate(sigLen int, metadata string) func(b *testing.B) {
+	return func(b *testing.B) {
+		r := newRigid(b, sigLen)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			if _, err := r.Generate(metadata); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func verify(sigLen int, metadata string) func(b *testing.B) {
+	return func(b *testing.B) {
+		r := newRigid(b, sigLen)
+		id := newID(b, r, metadata)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			if _, err := r.Verify(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// generateParallel generates from all goroutines of b.RunParallel, using the given number of
+// entropy shards, where zero means one per CPU.
+func generateParallel(shards int) func(b *testing.B) {
+	return func(b *testing.B) {
+		r := newRigid(b, rigid.DefaultSignatureLength).WithEntropyShards(shards)
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := r.Generate(); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	}
+}
+
+func appendGenerate(b *testing.B) {
+	r := newRigid(b, rigid.DefaultSignatureLength)
+	buf := make([]byte, 0, rigid.EncodedLen(0, rigid.DefaultSignatureLength, 0))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		var err error
+		if buf, err = r.AppendGenerate(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func verifyBytes(b *testing.B) {
+	r := newRigid(b, rigid.DefaultSignatureLength)
+	id := []byte(newID(b, r, ""))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := r.VerifyBytes(id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func verifyParallel(b *testing.B) {
+	r := newRigid(b, rigid.DefaultSignatureLength)
+	id := newID(b, r, "")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := r.Verify(id); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// verifyBatchParallel verifies BatchSize IDs per operation and also reports the time per ID.
+func verifyBatchParallel(b *testing.B) {
+	r := newRigid(b, rigid.DefaultSignatureLength)
+	ids := make([]string, BatchSize)
+	for i := range ids {
+		ids[i] = newID(b, r, "")
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, stats, err := r.VerifyBatchParallel(ctx, ids, 0); err != nil || stats.Valid != BatchSize {
+			b.Fatalf("verified %d of %d: %v", stats.Valid, BatchSize, err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*BatchSize), "ns/id")
+}
@@ -0,0 +1,160 @@
+package rigid
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// IssueRecord records the issuance of an ID, for registries enumerating every ID ever issued.
+// Records never contain signatures, so a registry cannot be used to reconstruct IDs, and carry
+// metadata only as a keyed hash.
+type IssueRecord struct {
+	// ULID is the ULID of the ID.
+	ULID string `json:"ulid"`
+	// Prefix is the type prefix of the ID, or empty.
+	Prefix string `json:"prefix,omitempty"`
+	// MetadataHash is the hash of the metadata of the ID as MetadataHash computes it, or empty
+	// if the ID has no metadata.
+	MetadataHash string `json:"metadata_hash,omitempty"`
+	// KeyID is the key ID of the issuing instance, see Rigid.KeyID.
+	KeyID string `json:"key_id"`
+	// Time is the time the ID was issued.
+	Time time.Time `json:"time"`
+}
+
+// IssueRecorder records every ID an instance issues. Implementations must be safe for
+// concurrent use.
+type IssueRecorder interface {
+	// RecordIssue records rec. An error fails the generation of the ID, so no ID is handed out
+	// without a record.
+	RecordIssue(rec IssueRecord) error
+}
+
+// issueRecorder is the issue recorder of an instance along with its key ID and metadata hash
+// key, computed once.
+type issueRecorder struct {
+	rec     IssueRecorder
+	keyID   string
+	hashKey []byte
+}
+
+// WithIssueRecorder returns a copy of r recording every generated ID to rec, or recording nothing
+// if rec is nil. Generation fails if the issuance cannot be recorded.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithIssueRecorder(rec IssueRecorder) *Rigid {
+	c := r.clone()
+	c.issues = nil
+	if rec != nil {
+		c.issues = &issueRecorder{rec: rec, keyID: r.KeyID(), hashKey: r.metadataHashKey()}
+	}
+	return c
+}
+
+// MetadataHash returns the hash of metadata recorded in IssueRecord.MetadataHash, as lowercase
+// hex. The hash is an HMAC-SHA256 keyed with a key derived from the secret key, so registries
+// do not disclose guessable metadata such as user IDs, while holders of the key can find the
+// IDs issued for a given metadata value.
+func (r *Rigid) MetadataHash(metadata string) string {
+	return metadataHash(r.metadataHashKey(), metadata)
+}
+
+func (r *Rigid) metadataHashKey() []byte {
+	return hkdf(r.secretKey, formatDomain+"metadata hash")
+}
+
+func metadataHash(key []byte, metadata string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(metadata))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// issued records the issuance of id.
+func (i *issueRecorder) issued(id string) error {
+	seg, _ := splitID(id)
+	rec := IssueRecord{
+		ULID:   seg.ulid,
+		Prefix: seg.prefix,
+		KeyID:  i.keyID,
+		Time:   time.Now(),
+	}
+	if seg.metadata != "" {
+		rec.MetadataHash = metadataHash(i.hashKey, seg.metadata)
+	}
+	if err := i.rec.RecordIssue(rec); err != nil {
+		return fmt.Errorf("record issuance: %w", err)
+	}
+	return nil
+}
+
+// JSONIssueRecorder is an IssueRecorder writing each record as a line of JSON.
+type JSONIssueRecorder struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONIssueRecorder returns an IssueRecorder writing records to w as JSON lines. Each record
+// is written with a single Write call.
+func NewJSONIssueRecorder(w io.Writer) *JSONIssueRecorder {
+	return &JSONIssueRecorder{w: w}
+}
+
+// OpenIssueFile opens the file at path for appending, creating it with mode 0600 if needed, and
+// returns a JSONIssueRecorder writing to it. Close the recorder to close the file.
+func OpenIssueFile(path string) (*JSONIssueRecorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONIssueRecorder(f), nil
+}
+
+// RecordIssue implements IssueRecorder.
+func (s *JSONIssueRecorder) RecordIssue(rec IssueRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(b)
+	return err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (s *JSONIssueRecorder) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReadIssueRecords calls fn for each record of a file written by a JSONIssueRecorder, in the
+// order they were written, stopping at the first error fn returns. Blank lines are skipped.
+func ReadIssueRecords(r io.Reader, fn func(IssueRecord) error) error {
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec IssueRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,148 @@
+package rigid
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readIssued decodes the records written to b.
+func readIssued(t *testing.T, b []byte) []IssueRecord {
+	t.Helper()
+
+	var records []IssueRecord
+	require.NoError(t, ReadIssueRecords(bytes.NewReader(b), func(rec IssueRecord) error {
+		records = append(records, rec)
+		return nil
+	}))
+	return records
+}
+
+func TestWithIssueRecorder(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := base.WithPrefix("ord")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	r := typed.WithIssueRecorder(NewJSONIssueRecorder(&buf))
+	assert.True(t, r.Config().IssueRecorder)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	appended, err := r.AppendGenerate(nil)
+	require.NoError(t, err)
+	_, err = r.Verify(id)
+	require.NoError(t, err)
+
+	records := readIssued(t, buf.Bytes())
+	require.Len(t, records, 2, "verifications are not recorded")
+	for _, rec := range records {
+		assert.WithinDuration(t, time.Now(), rec.Time, time.Minute)
+		assert.Equal(t, base.KeyID(), rec.KeyID)
+		assert.Equal(t, "ord", rec.Prefix)
+	}
+	seg, _ := splitID(id)
+	assert.Equal(t, seg.ulid, records[0].ULID)
+	assert.Equal(t, r.MetadataHash("user:alice"), records[0].MetadataHash)
+	appendedSeg, _ := splitID(string(appended))
+	assert.Equal(t, appendedSeg.ulid, records[1].ULID)
+	assert.Empty(t, records[1].MetadataHash)
+
+	// Signatures and metadata never reach the registry
+	assert.NotContains(t, buf.String(), seg.signature)
+	assert.NotContains(t, buf.String(), "alice")
+}
+
+func TestMetadataHash(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	h := r.MetadataHash("user:alice")
+	assert.Len(t, h, 64)
+	assert.Equal(t, strings.ToLower(h), h)
+	assert.Equal(t, h, r.MetadataHash("user:alice"))
+	assert.NotEqual(t, h, r.MetadataHash("user:bob"))
+	assert.NotEqual(t, h, other.MetadataHash("user:alice"), "hashes are keyed")
+}
+
+// failingRecorder is an IssueRecorder whose writes fail.
+type failingRecorder struct{}
+
+func (failingRecorder) RecordIssue(IssueRecord) error { return errors.New("disk full") }
+
+func TestWithIssueRecorderFailure(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithIssueRecorder(failingRecorder{})
+
+	// No ID is issued without a record
+	id, err := r.Generate()
+	assert.ErrorContains(t, err, "disk full")
+	assert.Empty(t, id)
+	dst := []byte("ids:")
+	b, err := r.AppendGenerate(dst)
+	assert.Error(t, err)
+	assert.Equal(t, "ids:", string(b))
+
+	_, err = r.WithIssueRecorder(nil).Generate()
+	assert.NoError(t, err)
+}
+
+func TestOpenIssueFile(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "issued.jsonl")
+	var ids []string
+	for range 2 {
+		rec, err := OpenIssueFile(path)
+		require.NoError(t, err)
+		id, err := r.WithIssueRecorder(rec).Generate()
+		require.NoError(t, err)
+		ids = append(ids, id)
+		require.NoError(t, rec.Close())
+	}
+
+	// The file is appended to
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records := readIssued(t, b)
+	require.Len(t, records, 2)
+	for i, id := range ids {
+		seg, _ := splitID(id)
+		assert.Equal(t, seg.ulid, records[i].ULID)
+	}
+
+	_, err = OpenIssueFile(filepath.Join(t.TempDir(), "missing", "issued.jsonl"))
+	assert.Error(t, err)
+}
+
+func TestReadIssueRecords(t *testing.T) {
+	input := `{"ulid":"a","key_id":"k","time":"2025-01-01T00:00:00Z"}
+
+{"ulid":"b","key_id":"k","time":"2025-01-01T00:00:00Z"}
+`
+	var ulids []string
+	require.NoError(t, ReadIssueRecords(strings.NewReader(input), func(rec IssueRecord) error {
+		ulids = append(ulids, rec.ULID)
+		return nil
+	}))
+	assert.Equal(t, []string{"a", "b"}, ulids)
+
+	stop := errors.New("stop")
+	err := ReadIssueRecords(strings.NewReader(input), func(IssueRecord) error { return stop })
+	assert.ErrorIs(t, err, stop)
+
+	err = ReadIssueRecords(strings.NewReader("{}\nnot json\n"), func(IssueRecord) error { return nil })
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,177 @@
+package rigid
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidIssuer indicates an issuer contains characters other than ASCII letters and digits.
+	ErrInvalidIssuer = errors.New("issuer must contain only ASCII letters and digits")
+	// ErrUntrustedIssuer indicates an ID was issued by an issuer the verifier does not trust.
+	ErrUntrustedIssuer = errors.New("untrusted issuer")
+)
+
+// TrustPolicy maps the issuers whose IDs an instance accepts to their secret keys, for federated
+// setups where several services mint IDs that others selectively accept, see WithTrustPolicy.
+// Issuers can be trusted and distrusted while the policy is in use. TrustPolicy is safe for
+// concurrent use.
+type TrustPolicy struct {
+	mu      sync.RWMutex
+	issuers map[string]*trustedIssuer
+}
+
+// trustedIssuer holds the MAC states keyed for the IDs of a trusted issuer.
+type trustedIssuer struct {
+	key   []byte
+	keyID string
+	macs  *sync.Pool
+	// tagMACs holds the FormatV3 pools of the issuer by signature length
+	tagMACs sync.Map
+}
+
+// NewTrustPolicy returns a TrustPolicy trusting no issuer.
+func NewTrustPolicy() *TrustPolicy {
+	return &TrustPolicy{issuers: make(map[string]*trustedIssuer)}
+}
+
+// Trust accepts IDs of issuer signed with k, replacing any key trusted for issuer before.
+// Returns ErrInvalidIssuer if issuer is empty or not made of ASCII letters and digits, and
+// ErrEmptySecretKey if k is empty.
+func (p *TrustPolicy) Trust(issuer string, k Key) error {
+	if issuer == "" || !validPrefix(issuer) {
+		return ErrInvalidIssuer
+	}
+	if len(k.b) == 0 {
+		return ErrEmptySecretKey
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.issuers[issuer] = &trustedIssuer{key: k.b, keyID: keyID(k.b), macs: newMACPool(k.b)}
+	return nil
+}
+
+// Distrust stops accepting IDs of issuer.
+func (p *TrustPolicy) Distrust(issuer string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.issuers, issuer)
+}
+
+// Trusted reports whether p accepts IDs of issuer.
+func (p *TrustPolicy) Trusted(issuer string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.issuers[issuer]
+	return ok
+}
+
+// keyID returns the key ID of the key trusted for issuer, or the empty string if p does not
+// trust issuer. p may be nil.
+func (p *TrustPolicy) keyID(issuer string) string {
+	if p == nil {
+		return ""
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if t, ok := p.issuers[issuer]; ok {
+		return t.keyID
+	}
+	return ""
+}
+
+// macPool returns the pool of MAC states keyed for signatures of issuer in format version v with
+// signatures of sigLen bytes, or nil if p does not trust issuer. p may be nil.
+func (p *TrustPolicy) macPool(issuer string, v FormatVersion, sigLen int) *sync.Pool {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	t, ok := p.issuers[issuer]
+	p.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if v != FormatV3 {
+		return t.macs
+	}
+	if pool, ok := t.tagMACs.Load(sigLen); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := t.tagMACs.LoadOrStore(sigLen, newMACPool(tagKey(t.key, sigLen)))
+	return pool.(*sync.Pool)
+}
+
+// WithIssuer returns a copy of r whose IDs carry the signed issuer segment issuer, rendered in
+// front of the type prefix as ISSUER.PREFIX_ULID-SIGNATURE, or ISSUER._ULID-SIGNATURE without a
+// type prefix. Verification accepts IDs of r's own issuer, and IDs of other issuers only if they
+// are trusted by r's trust policy. An empty issuer removes it.
+// The returned instance shares the secret key and entropy source with r.
+// Returns ErrInvalidIssuer if issuer contains characters other than ASCII letters and digits.
+func (r *Rigid) WithIssuer(issuer string) (*Rigid, error) {
+	if !validPrefix(issuer) {
+		return nil, ErrInvalidIssuer
+	}
+
+	c := r.clone()
+	c.issuer = issuer
+	c.prefix = qualifyPrefix(issuer, unqualifyPrefix(r.issuer, r.prefix))
+	return c, nil
+}
+
+// WithTrustPolicy returns a copy of r that also accepts IDs of the issuers trusted by p, verified
+// with their keys but otherwise under r's settings: the type prefix, signature length and format
+// version must match r's, and TTLs, stores and hooks apply as for r's own IDs. IDs of other
+// issuers are rejected with ErrUntrustedIssuer. VerifyResult.Issuer reports the issuer of an ID.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithTrustPolicy(p *TrustPolicy) *Rigid {
+	c := r.clone()
+	c.trust = p
+	return c
+}
+
+// Issuer returns the issuer of the IDs generated by r, or "" if they carry none.
+func (r *Rigid) Issuer() string {
+	return r.issuer
+}
+
+// checkIssuer checks that the prefix segment of an ID other than r's own carries r's type prefix
+// and an issuer trusted by r.
+func (r *Rigid) checkIssuer(prefix string) error {
+	issuer := issuerOf(prefix)
+	if issuer == "" || unqualifyPrefix(issuer, prefix) != unqualifyPrefix(r.issuer, r.prefix) {
+		return ErrInvalidFormat
+	}
+	if r.trust == nil || !r.trust.Trusted(issuer) {
+		return ErrUntrustedIssuer
+	}
+	return nil
+}
+
+// qualifyPrefix returns the prefix segment of IDs of issuer with type prefix p.
+func qualifyPrefix(issuer, p string) string {
+	if issuer == "" {
+		return p
+	}
+	return issuer + "." + p
+}
+
+// unqualifyPrefix returns the type prefix of the prefix segment p of an ID of issuer.
+func unqualifyPrefix(issuer, p string) string {
+	if issuer == "" {
+		return p
+	}
+	return p[len(issuer)+1:]
+}
+
+// issuerOf returns the issuer of the prefix segment p, or "" if it carries none.
+func issuerOf(p string) string {
+	issuer, _, _ := strings.Cut(p, ".")
+	if len(issuer) == len(p) {
+		return ""
+	}
+	return issuer
+}
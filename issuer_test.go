@@ -0,0 +1,163 @@
+package rigid
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIssuer(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	acme, err := r.WithIssuer("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", acme.Issuer())
+
+	id, err := acme.Generate("user:alice")
+	require.NoError(t, err)
+	assert.Regexp(t, "^acme\\._[0-9A-Z]{26}-", id)
+	result, err := acme.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", result.Issuer)
+
+	typed, err := acme.WithPrefix("usr")
+	require.NoError(t, err)
+	typedID, err := typed.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, "^acme\\.usr_", typedID)
+	assert.NoError(t, ValidateFormat(typedID))
+	parts, err := Parse(typedID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", parts.Issuer)
+	assert.Equal(t, "usr", parts.Prefix)
+	assert.Equal(t, "usr", typed.Config().Prefix)
+
+	// The issuer is signed, so it cannot be swapped or stripped
+	_, err = typed.Verify("globex" + typedID[4:])
+	assert.ErrorIs(t, err, ErrUntrustedIssuer)
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrUntrustedIssuer)
+	plain, err := r.Generate()
+	require.NoError(t, err)
+	_, err = acme.Verify(plain)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	noIssuer, err := typed.WithIssuer("")
+	require.NoError(t, err)
+	id, err = noIssuer.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, "^usr_", id)
+
+	_, err = r.WithIssuer("ac.me")
+	assert.ErrorIs(t, err, ErrInvalidIssuer)
+}
+
+func TestTrustPolicy(t *testing.T) {
+	acmeKey, err := GenerateKey()
+	require.NoError(t, err)
+	globexKey, err := GenerateKey()
+	require.NoError(t, err)
+	localKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	issuer := func(k Key, name string) *Rigid {
+		r, err := NewRigidFromKey(k, 16)
+		require.NoError(t, err)
+		r, err = r.WithPrefix("ord")
+		require.NoError(t, err)
+		r, err = r.WithIssuer(name)
+		require.NoError(t, err)
+		return r
+	}
+	acme := issuer(acmeKey, "acme")
+	globex := issuer(globexKey, "globex")
+
+	policy := NewTrustPolicy()
+	require.NoError(t, policy.Trust("acme", acmeKey))
+	assert.True(t, policy.Trusted("acme"))
+	assert.False(t, policy.Trusted("globex"))
+	local := issuer(localKey, "local").WithTrustPolicy(policy)
+	assert.True(t, local.Config().TrustPolicy)
+
+	acmeID, err := acme.Generate("user:alice")
+	require.NoError(t, err)
+	globexID, err := globex.Generate()
+	require.NoError(t, err)
+	localID, err := local.Generate()
+	require.NoError(t, err)
+
+	result, err := local.Verify(acmeID)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", result.Issuer)
+	assert.Equal(t, "user:alice", result.Metadata)
+	assert.Equal(t, acmeKey.ID(), result.KeyID, "IDs of trusted issuers are verified with their keys")
+	result, err = local.VerifyBytes([]byte(acmeID))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", result.Issuer)
+	assert.True(t, local.Explain(acmeID).Valid())
+
+	result, err = local.Verify(localID)
+	require.NoError(t, err)
+	assert.Equal(t, "local", result.Issuer)
+	assert.Equal(t, localKey.ID(), result.KeyID)
+
+	_, err = local.Verify(globexID)
+	assert.ErrorIs(t, err, ErrUntrustedIssuer)
+	assert.Equal(t, CheckPrefix, local.Explain(globexID).FailedCheck)
+
+	// A trusted issuer cannot sign for another
+	forged, err := globex.WithIssuer("acme")
+	require.NoError(t, err)
+	forgedID, err := forged.Generate()
+	require.NoError(t, err)
+	_, err = local.Verify(forgedID)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// Type prefix and settings must match the verifier's
+	untyped, err := acme.WithPrefix("")
+	require.NoError(t, err)
+	untypedID, err := untyped.Generate()
+	require.NoError(t, err)
+	_, err = local.Verify(untypedID)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	for _, v := range []FormatVersion{FormatV2, FormatV3} {
+		av, err := acme.WithFormatVersion(v)
+		require.NoError(t, err)
+		lv, err := local.WithFormatVersion(v)
+		require.NoError(t, err)
+		id, err := av.Generate()
+		require.NoError(t, err)
+		_, err = lv.Verify(id)
+		assert.NoError(t, err, "version %d", v)
+	}
+
+	// Rotating the key of an issuer reports the new key for its new IDs
+	rotatedKey, err := GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, policy.Trust("acme", rotatedKey))
+	rotatedID, err := issuer(rotatedKey, "acme").Generate()
+	require.NoError(t, err)
+	result, err = local.Verify(rotatedID)
+	require.NoError(t, err)
+	assert.Equal(t, rotatedKey.ID(), result.KeyID)
+	_, err = local.Verify(acmeID)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	require.NoError(t, policy.Trust("acme", acmeKey))
+
+	policy.Distrust("acme")
+	_, err = local.Verify(acmeID)
+	assert.ErrorIs(t, err, ErrUntrustedIssuer)
+
+	assert.ErrorIs(t, policy.Trust("", acmeKey), ErrInvalidIssuer)
+	assert.ErrorIs(t, policy.Trust("acme", Key{}), ErrEmptySecretKey)
+
+	data, err := json.Marshal(VerifyResult{Valid: true, Issuer: "acme"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"valid":true,"ulid":"","metadata":"","issuer":"acme"}`, string(data))
+	var decoded VerifyResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "acme", decoded.Issuer)
+}
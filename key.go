@@ -0,0 +1,103 @@
+package rigid
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// MinKeyLength is the minimum length in bytes of keys created with NewKey.
+// Shorter keys offer less than 128 bits of security.
+const MinKeyLength = 16
+
+// ErrKeyTooShort indicates a key is shorter than MinKeyLength.
+var ErrKeyTooShort = errors.New("secret key must be at least 16 bytes")
+
+// errMarshalKey is returned when a Key is marshaled, so keys cannot leak through serialized configuration.
+var errMarshalKey = errors.New("rigid: refusing to marshal secret key")
+
+// redactedKey replaces the key wherever a Key is printed or logged.
+const redactedKey = "rigid.Key(redacted)"
+
+// Key is a secret key that is hard to leak by accident. It prints as a placeholder with every fmt
+// verb and in slog output, refuses to marshal to JSON or text, and compares in constant time.
+// The zero Key is empty and cannot be used to create a Rigid instance.
+type Key struct {
+	b []byte
+}
+
+// NewKey returns a Key holding a copy of secret.
+// Returns ErrEmptySecretKey if secret is empty, or ErrKeyTooShort if it is shorter than MinKeyLength.
+func NewKey(secret []byte) (Key, error) {
+	if len(secret) == 0 {
+		return Key{}, ErrEmptySecretKey
+	}
+	if len(secret) < MinKeyLength {
+		return Key{}, ErrKeyTooShort
+	}
+	return Key{b: append([]byte(nil), secret...)}, nil
+}
+
+// GenerateKey returns a new random 32-byte Key read from crypto/rand.
+func GenerateKey() (Key, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return Key{}, err
+	}
+	return Key{b: b}, nil
+}
+
+// NewRigidFromKey creates a new Rigid instance with the secret key k, as NewRigid does.
+func NewRigidFromKey(k Key, signatureLength ...int) (*Rigid, error) {
+	return NewRigid(k.b, signatureLength...)
+}
+
+// Bytes returns a copy of the key material. It is the only way to read the key back out.
+func (k Key) Bytes() []byte {
+	return append([]byte(nil), k.b...)
+}
+
+// Len returns the length of the key in bytes.
+func (k Key) Len() int {
+	return len(k.b)
+}
+
+// ID returns the key ID of k, the same fingerprint Rigid.KeyID reports for instances using it.
+func (k Key) ID() string {
+	return keyID(k.b)
+}
+
+// Equal reports whether k and other hold the same key. The comparison runs in constant time
+// with respect to the key material; only the lengths may leak through timing.
+func (k Key) Equal(other Key) bool {
+	return subtle.ConstantTimeCompare(k.b, other.b) == 1
+}
+
+// String implements fmt.Stringer without revealing the key.
+func (k Key) String() string {
+	return redactedKey
+}
+
+// GoString implements fmt.GoStringer without revealing the key.
+func (k Key) GoString() string {
+	return redactedKey
+}
+
+// Format implements fmt.Formatter, printing the placeholder for every verb, including %x and %q.
+func (k Key) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redactedKey)
+}
+
+// LogValue implements slog.LogValuer, logging the placeholder.
+func (k Key) LogValue() slog.Value {
+	return slog.StringValue(redactedKey)
+}
+
+// MarshalText implements encoding.TextMarshaler by always failing, which also stops the key
+// being marshaled to JSON.
+func (k Key) MarshalText() ([]byte, error) {
+	return nil, errMarshalKey
+}
@@ -0,0 +1,103 @@
+package rigid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKey(t *testing.T) {
+	secret := append([]byte(nil), testSecretKey...)
+	k, err := NewKey(secret)
+	require.NoError(t, err)
+	assert.Equal(t, len(testSecretKey), k.Len())
+
+	// The key is copied in and out
+	secret[0] ^= 0xff
+	assert.Equal(t, testSecretKey, k.Bytes())
+	k.Bytes()[0] ^= 0xff
+	assert.Equal(t, testSecretKey, k.Bytes())
+
+	_, err = NewKey(nil)
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewKey(make([]byte, MinKeyLength-1))
+	assert.ErrorIs(t, err, ErrKeyTooShort)
+	_, err = NewKey(make([]byte, MinKeyLength))
+	assert.NoError(t, err)
+}
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	require.NoError(t, err)
+	b, err := GenerateKey()
+	require.NoError(t, err)
+
+	assert.Equal(t, 32, a.Len())
+	assert.False(t, a.Equal(b))
+	assert.True(t, a.Equal(a))
+}
+
+func TestKeyEqual(t *testing.T) {
+	a, err := NewKey(testSecretKey)
+	require.NoError(t, err)
+	b, err := NewKey(testSecretKey)
+	require.NoError(t, err)
+	other, err := NewKey([]byte("another-secret-key-for-testing"))
+	require.NoError(t, err)
+
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.Equal(other))
+	assert.False(t, a.Equal(Key{}))
+}
+
+func TestKeyRedacted(t *testing.T) {
+	k, err := NewKey(testSecretKey)
+	require.NoError(t, err)
+
+	secret := string(testSecretKey)
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d"} {
+		for _, v := range []any{k, &k, struct{ Key Key }{k}} {
+			out := fmt.Sprintf(verb, v)
+			assert.NotContains(t, out, secret, verb)
+			assert.NotContains(t, out, fmt.Sprintf("%x", testSecretKey), verb)
+			assert.Contains(t, out, "redacted", verb)
+		}
+	}
+	assert.Equal(t, "rigid.Key(redacted)", k.String())
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("configured", "key", k)
+	assert.NotContains(t, buf.String(), secret)
+	assert.Contains(t, buf.String(), "redacted")
+
+	_, err = json.Marshal(struct{ Key Key }{k})
+	assert.Error(t, err)
+	_, err = k.MarshalText()
+	assert.Error(t, err)
+}
+
+func TestNewRigidFromKey(t *testing.T) {
+	k, err := NewKey(testSecretKey)
+	require.NoError(t, err)
+
+	r, err := NewRigidFromKey(k, 16)
+	require.NoError(t, err)
+	plain, err := NewRigid(testSecretKey, 16)
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	_, err = plain.Verify(id)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.KeyID(), k.ID())
+
+	_, err = NewRigidFromKey(Key{})
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewRigidFromKey(k, 3)
+	assert.ErrorIs(t, err, ErrInvalidSigLength)
+}
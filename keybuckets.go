@@ -0,0 +1,142 @@
+package rigid
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrInvalidKeyBuckets indicates a period or window passed to WithKeyBuckets is out of range.
+var ErrInvalidKeyBuckets = errors.New("key bucket period or window out of range")
+
+// Bounds of the key bucket configuration of WithKeyBuckets.
+const (
+	// MinKeyBucketPeriod is the shortest period of key buckets.
+	MinKeyBucketPeriod = time.Second
+	// MaxKeyBucketWindow is the largest number of previous buckets accepted. Each accepted
+	// bucket costs a signature computation when verifying IDs that fail verification.
+	MaxKeyBucketWindow = 64
+)
+
+// keyBuckets derives signing keys from the key of an instance for consecutive periods of time.
+// Its pools are keyed for the instance it was made for, and are replaced whenever the key of an
+// instance changes.
+type keyBuckets struct {
+	period time.Duration
+	window int64
+	now    func() time.Time
+
+	mu    sync.RWMutex
+	pools map[bucketPool]*sync.Pool
+}
+
+// bucketPool identifies the MAC pool of a bucket for a format version family.
+type bucketPool struct {
+	bucket int64
+	tag    bool // keyed for FormatV3
+}
+
+// WithKeyBuckets returns a copy of r signing IDs with a key derived from its key for the current
+// period of time, such as the current day with a period of 24 hours, counted from the Unix epoch
+// in UTC. Verification accepts IDs signed in the current period and in the window previous ones,
+// so IDs expire by themselves after window to window+1 periods, without carrying any expiry claim,
+// and even where they carry no timestamp, as with WithUnordered. Expired IDs fail verification
+// with ErrIntegrityFailure, as they are indistinguishable from forged ones. IDs of trusted
+// issuers other than r's own are verified with their keys as usual.
+// Verifying an ID that fails costs a signature computation for each accepted period. A zero
+// period removes the buckets.
+// The returned instance shares the secret key and entropy source with r.
+// Returns ErrInvalidKeyBuckets if period is shorter than MinKeyBucketPeriod or window is not
+// between zero and MaxKeyBucketWindow.
+func (r *Rigid) WithKeyBuckets(period time.Duration, window int) (*Rigid, error) {
+	c := r.clone()
+	if period == 0 {
+		c.buckets = nil
+		return c, nil
+	}
+	if period < MinKeyBucketPeriod || window < 0 || window > MaxKeyBucketWindow {
+		return nil, ErrInvalidKeyBuckets
+	}
+	c.buckets = newKeyBuckets(period, int64(window), time.Now)
+	return c, nil
+}
+
+func newKeyBuckets(period time.Duration, window int64, now func() time.Time) *keyBuckets {
+	return &keyBuckets{period: period, window: window, now: now, pools: make(map[bucketPool]*sync.Pool)}
+}
+
+// rekeyed returns key buckets with the configuration of b for an instance with another key.
+func (b *keyBuckets) rekeyed() *keyBuckets {
+	if b == nil {
+		return nil
+	}
+	return newKeyBuckets(b.period, b.window, b.now)
+}
+
+// configuredPeriod returns the period of b, or zero if b is nil.
+func (b *keyBuckets) configuredPeriod() time.Duration {
+	if b == nil {
+		return 0
+	}
+	return b.period
+}
+
+// configuredWindow returns the window of b, or zero if b is nil.
+func (b *keyBuckets) configuredWindow() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.window)
+}
+
+// current returns the index of the current bucket.
+func (b *keyBuckets) current() int64 {
+	return b.now().UnixNano() / int64(b.period)
+}
+
+// pool returns the MAC pool of r for format version v in the given bucket.
+func (b *keyBuckets) pool(r *Rigid, v FormatVersion, bucket int64) *sync.Pool {
+	key := bucketPool{bucket: bucket, tag: v == FormatV3}
+	b.mu.RLock()
+	pool := b.pools[key]
+	b.mu.RUnlock()
+	if pool != nil {
+		return pool
+	}
+
+	k := hkdf(r.macKey, formatDomain+"bucket\x00"+strconv.FormatInt(int64(b.period), 10)+"\x00"+strconv.FormatInt(bucket, 10))
+	if key.tag {
+		k = tagKey(k, r.signatureLength)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if pool = b.pools[key]; pool == nil {
+		pool = newMACPool(k)
+		b.pools[key] = pool
+		// Forget the pools of buckets no longer accepted
+		for old := range b.pools {
+			if old.bucket < bucket-b.window {
+				delete(b.pools, old)
+			}
+		}
+	}
+	return pool
+}
+
+// bucketSignatureMatches reports whether seg, with its metadata replaced by metadata, carries the
+// signature r computes in format version v in the current bucket or one of the window previous ones.
+func (r *Rigid) bucketSignatureMatches(v FormatVersion, seg segments, metadata string) bool {
+	current := r.buckets.current()
+	for bucket := current; bucket >= current-r.buckets.window; bucket-- {
+		pool := r.buckets.pool(r, v, bucket)
+		mac := pool.Get().(*macState)
+		ok := equalSignature(seg.signature, mac.signature(v, seg.prefix, seg.ulid, metadata, r.signatureLength))
+		putMAC(pool, mac)
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bucketClock sets the clock of the key buckets of r to *now.
+func bucketClock(r *Rigid, now *time.Time) {
+	r.buckets.now = func() time.Time { return *now }
+}
+
+func TestWithKeyBuckets(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err := base.WithKeyBuckets(24*time.Hour, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, r.Config().KeyBucketPeriod)
+	assert.Equal(t, 2, r.Config().KeyBucketWindow)
+
+	now := time.Date(2025, time.March, 14, 23, 0, 0, 0, time.UTC)
+	bucketClock(r, &now)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	_, err = r.Verify(id)
+	require.NoError(t, err)
+	_, err = base.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "bucketed IDs are signed with derived keys")
+
+	for _, day := range []int{1, 2} {
+		now = time.Date(2025, time.March, 14+day, 12, 0, 0, 0, time.UTC)
+		result, err := r.Verify(id)
+		assert.NoError(t, err, "day %d", day)
+		assert.Equal(t, base.KeyID(), result.KeyID, "buckets derive from the key of the instance")
+	}
+	now = time.Date(2025, time.March, 17, 0, 0, 0, 0, time.UTC)
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure, "IDs expire after the window")
+
+	// IDs signed in the future are not accepted either
+	newer, err := r.Generate()
+	require.NoError(t, err)
+	now = time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC)
+	_, err = r.Verify(newer)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// Removing the buckets restores the key
+	plain, err := r.WithKeyBuckets(0, 0)
+	require.NoError(t, err)
+	id, err = plain.Generate()
+	require.NoError(t, err)
+	_, err = base.Verify(id)
+	assert.NoError(t, err)
+}
+
+func TestKeyBucketsDerived(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	base, err = base.WithKeyBuckets(time.Hour, 0)
+	require.NoError(t, err)
+	now := time.Date(2025, time.March, 14, 10, 30, 0, 0, time.UTC)
+
+	for _, v := range []FormatVersion{FormatV1, FormatV2, FormatV3} {
+		r, err := base.WithFormatVersion(v)
+		require.NoError(t, err)
+		r, err = r.WithSignatureLength(12)
+		require.NoError(t, err)
+		r = r.WithNamespace("billing")
+		bucketClock(r, &now)
+
+		id, err := r.Generate()
+		require.NoError(t, err)
+		_, err = r.Verify(id)
+		assert.NoError(t, err, "version %d", v)
+
+		other := r.WithNamespace("payments")
+		bucketClock(other, &now)
+		_, err = other.Verify(id)
+		assert.ErrorIs(t, err, ErrIntegrityFailure, "version %d", v)
+
+		shorter, err := r.WithSignatureLength(8)
+		require.NoError(t, err)
+		bucketClock(shorter, &now)
+		longer, err := shorter.WithSignatureLength(12)
+		require.NoError(t, err)
+		bucketClock(longer, &now)
+		_, err = longer.Verify(id)
+		assert.NoError(t, err, "version %d", v)
+	}
+}
+
+func TestKeyBucketsPoolsForgotten(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithKeyBuckets(time.Second, 1)
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	bucketClock(r, &now)
+
+	for range 10 {
+		_, err := r.Generate()
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+	assert.LessOrEqual(t, len(r.buckets.pools), 2)
+}
+
+func TestWithKeyBucketsInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		period time.Duration
+		window int
+	}{
+		{time.Millisecond, 1},
+		{-time.Hour, 1},
+		{time.Hour, -1},
+		{time.Hour, MaxKeyBucketWindow + 1},
+	} {
+		_, err := r.WithKeyBuckets(tc.period, tc.window)
+		assert.ErrorIs(t, err, ErrInvalidKeyBuckets, "%v %d", tc.period, tc.window)
+	}
+}
@@ -0,0 +1,105 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKSUID(t *testing.T) {
+	k, err := NewKSUID(testSecretKey)
+	require.NoError(t, err)
+
+	before := time.Now().Truncate(time.Second)
+	id, err := k.Generate("user:alice")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9A-Za-z]{27}-[A-Z2-7]{13}-user:alice$`, id)
+
+	result, err := k.Verify(id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, id[:27], result.KSUID.String())
+	assert.Equal(t, "user:alice", result.Metadata)
+	assert.WithinRange(t, result.Timestamp, before, time.Now())
+
+	// Existing KSUIDs can be signed
+	existing := ksuid.New()
+	signed, err := k.Sign(existing)
+	require.NoError(t, err)
+	result, err = k.Verify(signed)
+	require.NoError(t, err)
+	assert.Equal(t, existing, result.KSUID)
+	assert.Empty(t, result.Metadata)
+}
+
+func TestKSUIDSortOrder(t *testing.T) {
+	k, err := NewKSUID(testSecretKey)
+	require.NoError(t, err)
+
+	// Signed KSUIDs sort like the KSUIDs they carry
+	older, err := ksuid.NewRandomWithTime(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	a, err := k.Sign(older)
+	require.NoError(t, err)
+	b, err := k.Generate()
+	require.NoError(t, err)
+	assert.Less(t, a, b)
+}
+
+func TestKSUIDVerifyFailures(t *testing.T) {
+	k, err := NewKSUID(testSecretKey)
+	require.NoError(t, err)
+	id, err := k.Generate("user:alice")
+	require.NoError(t, err)
+	long, err := NewKSUID(testSecretKey, 16)
+	require.NoError(t, err)
+	longID, err := long.Generate()
+	require.NoError(t, err)
+	u, err := NewUUIDv7(testSecretKey)
+	require.NoError(t, err)
+	uuidID, err := u.Generate()
+	require.NoError(t, err)
+
+	// The same KSUID signed by the UUIDv7 backend does not verify
+	crossSigned := id[:28] + string(u.signature(id[:27], "user:alice")) + "-user:alice"
+
+	tests := []struct {
+		name string
+		id   string
+		err  error
+	}{
+		{"empty", "", ErrInvalidFormat},
+		{"empty signature", id[:28], ErrInvalidFormat},
+		{"bad character", "_" + id[1:], ErrInvalidKSUID},
+		{"overflow", "zzzzzzzzzzzzzzzzzzzzzzzzzzz" + id[27:], ErrInvalidKSUID},
+		{"uuid", uuidID, ErrInvalidFormat},
+		{"tampered metadata", strings.Replace(id, "alice", "mallory", 1), ErrIntegrityFailure},
+		{"tampered signature", id[:28] + "AAAAAAAAAAAAA" + id[41:], ErrIntegrityFailure},
+		{"signature length", longID, ErrSignatureLengthMismatch},
+		{"other backend", crossSigned, ErrIntegrityFailure},
+		{"too long", id + strings.Repeat("x", DefaultMaxLength), ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := k.Verify(tt.id)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, KSUIDResult{}, result)
+		})
+	}
+}
+
+func TestNewKSUIDErrors(t *testing.T) {
+	_, err := NewKSUID(nil)
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewKSUID(testSecretKey, 33)
+	assert.ErrorIs(t, err, ErrInvalidSigLength)
+
+	k, err := NewKSUID(testSecretKey)
+	require.NoError(t, err)
+	_, err = k.Generate(strings.Repeat("m", DefaultMaxLength))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
@@ -0,0 +1,36 @@
+package rigid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMemoryLockUnsupported indicates locked key memory is not available on this platform.
+var ErrMemoryLockUnsupported = errors.New("locked key memory is not supported on this platform")
+
+// NewRigidLocked creates a new Rigid instance like NewRigid, but keeps its copy of the secret key in
+// memory that is locked into RAM, so it is never written to swap. The key sits in a dedicated
+// read-only region surrounded by inaccessible guard pages, away from the garbage-collected heap.
+//
+// The region is held for the life of the process, so create locked instances once and derive
+// variants with the With methods, which share the key. The caller remains responsible for wiping
+// secretKey. The inner and outer padded keys that crypto/hmac derives from the key are kept in
+// ordinary Go memory.
+//
+// Locking may fail when the process exceeds its locked memory limit (RLIMIT_MEMLOCK), and returns
+// ErrMemoryLockUnsupported on platforms without mlock.
+func NewRigidLocked(secretKey []byte, signatureLength ...int) (*Rigid, error) {
+	if len(secretKey) == 0 {
+		return nil, ErrEmptySecretKey
+	}
+	sigLen, err := signatureLengthArg(signatureLength)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := lockedCopy(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("lock key memory: %w", err)
+	}
+	return newRigid(key, sigLen), nil
+}
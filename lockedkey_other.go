@@ -0,0 +1,7 @@
+//go:build !unix
+
+package rigid
+
+func lockedCopy([]byte) ([]byte, error) {
+	return nil, ErrMemoryLockUnsupported
+}
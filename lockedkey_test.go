@@ -0,0 +1,60 @@
+package rigid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRigidLocked(t *testing.T) {
+	secret := append([]byte(nil), testSecretKey...)
+	locked, err := NewRigidLocked(secret, 16)
+	if errors.Is(err, ErrMemoryLockUnsupported) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+
+	// The key is copied out of the caller's slice
+	for i := range secret {
+		secret[i] = 0
+	}
+
+	plain, err := NewRigid(testSecretKey, 16)
+	require.NoError(t, err)
+	assert.Equal(t, plain.KeyID(), locked.KeyID())
+
+	id, err := locked.Generate("user:alice")
+	require.NoError(t, err)
+	_, err = plain.Verify(id)
+	assert.NoError(t, err)
+
+	// Derived instances share the locked key
+	typed, err := locked.WithPrefix("ord")
+	require.NoError(t, err)
+	id, err = typed.Generate()
+	require.NoError(t, err)
+	_, err = typed.Verify(id)
+	assert.NoError(t, err)
+
+	_, err = NewRigidLocked(nil)
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewRigidLocked(testSecretKey, 3)
+	assert.ErrorIs(t, err, ErrInvalidSigLength)
+}
+
+func TestLockedCopyLargeKey(t *testing.T) {
+	// Keys spanning several pages are locked whole
+	key := make([]byte, 3*4096+17)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	locked, err := lockedCopy(key)
+	if errors.Is(err, ErrMemoryLockUnsupported) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	assert.Equal(t, key, locked)
+	assert.Equal(t, len(key), cap(locked))
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package rigid
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockedCopy copies b into a locked, read-only page enclosed by guard pages and returns the copy.
+// The copy ends right before the trailing guard page, so reads past its end fault.
+func lockedCopy(b []byte) ([]byte, error) {
+	page := os.Getpagesize()
+	size := (len(b) + page - 1) / page * page
+
+	mem, err := unix.Mmap(-1, 0, size+2*page, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	data := mem[page : page+size]
+	if err := guard(mem, data, page); err != nil {
+		_ = unix.Munmap(mem)
+		return nil, err
+	}
+
+	key := data[size-len(b):]
+	copy(key, b)
+	if err := unix.Mprotect(data, unix.PROT_READ); err != nil {
+		_ = unix.Munmap(mem)
+		return nil, err
+	}
+	return key[:len(b):len(b)], nil
+}
+
+// guard makes the pages around data inaccessible and locks data into memory.
+func guard(mem, data []byte, page int) error {
+	if err := unix.Mprotect(mem[:page], unix.PROT_NONE); err != nil {
+		return err
+	}
+	if err := unix.Mprotect(mem[len(mem)-page:], unix.PROT_NONE); err != nil {
+		return err
+	}
+	return unix.Mlock(data)
+}
@@ -0,0 +1,30 @@
+package rigid
+
+import "log/slog"
+
+// redactedSignature replaces signatures in redacted IDs.
+const redactedSignature = "***"
+
+// Redact returns id with its signature segment masked, keeping the prefix, ULID, and metadata.
+// Without its signature an ID cannot be replayed, so the result is safe to write to logs even when
+// IDs double as credentials. Values that are not rigid IDs are masked entirely.
+func Redact(id string) string {
+	seg, err := splitID(id)
+	if err != nil {
+		return redactedSignature
+	}
+
+	s := seg.ulid + "-" + redactedSignature
+	if seg.prefix != "" {
+		s = seg.prefix + "_" + s
+	}
+	if seg.hasMetadata {
+		s += "-" + seg.metadata
+	}
+	return s
+}
+
+// LogValue implements slog.LogValuer, logging the ID with its signature masked as Redact does.
+func (id ID) LogValue() slog.Value {
+	return slog.StringValue(Redact(string(id)))
+}
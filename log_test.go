@@ -0,0 +1,46 @@
+package rigid
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	prefixed, err := r.WithPrefix("sess")
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice-smith")
+	require.NoError(t, err)
+	assert.Equal(t, id[:26]+"-***-user:alice-smith", Redact(id))
+
+	id, err = prefixed.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, id[:31]+"-***", Redact(id))
+
+	assert.Equal(t, "***", Redact("secret-without-structure"[:6]))
+	assert.Equal(t, "***", Redact(""))
+}
+
+func TestIDLogValue(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	signature := strings.Split(generated, "-")[1]
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("session created", "session", ID(generated))
+
+	assert.Contains(t, buf.String(), generated[:26])
+	assert.Contains(t, buf.String(), "user:alice")
+	assert.NotContains(t, buf.String(), signature)
+}
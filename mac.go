@@ -0,0 +1,171 @@
+package rigid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+	"sync"
+)
+
+// FormatVersion identifies how the signed input of an ID is assembled from its segments, and
+// how the signature is cut down to the configured signature length. The version is not encoded
+// in IDs; generators and verifiers must be configured alike, see WithFormatVersion.
+type FormatVersion uint8
+
+const (
+	// FormatV1 signs the concatenation of the signed prefix, the ULID and the metadata, and
+	// truncates the HMAC to the signature length. It is the default, and the format used by
+	// the Python rigid library.
+	FormatV1 FormatVersion = 1
+	// FormatV2 signs a header binding the format version, MAC algorithm and signature length,
+	// followed by each of the prefix, ULID and metadata preceded by its length as a 4-byte
+	// big-endian integer, so no two distinct sets of segments or parameters share a signed input.
+	// The HMAC is truncated to the signature length.
+	FormatV2 FormatVersion = 2
+	// FormatV3 signs the same input as FormatV2 with a key derived for the signature length by
+	// HKDF-SHA256, then truncates. Signatures of different lengths are independent, so a short
+	// signature is not the prefix of a longer one and reveals nothing about it.
+	FormatV3 FormatVersion = 3
+)
+
+// formatDomain starts the signed input of framed format versions, followed by the version,
+// algorithm and signature length bytes.
+const formatDomain = "rigid\x00"
+
+// algHMACSHA256 identifies HMAC-SHA256 in framed signed input.
+const algHMACSHA256 = 1
+
+// valid reports whether v is a known format version.
+func (v FormatVersion) valid() bool {
+	return v >= FormatV1 && v <= FormatV3
+}
+
+// tagKey derives the FormatV3 key for signatures of sigLen bytes from key, using the FormatV3
+// header as info.
+func tagKey(key []byte, sigLen int) []byte {
+	return hkdf(key, formatDomain+string([]byte{byte(FormatV3), algHMACSHA256, byte(sigLen)}))
+}
+
+// backendKey derives the key signing the IDs of the backend with the given name, such as UUIDv7,
+// so their signatures are independent of those of rigid IDs made with the same key.
+func backendKey(key []byte, name string) []byte {
+	return hkdf(key, formatDomain+"backend\x00"+name)
+}
+
+// hkdf derives a 32-byte key from key with HKDF-SHA256 (RFC 5869), using an empty salt and the
+// given info. One output block is needed.
+func hkdf(key []byte, info string) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+// maxPooledInput bounds the input buffer kept by pooled MAC states, so one ID carrying
+// unusually large metadata does not pin its buffer in the pool.
+const maxPooledInput = 64 << 10
+
+// macState is reusable scratch space for computing signatures.
+type macState struct {
+	mac   hash.Hash
+	input []byte
+	sum   [sha256.Size]byte
+	enc   [52]byte // base32 encoding of a MaxSignatureLength signature
+}
+
+// newMACPool returns a pool of MAC states keyed with key. Instances derived from one another
+// share the pool, as they share the key.
+func newMACPool(key []byte) *sync.Pool {
+	return &sync.Pool{New: func() any {
+		return &macState{mac: hmac.New(sha256.New, key)}
+	}}
+}
+
+// macPool returns the pool of MAC states keyed for signatures of format version v.
+func (r *Rigid) macPool(v FormatVersion) *sync.Pool {
+	if v == FormatV3 {
+		return r.tagMACs
+	}
+	return r.macs
+}
+
+// getMAC returns a MAC state keyed for format version v from r's pools. Return it with putMAC.
+func (r *Rigid) getMAC(v FormatVersion) *macState {
+	return r.macPool(v).Get().(*macState)
+}
+
+func (r *Rigid) putMAC(v FormatVersion, s *macState) {
+	putMAC(r.macPool(v), s)
+}
+
+// putMAC returns s to pool, which it was taken from.
+func putMAC(pool *sync.Pool, s *macState) {
+	if cap(s.input) > maxPooledInput {
+		s.input = nil
+	}
+	pool.Put(s)
+}
+
+// signature computes the encoded signature of an ID with the given prefix, ULID and metadata
+// in format version v, truncated to sigLen bytes. s must be keyed for v, see getMAC.
+// The result aliases s and is valid until s is reused.
+func (s *macState) signature(v FormatVersion, prefix, ulidStr, metadata string, sigLen int) []byte {
+	// The input is assembled into one buffer, as writing strings to the hash would allocate
+	if v >= FormatV2 {
+		s.input = append(s.input[:0], formatDomain...)
+		s.input = append(s.input, byte(v), algHMACSHA256, byte(sigLen))
+		s.input = appendField(s.input, prefix)
+		s.input = appendField(s.input, ulidStr)
+		s.input = appendField(s.input, metadata)
+	} else {
+		s.input = appendPrefix(s.input[:0], prefix)
+		s.input = append(s.input, ulidStr...)
+		s.input = append(s.input, metadata...)
+	}
+	return s.sign(sigLen)
+}
+
+// sign computes the encoded signature of s.input, truncated to sigLen bytes.
+// The result aliases s and is valid until s is reused.
+func (s *macState) sign(sigLen int) []byte {
+	s.mac.Reset()
+	s.mac.Write(s.input)
+	sum := s.mac.Sum(s.sum[:0])
+
+	// The standard base32 alphabet is upper case, as signatures are rendered
+	n := signatureEncoding.EncodedLen(sigLen)
+	signatureEncoding.Encode(s.enc[:n], sum[:sigLen])
+	return s.enc[:n]
+}
+
+// appendPrefix appends the signed form of a type prefix to dst: the prefix and an underscore,
+// or nothing if the prefix is empty.
+func appendPrefix(dst []byte, prefix string) []byte {
+	if prefix == "" {
+		return dst
+	}
+	dst = append(dst, prefix...)
+	return append(dst, '_')
+}
+
+// appendField appends f to dst preceded by its length as a 4-byte big-endian integer.
+func appendField(dst []byte, f string) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(f)))
+	return append(dst, f...)
+}
+
+// equalSignature compares a presented signature with the expected one in constant time.
+func equalSignature(presented string, expected []byte) bool {
+	if len(presented) != len(expected) {
+		return false
+	}
+	var v byte
+	for i := range expected {
+		v |= presented[i] ^ expected[i]
+	}
+	return subtle.ConstantTimeByteEq(v, 0) == 1
+}
@@ -0,0 +1,116 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownTenant indicates a Manager has no instance for the requested tenant.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// TenantConfig is the configuration of a tenant of a Manager.
+type TenantConfig struct {
+	// Key is the secret key of the tenant.
+	Key Key
+	// SignatureLength is the signature length in bytes, or zero for DefaultSignatureLength.
+	SignatureLength int
+	// Prefix is the type prefix of the tenant's IDs, or empty for none.
+	Prefix string
+}
+
+// Manager routes generation and verification to per-tenant Rigid instances, for services
+// issuing IDs for many tenants, each with its own key. Tenants can be added, replaced and
+// removed while the manager is in use. Manager is safe for concurrent use.
+type Manager struct {
+	mu      sync.RWMutex
+	tenants map[string]*Rigid
+}
+
+// NewManager returns a Manager without tenants.
+func NewManager() *Manager {
+	return &Manager{tenants: make(map[string]*Rigid)}
+}
+
+// Add creates an instance from cfg and sets it as the instance of tenant, replacing any
+// existing one. Returns the error of NewRigidFromKey or WithPrefix if cfg is invalid.
+func (m *Manager) Add(tenant string, cfg TenantConfig) error {
+	sigLen := cfg.SignatureLength
+	if sigLen == 0 {
+		sigLen = DefaultSignatureLength
+	}
+	r, err := NewRigidFromKey(cfg.Key, sigLen)
+	if err != nil {
+		return err
+	}
+	if r, err = r.WithPrefix(cfg.Prefix); err != nil {
+		return err
+	}
+	m.Set(tenant, r)
+	return nil
+}
+
+// Set sets r as the instance of tenant, replacing any existing one, for tenants needing options
+// beyond TenantConfig.
+func (m *Manager) Set(tenant string, r *Rigid) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tenants[tenant] = r
+}
+
+// Remove removes tenant, if present.
+func (m *Manager) Remove(tenant string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tenants, tenant)
+}
+
+// Tenant returns the instance of tenant, or ErrUnknownTenant.
+func (m *Manager) Tenant(tenant string) (*Rigid, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.tenants[tenant]
+	if !ok {
+		return nil, ErrUnknownTenant
+	}
+	return r, nil
+}
+
+// Tenants returns the tenants of m in sorted order.
+func (m *Manager) Tenants() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tenants := make([]string, 0, len(m.tenants))
+	for tenant := range m.tenants {
+		tenants = append(tenants, tenant)
+	}
+	slices.Sort(tenants)
+	return tenants
+}
+
+// Generate generates an ID for tenant, as Rigid.Generate does.
+// Returns ErrUnknownTenant if tenant has no instance.
+func (m *Manager) Generate(tenant string, metadata ...string) (string, error) {
+	r, err := m.Tenant(tenant)
+	if err != nil {
+		return "", err
+	}
+	return r.Generate(metadata...)
+}
+
+// Verify verifies an ID of tenant, as Rigid.Verify does, so IDs of other tenants fail.
+// Returns ErrUnknownTenant if tenant has no instance.
+func (m *Manager) Verify(tenant, id string) (VerifyResult, error) {
+	return m.VerifyContext(context.Background(), tenant, id)
+}
+
+// VerifyContext verifies an ID of tenant, as Rigid.VerifyContext does.
+// Returns ErrUnknownTenant if tenant has no instance.
+func (m *Manager) VerifyContext(ctx context.Context, tenant, id string) (VerifyResult, error) {
+	r, err := m.Tenant(tenant)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	return r.VerifyContext(ctx, id)
+}
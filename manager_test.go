@@ -0,0 +1,57 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager()
+	acmeKey, err := GenerateKey()
+	require.NoError(t, err)
+	globexKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	require.NoError(t, m.Add("acme", TenantConfig{Key: acmeKey}))
+	require.NoError(t, m.Add("globex", TenantConfig{Key: globexKey, SignatureLength: 16, Prefix: "gx"}))
+	assert.Equal(t, []string{"acme", "globex"}, m.Tenants())
+
+	acmeID, err := m.Generate("acme", "user:alice")
+	require.NoError(t, err)
+	globexID, err := m.Generate("globex")
+	require.NoError(t, err)
+	assert.Regexp(t, "^gx_", globexID)
+	assert.Len(t, globexID, EncodedLen(2, 16, 0))
+
+	result, err := m.Verify("acme", acmeID)
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+	_, err = m.VerifyContext(context.Background(), "globex", globexID)
+	assert.NoError(t, err)
+
+	_, err = m.Verify("globex", acmeID)
+	assert.Error(t, err, "IDs of one tenant do not verify for another")
+	_, err = m.Verify("initech", acmeID)
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+	_, err = m.Generate("initech")
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+
+	r, err := NewRigidFromKey(acmeKey)
+	require.NoError(t, err)
+	m.Set("acme", r.WithStrict())
+	got, err := m.Tenant("acme")
+	require.NoError(t, err)
+	assert.True(t, got.Config().Strict)
+
+	m.Remove("acme")
+	_, err = m.Verify("acme", acmeID)
+	assert.ErrorIs(t, err, ErrUnknownTenant)
+
+	assert.ErrorIs(t, m.Add("bad", TenantConfig{}), ErrEmptySecretKey)
+	assert.ErrorIs(t, m.Add("bad", TenantConfig{Key: acmeKey, Prefix: "a-b"}), ErrInvalidPrefix)
+	assert.ErrorIs(t, m.Add("bad", TenantConfig{Key: acmeKey, SignatureLength: 2}), ErrInvalidSigLength)
+	assert.Equal(t, []string{"globex"}, m.Tenants())
+}
@@ -0,0 +1,206 @@
+package rigid
+
+import (
+	"context"
+	"hash/maphash"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryShards is the number of independently locked maps of a MemoryStore.
+const memoryShards = 32
+
+// MemoryStore is an in-memory RevocationStore and ReplayStore for single-instance deployments,
+// where a Redis server would only add an operational dependency. Entries are spread over
+// independently locked shards, so concurrent verifications rarely contend, and expire with the
+// TTL they were stored with. Expired entries are never reported, and a background janitor
+// removes them so memory stays bounded by the entries still live.
+// State is lost on restart and not shared between instances; use rigidfile or rigidredis for that.
+type MemoryStore struct {
+	seed   maphash.Seed
+	shards [memoryShards]memoryShard
+	now    func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// memoryShard holds the entries of a MemoryStore whose ULIDs hash to it, mapped to their
+// expiry, the zero time meaning never.
+type memoryShard struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	used    map[string]time.Time
+}
+
+var (
+	_ RevocationStore        = (*MemoryStore)(nil)
+	_ RevocationLister       = (*MemoryStore)(nil)
+	_ RevocationBatchChecker = (*MemoryStore)(nil)
+	_ ReplayStore            = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore returns an empty MemoryStore whose janitor removes expired entries every
+// janitorInterval. A janitorInterval of zero or less disables the janitor, leaving expired
+// entries in memory until they are looked up. Close the store to stop the janitor.
+func NewMemoryStore(janitorInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{seed: maphash.MakeSeed(), now: time.Now, stop: make(chan struct{}), done: make(chan struct{})}
+	for i := range s.shards {
+		s.shards[i].revoked = make(map[string]time.Time)
+		s.shards[i].used = make(map[string]time.Time)
+	}
+	if janitorInterval > 0 {
+		go s.janitor(janitorInterval)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+// Revoke implements RevocationStore.
+func (s *MemoryStore) Revoke(_ context.Context, ulid string, ttl time.Duration) error {
+	expiresAt := s.expiry(ttl)
+	sh := s.shard(ulid)
+	sh.mu.Lock()
+	sh.revoked[ulid] = expiresAt
+	sh.mu.Unlock()
+	return nil
+}
+
+// IsRevoked implements RevocationStore.
+func (s *MemoryStore) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	now := s.now()
+	sh := s.shard(ulid)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return live(sh.revoked, ulid, now), nil
+}
+
+// AreRevoked implements RevocationBatchChecker.
+func (s *MemoryStore) AreRevoked(ctx context.Context, ulids []string) ([]bool, error) {
+	revoked := make([]bool, len(ulids))
+	for i, u := range ulids {
+		revoked[i], _ = s.IsRevoked(ctx, u)
+	}
+	return revoked, nil
+}
+
+// Unrevoke removes the revocation of the ID with the given ULID.
+func (s *MemoryStore) Unrevoke(_ context.Context, ulid string) error {
+	sh := s.shard(ulid)
+	sh.mu.Lock()
+	delete(sh.revoked, ulid)
+	sh.mu.Unlock()
+	return nil
+}
+
+// ListRevoked implements RevocationLister.
+func (s *MemoryStore) ListRevoked(context.Context) ([]Revocation, error) {
+	now := s.now()
+	var revocations []Revocation
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		for u, expiresAt := range sh.revoked {
+			if expiresAt.IsZero() || now.Before(expiresAt) {
+				revocations = append(revocations, Revocation{ULID: u, ExpiresAt: expiresAt})
+			}
+		}
+		sh.mu.Unlock()
+	}
+	slices.SortFunc(revocations, func(a, b Revocation) int { return strings.Compare(a.ULID, b.ULID) })
+	return revocations, nil
+}
+
+// MarkUsed implements ReplayStore. An expired use no longer counts, so the ID is accepted again.
+func (s *MemoryStore) MarkUsed(_ context.Context, ulid string, ttl time.Duration) (bool, error) {
+	now := s.now()
+	sh := s.shard(ulid)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if live(sh.used, ulid, now) {
+		return false, nil
+	}
+	sh.used[ulid] = s.expiry(ttl)
+	return true, nil
+}
+
+// Len returns the number of entries held, including expired ones the janitor has not removed yet.
+func (s *MemoryStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		n += len(sh.revoked) + len(sh.used)
+		sh.mu.Unlock()
+	}
+	return n
+}
+
+// Close stops the janitor. The store remains usable. Close is safe to call more than once.
+func (s *MemoryStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
+
+// janitor removes expired entries every interval until the store is closed.
+func (s *MemoryStore) janitor(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.removeExpired()
+		}
+	}
+}
+
+// removeExpired removes the entries expired by now, one shard at a time.
+func (s *MemoryStore) removeExpired() {
+	now := s.now()
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		for _, m := range []map[string]time.Time{sh.revoked, sh.used} {
+			for u, expiresAt := range m {
+				if !expiresAt.IsZero() && !now.Before(expiresAt) {
+					delete(m, u)
+				}
+			}
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// shard returns the shard holding the entries of ulid.
+func (s *MemoryStore) shard(ulid string) *memoryShard {
+	return &s.shards[maphash.String(s.seed, ulid)%memoryShards]
+}
+
+// expiry returns the expiry of an entry stored now for ttl, the zero time for zero.
+func (s *MemoryStore) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(ttl)
+}
+
+// live reports whether m holds an entry for ulid that has not expired by now, removing it if it has.
+func live(m map[string]time.Time, ulid string, now time.Time) bool {
+	expiresAt, ok := m[ulid]
+	if !ok {
+		return false
+	}
+	if !expiresAt.IsZero() && !now.Before(expiresAt) {
+		delete(m, ulid)
+		return false
+	}
+	return true
+}
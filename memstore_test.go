@@ -0,0 +1,140 @@
+package rigid
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(0)
+	defer s.Close()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, s.Revoke(ctx, "a", time.Minute))
+	require.NoError(t, s.Revoke(ctx, "b", 0))
+	revoked, err := s.AreRevoked(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, revoked)
+
+	list, err := s.ListRevoked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Revocation{{ULID: "a", ExpiresAt: now.Add(time.Minute)}, {ULID: "b"}}, list)
+
+	require.NoError(t, s.Unrevoke(ctx, "b"))
+	ok, err := s.IsRevoked(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Entries expire with their TTL
+	now = now.Add(time.Minute)
+	ok, err = s.IsRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Zero(t, s.Len(), "expired entries are removed when looked up")
+}
+
+func TestMemoryStoreReplay(t *testing.T) {
+	s := NewMemoryStore(0)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, err := s.MarkUsed(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first)
+	first, err = s.MarkUsed(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, first)
+
+	// Revocations and uses of the same ULID are kept apart
+	ok, err := s.IsRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	first, err = s.MarkUsed(ctx, "a", 0)
+	require.NoError(t, err)
+	assert.True(t, first, "expired uses no longer count")
+	now = now.Add(24 * time.Hour)
+	first, err = s.MarkUsed(ctx, "a", 0)
+	require.NoError(t, err)
+	assert.False(t, first, "uses without a TTL are kept indefinitely")
+}
+
+func TestMemoryStoreJanitor(t *testing.T) {
+	s := NewMemoryStore(5 * time.Millisecond)
+	defer s.Close()
+	ctx := context.Background()
+
+	for i := range 100 {
+		require.NoError(t, s.Revoke(ctx, fmt.Sprint("short", i), time.Millisecond))
+		_, err := s.MarkUsed(ctx, fmt.Sprint("short", i), time.Millisecond)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Revoke(ctx, "long", time.Hour))
+
+	assert.Eventually(t, func() bool { return s.Len() == 1 }, time.Second, 5*time.Millisecond)
+	ok, err := s.IsRevoked(ctx, "long")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Close(), "Close is idempotent")
+}
+
+func TestMemoryStoreVerification(t *testing.T) {
+	s := NewMemoryStore(time.Minute)
+	defer s.Close()
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r := base.WithRevocationStore(s).WithReplayStore(s, time.Hour)
+	ctx := context.Background()
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	_, err = r.VerifyContext(ctx, id)
+	require.NoError(t, err)
+	_, err = r.VerifyContext(ctx, id)
+	assert.ErrorIs(t, err, ErrReplayed)
+
+	other, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(ctx, other, time.Hour))
+	_, err = r.VerifyContext(ctx, other)
+	assert.ErrorIs(t, err, ErrRevoked)
+}
+
+func TestMemoryStoreConcurrentUse(t *testing.T) {
+	s := NewMemoryStore(time.Millisecond)
+	defer s.Close()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	firsts := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				first, err := s.MarkUsed(ctx, fmt.Sprint(i), time.Hour)
+				assert.NoError(t, err)
+				if first {
+					mu.Lock()
+					firsts++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 200, firsts, "each ID is accepted exactly once")
+}
@@ -0,0 +1,212 @@
+package rigid
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetaBuilder builds metadata from named fields in a canonical encoding, replacing hand-written
+// conventions such as fmt.Sprintf("user:%s:role:%s", u, role), which break as soon as a value
+// contains a colon. Fields are encoded as key:value pairs joined by colons and sorted by key, so
+// the same fields always produce the same metadata. Colons, percent signs and control
+// characters in keys and values are percent-encoded. Metadata without such characters reads as
+// before:
+//
+//	rigid.Meta().Str("user", "alice").Str("role", "admin").String() // "role:admin:user:alice"
+//
+// The zero MetaBuilder is empty and ready to use. A MetaBuilder is not safe for concurrent use.
+type MetaBuilder struct {
+	fields map[string]string
+}
+
+// Meta returns an empty MetaBuilder.
+func Meta() *MetaBuilder {
+	return &MetaBuilder{}
+}
+
+// Str sets the field key to value, replacing any earlier value of key.
+func (b *MetaBuilder) Str(key, value string) *MetaBuilder {
+	if b.fields == nil {
+		b.fields = make(map[string]string)
+	}
+	b.fields[key] = value
+	return b
+}
+
+// Int sets the field key to v in decimal.
+func (b *MetaBuilder) Int(key string, v int64) *MetaBuilder {
+	return b.Str(key, strconv.FormatInt(v, 10))
+}
+
+// Bool sets the field key to "true" or "false".
+func (b *MetaBuilder) Bool(key string, v bool) *MetaBuilder {
+	return b.Str(key, strconv.FormatBool(v))
+}
+
+// Time sets the field key to t in Unix seconds, dropping any fraction of a second.
+func (b *MetaBuilder) Time(key string, t time.Time) *MetaBuilder {
+	return b.Int(key, t.Unix())
+}
+
+// String returns the canonical encoding of the fields, or "" if there are none.
+func (b *MetaBuilder) String() string {
+	keys := make([]string, 0, len(b.fields))
+	for k := range b.fields {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(':')
+		}
+		writeMetaEscaped(&sb, k)
+		sb.WriteByte(':')
+		writeMetaEscaped(&sb, b.fields[k])
+	}
+	return sb.String()
+}
+
+// MetaValues are the fields of metadata in the encoding of MetaBuilder, by key.
+type MetaValues map[string]string
+
+// ParseMeta parses metadata in the encoding of MetaBuilder. Fields may appear in any order, so
+// metadata written with the key:value convention by hand parses too. Returns an error wrapping
+// ErrInvalidMetadata if s is not a list of key:value pairs, repeats a key or is badly escaped.
+func ParseMeta(s string) (MetaValues, error) {
+	values := MetaValues{}
+	if s == "" {
+		return values, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of key:value parts", ErrInvalidMetadata)
+	}
+	for i := 0; i < len(parts); i += 2 {
+		key, err := unescapeMeta(parts[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := unescapeMeta(parts[i+1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("%w: repeated key %q", ErrInvalidMetadata, key)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Meta parses the metadata of the result with ParseMeta.
+func (v VerifyResult) Meta() (MetaValues, error) {
+	return ParseMeta(v.Metadata)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with ParseMeta, so VerifyInto can decode
+// metadata into MetaValues.
+func (m *MetaValues) UnmarshalText(text []byte) error {
+	values, err := ParseMeta(string(text))
+	if err != nil {
+		return err
+	}
+	*m = values
+	return nil
+}
+
+// Str returns the value of key, or "" if it is not set.
+func (m MetaValues) Str(key string) string {
+	return m[key]
+}
+
+// Int returns the value of key parsed as a decimal integer. Returns an error wrapping
+// ErrInvalidMetadata if key is not set or is not an integer.
+func (m MetaValues) Int(key string) (int64, error) {
+	s, err := m.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: field %q: %w", ErrInvalidMetadata, key, err)
+	}
+	return v, nil
+}
+
+// Bool returns the value of key parsed with strconv.ParseBool. Returns an error wrapping
+// ErrInvalidMetadata if key is not set or is not a boolean.
+func (m MetaValues) Bool(key string) (bool, error) {
+	s, err := m.lookup(key)
+	if err != nil {
+		return false, err
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("%w: field %q: %w", ErrInvalidMetadata, key, err)
+	}
+	return v, nil
+}
+
+// Time returns the value of key parsed as Unix seconds. Returns an error wrapping
+// ErrInvalidMetadata if key is not set or is not an integer.
+func (m MetaValues) Time(key string) (time.Time, error) {
+	v, err := m.Int(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(v, 0), nil
+}
+
+// lookup returns the value of key, or an error if it is not set.
+func (m MetaValues) lookup(key string) (string, error) {
+	s, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("%w: missing field %q", ErrInvalidMetadata, key)
+	}
+	return s, nil
+}
+
+// writeMetaEscaped writes s to sb, percent-encoding colons, percent signs and control characters.
+func writeMetaEscaped(sb *strings.Builder, s string) {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == ':' || c == '%' || c < 0x20 || c == 0x7f {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&0xf])
+			continue
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// unescapeMeta decodes a key or value escaped by writeMetaEscaped.
+func unescapeMeta(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b = append(b, s[i])
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", fmt.Errorf("%w: truncated escape in %q", ErrInvalidMetadata, s)
+		}
+		v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid escape in %q", ErrInvalidMetadata, s)
+		}
+		b = append(b, byte(v))
+		i += 2
+	}
+	return string(b), nil
+}
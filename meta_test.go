@@ -0,0 +1,89 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaBuilder(t *testing.T) {
+	assert.Equal(t, "role:admin:user:alice", Meta().Str("user", "alice").Str("role", "admin").String())
+	assert.Equal(t, "role:admin:user:alice", Meta().Str("role", "admin").Str("user", "alice").String(),
+		"fields are sorted, so the order they are set in does not matter")
+	assert.Equal(t, "user:bob", Meta().Str("user", "alice").Str("user", "bob").String())
+	assert.Empty(t, Meta().String())
+	var zero MetaBuilder
+	assert.Equal(t, "a:1", zero.Int("a", 1).String())
+
+	at := time.Unix(1700000000, 500)
+	assert.Equal(t, "admin:true:at:1700000000:n:-42", Meta().Int("n", -42).Bool("admin", true).Time("at", at).String())
+
+	// Separators in keys and values cannot forge fields
+	assert.Equal(t, "url:https%3A//example.com/%25x", Meta().Str("url", "https://example.com/%x").String())
+	assert.Equal(t, "user:alice%3Arole%3Aadmin", Meta().Str("user", "alice:role:admin").String())
+	assert.Equal(t, "a%3Ab:%0A", Meta().Str("a:b", "\n").String())
+}
+
+func TestParseMeta(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	b := Meta().Str("user", "alice:x").Str("url", "https://a/%").Int("n", 7).Bool("ok", true).Time("at", at).Str("", "")
+	values, err := ParseMeta(b.String())
+	require.NoError(t, err)
+	assert.Equal(t, MetaValues{"user": "alice:x", "url": "https://a/%", "n": "7", "ok": "true", "at": "1700000000", "": ""}, values)
+
+	assert.Equal(t, "alice:x", values.Str("user"))
+	assert.Empty(t, values.Str("missing"))
+	n, err := values.Int("n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+	ok, err := values.Bool("ok")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	parsed, err := values.Time("at")
+	require.NoError(t, err)
+	assert.True(t, at.Equal(parsed))
+
+	_, err = values.Int("user")
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	_, err = values.Bool("missing")
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	_, err = values.Time("ok")
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	// Hand-written metadata in the key:value convention parses in any order
+	values, err = ParseMeta("user:alice:role:admin")
+	require.NoError(t, err)
+	assert.Equal(t, MetaValues{"user": "alice", "role": "admin"}, values)
+
+	values, err = ParseMeta("")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	for _, bad := range []string{"user", "a:1:b", "a:1:a:2", "a:%", "a:%4", "a:%zz"} {
+		_, err := ParseMeta(bad)
+		assert.ErrorIs(t, err, ErrInvalidMetadata, bad)
+	}
+}
+
+func TestVerifyResultMeta(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate(Meta().Str("user", "alice").Int("tenant", 42).String())
+	require.NoError(t, err)
+
+	result, err := r.Verify(id)
+	require.NoError(t, err)
+	values, err := result.Meta()
+	require.NoError(t, err)
+	assert.Equal(t, "alice", values.Str("user"))
+
+	var into MetaValues
+	require.NoError(t, r.VerifyInto(id, &into))
+	assert.Equal(t, values, into)
+
+	bare, err := r.Generate("not key value")
+	require.NoError(t, err)
+	assert.ErrorIs(t, r.VerifyInto(bare, &into), ErrInvalidMetadata)
+}
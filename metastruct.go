@@ -0,0 +1,224 @@
+package rigid
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidClaims indicates a value passed to MetaOf, GenerateClaims or VerifyInto is not a
+// struct with supported rigid-tagged fields.
+var ErrInvalidClaims = errors.New("invalid claims type")
+
+// claimField is a rigid-tagged field of a claims struct.
+type claimField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// claimFields caches the rigid-tagged fields of claims struct types.
+var claimFields sync.Map // reflect.Type -> []claimField
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// MetaOf encodes the rigid-tagged fields of the struct v, or of the struct v points to, as
+// MetaBuilder does, so claim names are checked by the compiler rather than spelled out in
+// strings:
+//
+//	type Claims struct {
+//		User   string `rigid:"user"`
+//		Tenant int    `rigid:"tenant"`
+//		Admin  bool   `rigid:"admin,omitempty"`
+//	}
+//
+// Fields may be strings, booleans, integers, time.Time, encoded in Unix seconds, or implement
+// encoding.TextMarshaler. Fields tagged omitempty are left out when they hold their zero value,
+// and untagged fields and fields tagged "-" are ignored. Returns an error wrapping
+// ErrInvalidClaims if v is not such a struct.
+func MetaOf(v any) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", fmt.Errorf("%w: nil", ErrInvalidClaims)
+	}
+	fields, err := claimFieldsOf(rv.Type())
+	if err != nil {
+		return "", err
+	}
+
+	b := Meta()
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+		s, err := formatClaim(fv)
+		if err != nil {
+			return "", fmt.Errorf("%w: field %q: %w", ErrInvalidClaims, f.name, err)
+		}
+		b.Str(f.name, s)
+	}
+	return b.String(), nil
+}
+
+// GenerateClaims generates an ID whose metadata is the encoding of the claims struct v by MetaOf.
+// VerifyInto decodes the metadata back into a struct of the same type.
+func (r *Rigid) GenerateClaims(v any) (string, error) {
+	metadata, err := MetaOf(v)
+	if err != nil {
+		return "", err
+	}
+	return r.Generate(metadata)
+}
+
+// hasClaimTags reports whether t is a struct with at least one rigid-tagged field.
+func hasClaimTags(t reflect.Type) bool {
+	fields, err := claimFieldsOf(t)
+	return err == nil && len(fields) > 0
+}
+
+// unmarshalClaims decodes metadata in the encoding of MetaBuilder into the rigid-tagged fields of
+// the struct dst points to. Fields not tagged omitempty must be present; keys without a field
+// are ignored.
+func unmarshalClaims(metadata string, dst reflect.Value) error {
+	values, err := ParseMeta(metadata)
+	if err != nil {
+		return err
+	}
+	rv := dst.Elem()
+	fields, err := claimFieldsOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		s, ok := values[f.name]
+		if !ok {
+			if f.omitEmpty {
+				continue
+			}
+			return fmt.Errorf("%w: missing field %q", ErrInvalidMetadata, f.name)
+		}
+		if err := parseClaim(rv.FieldByIndex(f.index), s); err != nil {
+			return fmt.Errorf("%w: field %q: %w", ErrInvalidMetadata, f.name, err)
+		}
+	}
+	return nil
+}
+
+// claimFieldsOf returns the rigid-tagged fields of the struct type t.
+func claimFieldsOf(t reflect.Type) ([]claimField, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrInvalidClaims, t)
+	}
+	if cached, ok := claimFields.Load(t); ok {
+		return cached.([]claimField), nil
+	}
+
+	var fields []claimField
+	seen := make(map[string]bool)
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("rigid")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %v has two fields named %q", ErrInvalidClaims, t, name)
+		}
+		if !supportedClaim(sf.Type) {
+			return nil, fmt.Errorf("%w: field %s has unsupported type %v", ErrInvalidClaims, sf.Name, sf.Type)
+		}
+		seen[name] = true
+		fields = append(fields, claimField{name: name, index: sf.Index, omitEmpty: opts == "omitempty"})
+	}
+	claimFields.Store(t, fields)
+	return fields, nil
+}
+
+// supportedClaim reports whether fields of type t can be encoded and decoded.
+func supportedClaim(t reflect.Type) bool {
+	if t == timeType || (t.Implements(textMarshalerType) && reflect.PointerTo(t).Implements(textUnmarshalerType)) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// formatClaim returns the encoding of the field value v.
+func formatClaim(v reflect.Value) (string, error) {
+	if v.Type() == timeType {
+		return strconv.FormatInt(v.Interface().(time.Time).Unix(), 10), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	default:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+}
+
+// parseClaim sets the field v from its encoding s.
+func parseClaim(v reflect.Value, s string) error {
+	if v.Type() == timeType {
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(time.Unix(sec, 0)))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	}
+	return nil
+}
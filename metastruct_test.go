@@ -0,0 +1,114 @@
+package rigid
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClaims struct {
+	User    string     `rigid:"user"`
+	Tenant  int        `rigid:"tenant"`
+	Admin   bool       `rigid:"admin,omitempty"`
+	Quota   uint16     `rigid:"quota,omitempty"`
+	Issued  time.Time  `rigid:"iat,omitempty"`
+	Addr    netip.Addr `rigid:"addr,omitempty"`
+	Comment string
+	Skipped string `rigid:"-"`
+}
+
+func TestMetaOf(t *testing.T) {
+	claims := testClaims{User: "alice:x", Tenant: 42, Admin: true, Comment: "not encoded", Skipped: "no"}
+	metadata, err := MetaOf(claims)
+	require.NoError(t, err)
+	assert.Equal(t, "admin:true:tenant:42:user:alice%3Ax", metadata)
+
+	fromPointer, err := MetaOf(&claims)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, fromPointer)
+
+	// Fields without omitempty are encoded even when zero
+	metadata, err = MetaOf(testClaims{
+		Issued: time.Unix(1700000000, 0),
+		Addr:   netip.MustParseAddr("192.0.2.1"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "addr:192.0.2.1:iat:1700000000:tenant:0:user:", metadata)
+
+	type unnamed struct {
+		Role string `rigid:",omitempty"`
+	}
+	metadata, err = MetaOf(unnamed{Role: "admin"})
+	require.NoError(t, err)
+	assert.Equal(t, "Role:admin", metadata)
+}
+
+func TestMetaOfInvalid(t *testing.T) {
+	type duplicate struct {
+		A string `rigid:"x"`
+		B string `rigid:"x"`
+	}
+	type unsupported struct {
+		Tags []string `rigid:"tags"`
+	}
+	for _, v := range []any{nil, "user:alice", 42, (*testClaims)(nil), duplicate{}, unsupported{}} {
+		_, err := MetaOf(v)
+		assert.ErrorIs(t, err, ErrInvalidClaims, "%T", v)
+	}
+}
+
+func TestGenerateClaims(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	want := testClaims{User: "alice", Tenant: 42, Quota: 100, Issued: time.Unix(1700000000, 0), Addr: netip.MustParseAddr("::1")}
+	id, err := r.GenerateClaims(want)
+	require.NoError(t, err)
+
+	var got testClaims
+	require.NoError(t, r.VerifyInto(id, &got))
+	assert.Equal(t, want.User, got.User)
+	assert.Equal(t, want.Tenant, got.Tenant)
+	assert.Equal(t, want.Quota, got.Quota)
+	assert.False(t, got.Admin)
+	assert.True(t, want.Issued.Equal(got.Issued))
+	assert.Equal(t, want.Addr, got.Addr)
+
+	_, err = r.GenerateClaims([]string{"user"})
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+}
+
+func TestVerifyIntoClaims(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	verifyInto := func(metadata string) error {
+		id, err := r.Generate(metadata)
+		require.NoError(t, err)
+		var c testClaims
+		return r.VerifyInto(id, &c)
+	}
+
+	assert.NoError(t, verifyInto("user:alice:tenant:1:extra:ignored"))
+	assert.ErrorIs(t, verifyInto("user:alice"), ErrInvalidMetadata, "required fields must be present")
+	assert.ErrorIs(t, verifyInto("user:alice:tenant:many"), ErrInvalidMetadata)
+	assert.ErrorIs(t, verifyInto("user:alice:tenant:1:quota:70000"), ErrInvalidMetadata)
+	assert.ErrorIs(t, verifyInto("user:alice:tenant:1:addr:nowhere"), ErrInvalidMetadata)
+	assert.ErrorIs(t, verifyInto("user"), ErrInvalidMetadata)
+
+	err = verifyInto("user:alice")
+	assert.Equal(t, `invalid metadata: missing field "tenant"`, err.Error())
+
+	// Structs without rigid tags are still decoded as JSON
+	type jsonClaims struct {
+		User string `json:"user"`
+	}
+	id, err := r.Generate(`{"user":"alice"}`)
+	require.NoError(t, err)
+	var jc jsonClaims
+	require.NoError(t, r.VerifyInto(id, &jc))
+	assert.Equal(t, "alice", jc.User)
+}
@@ -0,0 +1,61 @@
+package rigid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNamespace(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	billing := r.WithNamespace("billing")
+	payments := r.WithNamespace("payments")
+	assert.Equal(t, "billing", billing.Namespace())
+	assert.Equal(t, "billing", billing.Config().Namespace)
+	assert.Equal(t, r.KeyID(), billing.KeyID(), "namespaces share the secret key")
+
+	id, err := billing.Generate("invoice:42")
+	require.NoError(t, err)
+	_, err = billing.Verify(id)
+	assert.NoError(t, err)
+	_, err = payments.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = payments.WithNamespace("billing").Verify(id)
+	assert.NoError(t, err, "namespaces replace rather than nest")
+
+	plain, err := r.Generate()
+	require.NoError(t, err)
+	_, err = billing.Verify(plain)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = billing.WithNamespace("").Verify(plain)
+	assert.NoError(t, err)
+
+	for _, v := range []FormatVersion{FormatV1, FormatV2, FormatV3} {
+		versioned, err := billing.WithFormatVersion(v)
+		require.NoError(t, err)
+		versioned, err = versioned.WithSignatureLength(16)
+		require.NoError(t, err)
+		id, err := versioned.Generate()
+		require.NoError(t, err)
+		_, err = versioned.Verify(id)
+		assert.NoError(t, err, "version %d", v)
+
+		other, err := payments.WithFormatVersion(v)
+		require.NoError(t, err)
+		other, err = other.WithSignatureLength(16)
+		require.NoError(t, err)
+		_, err = other.Verify(id)
+		assert.ErrorIs(t, err, ErrIntegrityFailure, "version %d", v)
+	}
+
+	shown, err := billing.WithPrefix("billing")
+	require.NoError(t, err)
+	id, err = shown.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, "^billing_", id)
+	assert.True(t, shown.Explain(id).Valid())
+}
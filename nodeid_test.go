@@ -0,0 +1,115 @@
+package rigid
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNodeID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, node := range []uint32{0, 1, 513, 1023} {
+		n, err := r.WithNodeID(node, 10)
+		require.NoError(t, err)
+		for range 100 {
+			id, err := n.Generate("user:alice")
+			require.NoError(t, err)
+			got, err := n.ExtractNodeID(id)
+			require.NoError(t, err)
+			assert.Equal(t, node, got)
+
+			_, err = r.Verify(id)
+			assert.NoError(t, err, "node IDs do not affect verification")
+		}
+	}
+
+	n, err := r.WithNodeID(0xABCD, MaxNodeIDBits)
+	require.NoError(t, err)
+	id, err := n.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, "NF6", id[10:13], "the node ID occupies the top bits of the entropy")
+	assert.Zero(t, r.Config().NodeIDBits)
+	assert.Equal(t, uint32(0xABCD), n.Config().NodeID)
+	assert.Equal(t, MaxNodeIDBits, n.Config().NodeIDBits)
+	assert.Equal(t, MaxNodeIDBits, n.WithEntropyShards(4).Config().NodeIDBits, "sharding keeps the node ID")
+
+	for _, tc := range []struct {
+		node uint32
+		bits int
+	}{{0, 0}, {0, MaxNodeIDBits + 1}, {1 << 10, 10}, {2, 1}} {
+		_, err := r.WithNodeID(tc.node, tc.bits)
+		assert.ErrorIs(t, err, ErrInvalidNodeID, "node %d, bits %d", tc.node, tc.bits)
+	}
+}
+
+func TestExtractNodeID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	n, err := r.WithNodeID(7, 4)
+	require.NoError(t, err)
+	id, err := n.Generate()
+	require.NoError(t, err)
+
+	_, err = r.ExtractNodeID(id)
+	assert.ErrorIs(t, err, ErrNoNodeID)
+
+	verifier, err := r.WithNodeID(0, 4)
+	require.NoError(t, err)
+	got, err := verifier.ExtractNodeID(id)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), got)
+
+	_, err = verifier.ExtractNodeID(id[:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+}
+
+func TestNodeIDOrdering(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	n, err := r.WithNodeID(3, 8)
+	require.NoError(t, err)
+
+	now := time.Now()
+	prev, err := n.GenerateAt(now)
+	require.NoError(t, err)
+	for range 1000 {
+		id, err := n.GenerateAt(now)
+		require.NoError(t, err)
+		assert.Less(t, prev, id)
+		prev = id
+	}
+}
+
+func TestNodeIDSequenceCarry(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	n, err := r.WithNodeID(0xA5, 8)
+	require.NoError(t, err)
+
+	// Start the sequence with the bits below the node ID at their maximum, so the next ID of the
+	// same millisecond carries into the node ID
+	start := append([]byte{0x00}, bytes.Repeat([]byte{0xFF}, 9)...)
+	n.gen.shards[0].entropy = ulid.Monotonic(bytes.NewReader(start), 1)
+
+	now := time.Now()
+	first, err := n.GenerateAt(now)
+	require.NoError(t, err)
+	second, err := n.GenerateAt(now)
+	require.NoError(t, err)
+	third, err := n.GenerateAt(now)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Less(t, second, third, "the restarted sequence is monotonic")
+	for _, id := range []string{first, second, third} {
+		node, err := n.ExtractNodeID(id)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0xA5), node)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package rigid
+
+const raceEnabled = false
@@ -0,0 +1,149 @@
+package rigid
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	// ErrInvalidGranularity indicates a granularity passed to WithTimestampObfuscation is out of range.
+	ErrInvalidGranularity = errors.New("timestamp granularity out of range")
+	// ErrNoObfuscation indicates a timestamp was decoded by an instance configured without
+	// WithTimestampObfuscation.
+	ErrNoObfuscation = errors.New("timestamp obfuscation not configured")
+)
+
+// Bounds of the granularity of obfuscated timestamps.
+const (
+	MinObfuscationGranularity = 2 * time.Millisecond
+	MaxObfuscationGranularity = 1 << 32 * time.Millisecond
+)
+
+// obfuscationRounds is the number of Feistel rounds of the timestamp permutation.
+const obfuscationRounds = 8
+
+// timestampCipher permutes the milliseconds within each period of granularity ms with a keyed
+// Feistel network, using a distinct permutation for each period.
+type timestampCipher struct {
+	granularity uint64
+	half        uint // bits in each half of the Feistel network
+	block       cipher.Block
+}
+
+// WithTimestampObfuscation returns a copy of r hiding the exact creation time of the IDs it
+// generates. Timestamps are kept to the period of the given granularity, truncated to whole
+// milliseconds and counted from the Unix epoch, and the millisecond within the period is encrypted with a key derived from the secret
+// key. IDs of different periods still sort by time, IDs within a period sort randomly, and only
+// DecodeTimestamp, with the secret key, recovers the creation time.
+// Verification, including TTL and timestamp bound checks, and VerifyResult.Timestamp use the
+// timestamp as it appears in the ID, which lies in the same period as the creation time.
+// The returned instance shares the secret key and entropy source with r.
+// Returns ErrInvalidGranularity if granularity is not between MinObfuscationGranularity and
+// MaxObfuscationGranularity.
+func (r *Rigid) WithTimestampObfuscation(granularity time.Duration) (*Rigid, error) {
+	if granularity < MinObfuscationGranularity || granularity > MaxObfuscationGranularity {
+		return nil, ErrInvalidGranularity
+	}
+	block, err := aes.NewCipher(hkdf(r.secretKey, formatDomain+"timestamp"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := uint64(granularity.Milliseconds())
+	c := r.clone()
+	c.obfuscation = &timestampCipher{
+		granularity: g,
+		half:        uint(bits.Len64(g-1)+1) / 2,
+		block:       block,
+	}
+	return c, nil
+}
+
+// DecodeTimestamp verifies id and returns its creation time, as obfuscated by an instance
+// configured with WithTimestampObfuscation with the same granularity. The ID is verified like
+// Verify, without consulting the revocation and replay stores. Returns ErrNoObfuscation if r does
+// not obfuscate timestamps, and ErrNoTimestamp if r generates unordered IDs.
+func (r *Rigid) DecodeTimestamp(id string) (time.Time, error) {
+	if r.obfuscation == nil {
+		return time.Time{}, ErrNoObfuscation
+	}
+	if r.unordered {
+		return time.Time{}, ErrNoTimestamp
+	}
+	result, err := r.verify(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ulidObj, err := ulid.ParseStrict(result.ULID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ulid.Time(r.obfuscation.decode(ulidObj.Time())), nil
+}
+
+// timestampGranularity returns the granularity of the timestamps obfuscated by r, or zero.
+func (r *Rigid) timestampGranularity() time.Duration {
+	if r.obfuscation == nil {
+		return 0
+	}
+	return time.Duration(r.obfuscation.granularity) * time.Millisecond
+}
+
+// obscure returns the timestamp to generate an ID created at t with. Times outside the ULID range,
+// or in a period reaching beyond it, are left for the generator to reject or keep.
+func (c *timestampCipher) obscure(t time.Time) time.Time {
+	ms := t.UnixMilli()
+	if ms < 0 || uint64(ms)/c.granularity >= ulid.MaxTime()/c.granularity {
+		return t
+	}
+	return ulid.Time(c.encode(uint64(ms)))
+}
+
+// encode returns the obfuscated form of the Unix millisecond timestamp ms.
+func (c *timestampCipher) encode(ms uint64) uint64 {
+	period, offset := ms/c.granularity, ms%c.granularity
+	// Cycle walking keeps the permutation within the period
+	for offset = c.permute(period, offset, false); offset >= c.granularity; {
+		offset = c.permute(period, offset, false)
+	}
+	return period*c.granularity + offset
+}
+
+// decode inverts encode.
+func (c *timestampCipher) decode(ms uint64) uint64 {
+	period, offset := ms/c.granularity, ms%c.granularity
+	for offset = c.permute(period, offset, true); offset >= c.granularity; {
+		offset = c.permute(period, offset, true)
+	}
+	return period*c.granularity + offset
+}
+
+// permute applies the Feistel network of period to x, or its inverse.
+func (c *timestampCipher) permute(period, x uint64, inverse bool) uint64 {
+	mask := uint64(1)<<c.half - 1
+	left, right := x>>c.half, x&mask
+	for i := range obfuscationRounds {
+		if inverse {
+			left, right = right^c.round(obfuscationRounds-1-i, period, left), left
+		} else {
+			left, right = right, left^c.round(i, period, right)
+		}
+	}
+	return left<<c.half | right
+}
+
+// round is the round function of the Feistel network.
+func (c *timestampCipher) round(i int, period, x uint64) uint64 {
+	var b [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(b[0:], period)
+	b[0] = byte(i) // periods fit in 48 bits
+	binary.BigEndian.PutUint64(b[8:], x)
+	c.block.Encrypt(b[:], b[:])
+	return binary.BigEndian.Uint64(b[:]) & (1<<c.half - 1)
+}
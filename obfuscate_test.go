@@ -0,0 +1,93 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampCipherPermutation(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, g := range []time.Duration{2 * time.Millisecond, 7 * time.Millisecond, time.Second} {
+		o, err := r.WithTimestampObfuscation(g)
+		require.NoError(t, err)
+		c := o.obfuscation
+
+		period := uint64(1_700_000_000_000) / c.granularity
+		start := period * c.granularity
+		seen := make(map[uint64]bool)
+		for ms := start; ms < start+c.granularity; ms++ {
+			enc := c.encode(ms)
+			assert.GreaterOrEqual(t, enc, start, "granularity %s", g)
+			assert.Less(t, enc, start+c.granularity, "granularity %s", g)
+			assert.Equal(t, ms, c.decode(enc))
+			seen[enc] = true
+		}
+		assert.Len(t, seen, int(c.granularity), "granularity %s", g)
+	}
+}
+
+func TestWithTimestampObfuscation(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	o, err := r.WithTimestampObfuscation(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, o.Config().TimestampGranularity)
+
+	created := time.Date(2024, 5, 1, 12, 34, 56, 789_000_000, time.UTC)
+	moved := 0
+	for i := range 50 {
+		at := created.Add(time.Duration(i) * time.Minute)
+		id, err := o.GenerateAt(at, "user:alice")
+		require.NoError(t, err)
+
+		result, err := r.Verify(id)
+		require.NoError(t, err, "obfuscated IDs verify without the obfuscation")
+		assert.True(t, at.Truncate(time.Hour).Equal(result.Timestamp.Truncate(time.Hour)), "the period is kept")
+		if !result.Timestamp.Equal(at) {
+			moved++
+		}
+
+		decoded, err := o.DecodeTimestamp(id)
+		require.NoError(t, err)
+		assert.True(t, at.Equal(decoded), "decoded %s, created %s", decoded, at)
+	}
+	assert.Greater(t, moved, 40, "the millisecond within the period is hidden")
+
+	later, err := o.GenerateAt(created.Add(time.Hour))
+	require.NoError(t, err)
+	earlier, err := o.GenerateAt(created)
+	require.NoError(t, err)
+	assert.Less(t, earlier, later, "IDs of different periods sort by time")
+}
+
+func TestDecodeTimestampErrors(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, g := range []time.Duration{0, time.Millisecond, MaxObfuscationGranularity + time.Millisecond} {
+		_, err := r.WithTimestampObfuscation(g)
+		assert.ErrorIs(t, err, ErrInvalidGranularity, "granularity %s", g)
+	}
+
+	o, err := r.WithTimestampObfuscation(time.Minute)
+	require.NoError(t, err)
+	id, err := o.Generate()
+	require.NoError(t, err)
+
+	_, err = r.DecodeTimestamp(id)
+	assert.ErrorIs(t, err, ErrNoObfuscation)
+	_, err = o.DecodeTimestamp(id[:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+	other, err = other.WithTimestampObfuscation(time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, o.obfuscation.encode(1_700_000_000_000), other.obfuscation.encode(1_700_000_000_000),
+		"the permutation depends on the secret key")
+}
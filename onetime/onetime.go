@@ -0,0 +1,221 @@
+// Package onetime issues the tokens of one-time action links, such as password reset, email
+// verification and unsubscribe links, built on rigid.
+//
+// A token is a rigid ID whose type prefix names its purpose and whose signed metadata names the
+// subject it acts on, so a token issued for one purpose is rejected for any other. The presets
+// combine a lifetime, single use through a rigid.ReplayStore and a minimum signature length
+// suited to each purpose:
+//
+//	resets, err := onetime.NewPasswordReset(r, store)
+//	token, err := resets.Issue(onetime.Claims{Subject: userID, Binding: passwordHash})
+//	...
+//	t, err := resets.Redeem(ctx, token, passwordHash)
+package onetime
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// MinSignatureLength is the signature length in bytes tokens are issued with at least, whatever
+// the signature length of the instance passed to New.
+const MinSignatureLength = 16
+
+// bindingLength is the number of hex digits of the binding digest signed into tokens.
+const bindingLength = 32
+
+// Errors returned by Manager operations, in addition to those returned by rigid verification.
+var (
+	// ErrBindingMismatch indicates the token is bound to a different value than the one presented.
+	ErrBindingMismatch = errors.New("onetime: binding does not match")
+	// ErrInvalidClaims indicates the token claims are missing or malformed.
+	ErrInvalidClaims = errors.New("onetime: invalid claims")
+	// ErrNoReplayStore indicates a single-use purpose was configured without a replay store.
+	ErrNoReplayStore = errors.New("onetime: single-use tokens need a replay store")
+)
+
+// Purpose describes a kind of one-time action.
+type Purpose struct {
+	// Prefix is the type prefix of the tokens, which must be ASCII alphanumeric. Tokens are
+	// only accepted by managers of the same prefix.
+	Prefix string
+	// TTL is the lifetime of the tokens. It must be positive.
+	TTL time.Duration
+	// SingleUse makes tokens redeemable once, which requires a replay store.
+	SingleUse bool
+}
+
+// Presets of common one-time actions. Password reset and email verification links are
+// single-use; unsubscribe links are not, since unsubscribing twice is harmless, mail scanners
+// follow links ahead of the recipient, and the link must keep working for weeks.
+var (
+	PasswordReset     = Purpose{Prefix: "pwreset", TTL: 30 * time.Minute, SingleUse: true}
+	EmailVerification = Purpose{Prefix: "emailverify", TTL: 24 * time.Hour, SingleUse: true}
+	Unsubscribe       = Purpose{Prefix: "unsub", TTL: 60 * 24 * time.Hour}
+)
+
+// Claims are the contents of a token, signed into it.
+type Claims struct {
+	// Subject identifies the user or address the action applies to. It is required, and
+	// visible to anyone holding the token.
+	Subject string
+	// Binding is a value the token is only redeemable against, such as the email address being
+	// verified or the current password hash, so the token dies once the value changes. Only a
+	// keyed digest of it is signed into the token. Empty tokens are not bound.
+	Binding string
+}
+
+// Token is a verified token.
+type Token struct {
+	// ID is the token handed out in the link.
+	ID string
+	// Subject is the subject of the claims the token was issued for.
+	Subject string
+	// IssuedAt is the time the token was issued.
+	IssuedAt time.Time
+	// ExpiresAt is the time the token expires.
+	ExpiresAt time.Time
+}
+
+type config struct {
+	ttl time.Duration
+}
+
+// Option configures a Manager.
+type Option func(*config)
+
+// WithTTL overrides the lifetime of the purpose's tokens.
+func WithTTL(d time.Duration) Option {
+	return func(c *config) {
+		c.ttl = d
+	}
+}
+
+// Manager issues and redeems the tokens of one purpose. It is safe for concurrent use.
+type Manager struct {
+	r         *rigid.Rigid
+	store     rigid.ReplayStore
+	singleUse bool
+}
+
+// New returns a Manager issuing tokens for p signed by r, recording redeemed single-use tokens
+// in store, which may be nil for purposes that are not single-use.
+// Returns ErrNoReplayStore if p is single-use and store is nil, rigid.ErrInvalidPrefix if the
+// prefix of p is empty or not ASCII alphanumeric, or an error if the TTL is not positive.
+func New(r *rigid.Rigid, p Purpose, store rigid.ReplayStore, opts ...Option) (*Manager, error) {
+	c := config{ttl: p.TTL}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.ttl <= 0 {
+		return nil, errors.New("onetime: TTL must be positive")
+	}
+	if p.SingleUse && store == nil {
+		return nil, ErrNoReplayStore
+	}
+	if p.Prefix == "" {
+		return nil, rigid.ErrInvalidPrefix
+	}
+
+	r, err := r.WithPrefix(p.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if r.Config().SignatureLength < MinSignatureLength {
+		if r, err = r.WithSignatureLength(MinSignatureLength); err != nil {
+			return nil, err
+		}
+	}
+	return &Manager{r: r.WithTTL(c.ttl), store: store, singleUse: p.SingleUse}, nil
+}
+
+// NewPasswordReset returns a Manager for the PasswordReset preset.
+func NewPasswordReset(r *rigid.Rigid, store rigid.ReplayStore, opts ...Option) (*Manager, error) {
+	return New(r, PasswordReset, store, opts...)
+}
+
+// NewEmailVerification returns a Manager for the EmailVerification preset.
+func NewEmailVerification(r *rigid.Rigid, store rigid.ReplayStore, opts ...Option) (*Manager, error) {
+	return New(r, EmailVerification, store, opts...)
+}
+
+// NewUnsubscribe returns a Manager for the Unsubscribe preset.
+func NewUnsubscribe(r *rigid.Rigid, opts ...Option) (*Manager, error) {
+	return New(r, Unsubscribe, nil, opts...)
+}
+
+// Issue returns a new token carrying claims.
+func (m *Manager) Issue(claims Claims) (string, error) {
+	if claims.Subject == "" {
+		return "", fmt.Errorf("%w: missing subject", ErrInvalidClaims)
+	}
+
+	b := rigid.Meta().Str("sub", claims.Subject)
+	if claims.Binding != "" {
+		b.Str("bind", m.digest(claims.Binding))
+	}
+	return m.r.Generate(b.String())
+}
+
+// Check verifies token against binding without redeeming it, such as to render the form a
+// link leads to before the action is confirmed. Pass the empty string for tokens issued
+// without a binding. Tokens bound to another value are rejected with ErrBindingMismatch, and
+// expired tokens with rigid.ErrExpired.
+func (m *Manager) Check(ctx context.Context, token, binding string) (Token, error) {
+	result, err := m.r.VerifyContext(ctx, token)
+	if err != nil {
+		return Token{}, err
+	}
+
+	claims, err := result.Meta()
+	if err != nil || claims.Str("sub") == "" {
+		return Token{}, ErrInvalidClaims
+	}
+	if bound := claims.Str("bind"); bound != "" || binding != "" {
+		if subtle.ConstantTimeCompare([]byte(bound), []byte(m.digest(binding))) != 1 {
+			return Token{}, ErrBindingMismatch
+		}
+	}
+
+	return Token{
+		ID:        token,
+		Subject:   claims.Str("sub"),
+		IssuedAt:  result.Timestamp,
+		ExpiresAt: result.ExpiresAt,
+	}, nil
+}
+
+// Redeem verifies token like Check and, for single-use purposes, consumes it, rejecting
+// tokens redeemed before with rigid.ErrReplayed. Tokens failing any check are not consumed.
+func (m *Manager) Redeem(ctx context.Context, token, binding string) (Token, error) {
+	t, err := m.Check(ctx, token, binding)
+	if err != nil || !m.singleUse {
+		return t, err
+	}
+
+	u, err := m.r.ExtractULID(token)
+	if err != nil {
+		return Token{}, err
+	}
+	// The entry outlives the token by a second so that a token about to expire is never
+	// stored without expiry.
+	first, err := m.store.MarkUsed(ctx, u.String(), time.Until(t.ExpiresAt)+time.Second)
+	if err != nil {
+		return Token{}, err
+	}
+	if !first {
+		return Token{}, rigid.ErrReplayed
+	}
+	return t, nil
+}
+
+// digest returns the digest of binding signed into tokens.
+func (m *Manager) digest(binding string) string {
+	return m.r.MetadataHash(binding)[:bindingLength]
+}
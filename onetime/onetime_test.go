@@ -0,0 +1,128 @@
+package onetime
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newRigid(t *testing.T) *rigid.Rigid {
+	t.Helper()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	return r
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	store := rigid.NewMemoryStore(0)
+	defer store.Close()
+	m, err := NewPasswordReset(newRigid(t), store)
+	require.NoError(t, err)
+
+	token, err := m.Issue(Claims{Subject: "alice", Binding: "$argon2id$old"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "pwreset_"))
+	assert.NotContains(t, token, "argon2id", "only a digest of the binding is signed into the token")
+
+	parts, err := rigid.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, MinSignatureLength, parts.SignatureLength)
+
+	// Checking does not consume the token
+	for range 2 {
+		tok, err := m.Check(ctx, token, "$argon2id$old")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", tok.Subject)
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), tok.ExpiresAt, time.Second)
+	}
+
+	_, err = m.Redeem(ctx, token, "$argon2id$new")
+	assert.ErrorIs(t, err, ErrBindingMismatch)
+	_, err = m.Redeem(ctx, token, "")
+	assert.ErrorIs(t, err, ErrBindingMismatch)
+
+	tok, err := m.Redeem(ctx, token, "$argon2id$old")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", tok.Subject)
+	_, err = m.Redeem(ctx, token, "$argon2id$old")
+	assert.ErrorIs(t, err, rigid.ErrReplayed)
+}
+
+func TestPurposeSeparation(t *testing.T) {
+	ctx := context.Background()
+	store := rigid.NewMemoryStore(0)
+	defer store.Close()
+	r := newRigid(t)
+
+	verify, err := NewEmailVerification(r, store)
+	require.NoError(t, err)
+	unsub, err := NewUnsubscribe(r)
+	require.NoError(t, err)
+
+	token, err := verify.Issue(Claims{Subject: "alice", Binding: "alice@example.com"})
+	require.NoError(t, err)
+	_, err = unsub.Redeem(ctx, token, "alice@example.com")
+	assert.ErrorIs(t, err, rigid.ErrInvalidFormat)
+
+	// Unsubscribe links may be followed several times
+	token, err = unsub.Issue(Claims{Subject: "alice@example.com"})
+	require.NoError(t, err)
+	for range 2 {
+		tok, err := unsub.Redeem(ctx, token, "")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", tok.Subject)
+	}
+	_, err = unsub.Redeem(ctx, token, "alice@example.com")
+	assert.ErrorIs(t, err, ErrBindingMismatch, "unbound tokens do not satisfy a binding")
+}
+
+func TestExpiredToken(t *testing.T) {
+	store := rigid.NewMemoryStore(0)
+	defer store.Close()
+	m, err := NewEmailVerification(newRigid(t), store, WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	token, err := m.Issue(Claims{Subject: "alice"})
+	require.NoError(t, err)
+	old, err := m.r.GenerateAt(time.Now().Add(-2*time.Minute), "sub:alice")
+	require.NoError(t, err)
+
+	_, err = m.Redeem(context.Background(), old, "")
+	assert.ErrorIs(t, err, rigid.ErrExpired)
+	_, err = m.Redeem(context.Background(), token, "")
+	assert.NoError(t, err)
+}
+
+func TestNewErrors(t *testing.T) {
+	r := newRigid(t)
+
+	_, err := NewPasswordReset(r, nil)
+	assert.ErrorIs(t, err, ErrNoReplayStore)
+	_, err = New(r, Purpose{TTL: time.Hour}, nil)
+	assert.ErrorIs(t, err, rigid.ErrInvalidPrefix)
+	_, err = New(r, Purpose{Prefix: "invite"}, nil)
+	assert.Error(t, err)
+	_, err = NewUnsubscribe(r, WithTTL(-time.Hour))
+	assert.Error(t, err)
+
+	m, err := NewUnsubscribe(r)
+	require.NoError(t, err)
+	_, err = m.Issue(Claims{})
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+
+	// Tokens without a subject are rejected even when correctly signed
+	token, err := m.r.Generate("bind:x")
+	require.NoError(t, err)
+	_, err = m.Check(context.Background(), token, "")
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+}
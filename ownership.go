@@ -0,0 +1,70 @@
+package rigid
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// ErrInvalidProof indicates a proof of ownership does not match the ID and challenge.
+var ErrInvalidProof = errors.New("invalid proof of ownership")
+
+// ChallengeSize is the size in bytes of the challenges returned by NewChallenge.
+const ChallengeSize = 32
+
+// OwnershipKey verifies id and returns its ownership key, a key derived from the secret key for
+// this ID alone. Hand it to the party the ID is issued to, over the same channel as the ID, so
+// it can later prove holding it with ProveOwnership: an ID copied from a log or a URL lacks the
+// key. The key reveals nothing about the secret key or the keys of other IDs.
+// The ID is verified like Verify, without consulting the revocation and replay stores.
+func (r *Rigid) OwnershipKey(id string) ([]byte, error) {
+	if _, err := r.verify(id); err != nil {
+		return nil, err
+	}
+	return r.ownershipKey(id), nil
+}
+
+// NewChallenge returns ChallengeSize random bytes read from crypto/rand, for the verifier to send
+// to the party proving ownership. Challenges must be used once, and only for a short time.
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, ChallengeSize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ProveOwnership returns the proof that the holder of the ownership key of id, as returned by
+// OwnershipKey, answers challenge with. It needs no secret key, so it runs on the side of the
+// holder.
+func ProveOwnership(key []byte, id string, challenge []byte) string {
+	mac := hmac.New(sha256.New, key)
+	input := appendField([]byte(formatDomain+"proof"), id)
+	mac.Write(appendField(input, string(challenge)))
+	return signatureEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyProof verifies id and checks that proof answers challenge with the ownership key of id,
+// as computed by ProveOwnership. The ID is verified like Verify, without consulting the
+// revocation and replay stores. Returns the verification error of id, or ErrInvalidProof.
+func (r *Rigid) VerifyProof(id string, challenge []byte, proof string) (VerifyResult, error) {
+	result, err := r.verify(id)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	expected := ProveOwnership(r.ownershipKey(id), id, challenge)
+	if subtle.ConstantTimeCompare([]byte(proof), []byte(expected)) != 1 {
+		return VerifyResult{}, ErrInvalidProof
+	}
+	return result, nil
+}
+
+// ownershipKey derives the ownership key of id.
+func (r *Rigid) ownershipKey(id string) []byte {
+	mac := hmac.New(sha256.New, hkdf(r.macKey, formatDomain+"ownership"))
+	mac.Write([]byte(id))
+	return mac.Sum(nil)
+}
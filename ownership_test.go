@@ -0,0 +1,64 @@
+package rigid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveOwnership(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("device:1")
+	require.NoError(t, err)
+
+	key, err := r.OwnershipKey(id)
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	challenge, err := NewChallenge()
+	require.NoError(t, err)
+	assert.Len(t, challenge, ChallengeSize)
+
+	proof := ProveOwnership(key, id, challenge)
+	result, err := r.VerifyProof(id, challenge, proof)
+	require.NoError(t, err)
+	assert.Equal(t, "device:1", result.Metadata)
+
+	// A proof answers one challenge for one ID
+	other, err := NewChallenge()
+	require.NoError(t, err)
+	_, err = r.VerifyProof(id, other, proof)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	id2, err := r.Generate("device:1")
+	require.NoError(t, err)
+	_, err = r.VerifyProof(id2, challenge, proof)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+	_, err = r.VerifyProof(id2, challenge, ProveOwnership(key, id2, challenge))
+	assert.ErrorIs(t, err, ErrInvalidProof, "keys are bound to their ID")
+
+	key2, err := r.OwnershipKey(id2)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, key2)
+
+	// Keys are derived from the signing key
+	_, err = r.WithNamespace("other").VerifyProof(id, challenge, proof)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+}
+
+func TestOwnershipKeyInvalidID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("device:1")
+	require.NoError(t, err)
+	forged := id[:len(id)-1] + "2"
+
+	_, err = r.OwnershipKey(forged)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.VerifyProof(forged, []byte("challenge"), "")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.OwnershipKey("not an id")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
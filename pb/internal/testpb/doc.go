@@ -0,0 +1,4 @@
+// Package testpb contains messages used to test rigid ID validation in package pb.
+package testpb
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative pb/internal/testpb/test.proto
@@ -0,0 +1,129 @@
+// Package pb provides Protocol Buffers support for rigid IDs.
+//
+// It contains the RigidID message, conversion helpers between RigidID and rigid.ID,
+// and the (rigid.v1.id) field option for declaring string fields that hold rigid IDs:
+//
+//	import "pb/rigid.proto";
+//
+//	message GetOrderRequest {
+//	  string order_id = 1 [(rigid.v1.id) = true];
+//	  rigid.v1.RigidID parent_id = 2;
+//	}
+//
+// Validate checks every annotated field and RigidID message in a request, in the spirit of
+// protoc-gen-validate but without a code generation step. Checks follow rigid.ID: a structural
+// check by default, or full signature verification once rigid.SetDefaultVerifier has been called.
+//
+// RigidService defines Generate, Verify and Inspect RPCs, letting services in any language
+// delegate signing to a central issuer; package server implements it.
+package pb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative pb/rigid.proto
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// New returns a RigidID message holding id.
+func New(id rigid.ID) *RigidID {
+	return &RigidID{Value: string(id)}
+}
+
+// ID returns the rigid ID held by x. A nil message yields the empty ID.
+func (x *RigidID) ID() rigid.ID {
+	return rigid.ID(x.GetValue())
+}
+
+// Validate checks the rigid ID held by x. A nil message or an empty value is invalid.
+func (x *RigidID) Validate() error {
+	if x.GetValue() == "" {
+		return rigid.ErrInvalidFormat
+	}
+	return x.ID().Validate()
+}
+
+// Validate checks all rigid IDs in msg: RigidID messages and string fields annotated with
+// (rigid.v1.id), including repeated fields, map values, and nested messages.
+// Empty annotated strings are treated as absent and skipped.
+// The returned error names the offending field and wraps the rigid validation error.
+func Validate(msg proto.Message) error {
+	if msg == nil {
+		return nil
+	}
+	return validateMessage(msg.ProtoReflect(), "")
+}
+
+func validateMessage(m protoreflect.Message, path string) error {
+	if x, ok := m.Interface().(*RigidID); ok {
+		if err := x.Validate(); err != nil {
+			return fieldError(path, err)
+		}
+		return nil
+	}
+
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		err = validateField(fd, v, joinPath(path, string(fd.Name())))
+		return err == nil
+	})
+	return err
+}
+
+func validateField(fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	annotated := proto.GetExtension(fd.Options(), E_Id).(bool)
+
+	switch {
+	case fd.IsList():
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			if err := validateValue(fd, list.Get(i), annotated, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case fd.IsMap():
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			err = validateValue(fd.MapValue(), mv, annotated, fmt.Sprintf("%s[%v]", path, k.Interface()))
+			return err == nil
+		})
+		return err
+	default:
+		return validateValue(fd, v, annotated, path)
+	}
+	return nil
+}
+
+func validateValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, annotated bool, path string) error {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return validateMessage(v.Message(), path)
+	case protoreflect.StringKind:
+		if annotated {
+			if id := rigid.ID(v.String()); id != "" {
+				if err := id.Validate(); err != nil {
+					return fieldError(path, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func fieldError(path string, err error) error {
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
@@ -0,0 +1,91 @@
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/pb"
+	"github.com/bahadrix/rigid-go/pb/internal/testpb"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestRigidIDConversion(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	generated, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	msg := pb.New(rigid.ID(generated))
+	assert.Equal(t, rigid.ID(generated), msg.ID())
+	assert.NoError(t, msg.Validate())
+
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	var decoded pb.RigidID
+	require.NoError(t, proto.Unmarshal(data, &decoded))
+	assert.Equal(t, rigid.ID(generated), decoded.ID())
+
+	var nilMsg *pb.RigidID
+	assert.Empty(t, nilMsg.ID())
+	assert.Equal(t, rigid.ErrInvalidFormat, nilMsg.Validate())
+	assert.Equal(t, rigid.ErrInvalidULID, pb.New("bad-id").Validate())
+}
+
+func TestValidate(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id := func() string {
+		generated, err := r.Generate()
+		require.NoError(t, err)
+		return generated
+	}
+
+	valid := &testpb.Order{
+		OrderId: id(),
+		Parent:  pb.New(rigid.ID(id())),
+		Refs:    []string{id(), id()},
+		Note:    "not an id",
+		Items:   []*testpb.Item{{ItemId: id()}, {}},
+		Labels:  map[string]string{"origin": id()},
+	}
+	assert.NoError(t, pb.Validate(valid))
+	assert.NoError(t, pb.Validate(&testpb.Order{}))
+
+	tests := map[string]*testpb.Order{
+		"order_id":         {OrderId: "bad-id"},
+		"parent":           {Parent: &pb.RigidID{}},
+		"refs[1]":          {Refs: []string{id(), "bad-id"}},
+		"items[0].item_id": {Items: []*testpb.Item{{ItemId: "bad-id"}}},
+		"labels[origin]":   {Labels: map[string]string{"origin": "bad-id"}},
+	}
+
+	for path, msg := range tests {
+		err := pb.Validate(msg)
+		require.Error(t, err, path)
+		assert.Contains(t, err.Error(), path+": ")
+	}
+}
+
+func TestValidateWithDefaultVerifier(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	other, err := rigid.NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	forged, err := other.Generate()
+	require.NoError(t, err)
+
+	rigid.SetDefaultVerifier(r)
+	defer rigid.SetDefaultVerifier(nil)
+
+	err = pb.Validate(&testpb.Order{OrderId: forged})
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
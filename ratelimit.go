@@ -0,0 +1,119 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited indicates the caller verifying an ID has exceeded its rate limit.
+var ErrRateLimited = errors.New("verification rate limit exceeded")
+
+// RateLimiter throttles verifications per caller, so signatures cannot be guessed by brute force,
+// which matters most for short signatures. Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	// Allow reports whether caller may verify another ID now, consuming one unit of its allowance.
+	Allow(ctx context.Context, caller string) (bool, error)
+}
+
+// WithRateLimiter returns a copy of r consulting l before every verification whose context
+// carries a caller, see ContextWithCaller, or consulting no limiter if l is nil. Verifications
+// that are not allowed fail with ErrRateLimited without checking the ID; verifications without
+// a caller are not limited. The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithRateLimiter(l RateLimiter) *Rigid {
+	c := r.clone()
+	c.limiter = l
+	return c
+}
+
+// allow consults r's rate limiter for the caller of ctx, if any.
+func (r *Rigid) allow(ctx context.Context) error {
+	if r.limiter == nil {
+		return nil
+	}
+	caller, ok := CallerFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	allowed, err := r.limiter.Allow(ctx, caller)
+	if err != nil {
+		return fmt.Errorf("check rate limit: %w", err)
+	}
+	if !allowed {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// maxIdleBuckets is the number of callers a MemoryRateLimiter tracks before it drops those whose
+// allowance has fully recovered.
+const maxIdleBuckets = 4096
+
+// MemoryRateLimiter is an in-memory RateLimiter giving each caller a token bucket. It suits
+// single-instance deployments; use a shared implementation to limit callers across instances.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryRateLimiter returns a MemoryRateLimiter allowing each caller perSecond verifications
+// per second on average, and bursts of up to burst verifications.
+func NewMemoryRateLimiter(perSecond float64, burst int) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		rate:    perSecond,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow implements RateLimiter.
+func (l *MemoryRateLimiter) Allow(_ context.Context, caller string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[caller]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[caller] = b
+	}
+
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false, nil
+	}
+	b.tokens--
+	return true, nil
+}
+
+// refill returns the tokens of b at now.
+func (l *MemoryRateLimiter) refill(b *bucket, now time.Time) float64 {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		return min(l.burst, b.tokens+elapsed*l.rate)
+	}
+	return b.tokens
+}
+
+// prune drops the buckets that are full at now, which behave as new buckets would.
+func (l *MemoryRateLimiter) prune(now time.Time) {
+	for caller, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, caller)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimiter(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	limited := r.WithRateLimiter(NewMemoryRateLimiter(0, 2))
+	alice := ContextWithCaller(context.Background(), "alice")
+	bob := ContextWithCaller(context.Background(), "bob")
+
+	// Failed and successful verifications both use up the allowance
+	_, err = limited.VerifyContext(alice, id[:27]+"AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = limited.VerifyContext(alice, id)
+	assert.NoError(t, err)
+	result, err := limited.VerifyContext(alice, id)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, VerifyResult{}, result)
+	assert.Equal(t, uint64(1), limited.Stats().FailuresByReason[ReasonRateLimited])
+
+	// Other callers and verifications without a caller are not affected
+	_, err = limited.VerifyContext(bob, id)
+	assert.NoError(t, err)
+	_, err = limited.Verify(id)
+	assert.NoError(t, err)
+
+	// The instance the limiter was set on is unaffected
+	_, err = r.VerifyContext(alice, id)
+	assert.NoError(t, err)
+	_, err = limited.WithRateLimiter(nil).VerifyContext(alice, id)
+	assert.NoError(t, err)
+}
+
+// limiterFunc adapts a function to a RateLimiter.
+type limiterFunc func(ctx context.Context, caller string) (bool, error)
+
+func (f limiterFunc) Allow(ctx context.Context, caller string) (bool, error) { return f(ctx, caller) }
+
+func TestWithRateLimiterError(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	unavailable := errors.New("limiter unavailable")
+	limited := r.WithRateLimiter(limiterFunc(func(context.Context, string) (bool, error) {
+		return false, unavailable
+	}))
+
+	_, err = limited.VerifyContext(ContextWithCaller(context.Background(), "alice"), id)
+	assert.ErrorIs(t, err, unavailable)
+	assert.NotErrorIs(t, err, ErrRateLimited)
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	l := NewMemoryRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	allow := func(caller string) bool {
+		ok, err := l.Allow(ctx, caller)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// A burst, then the rate
+	for range 3 {
+		assert.True(t, allow("alice"))
+	}
+	assert.False(t, allow("alice"))
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, allow("alice"))
+	assert.False(t, allow("alice"))
+
+	// Allowances recover up to the burst only
+	now = now.Add(time.Hour)
+	for range 3 {
+		assert.True(t, allow("alice"))
+	}
+	assert.False(t, allow("alice"))
+}
+
+func TestMemoryRateLimiterPrune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	l := NewMemoryRateLimiter(1, 1)
+	l.now = func() time.Time { return now }
+
+	for i := range maxIdleBuckets {
+		_, err := l.Allow(ctx, string(rune(i)))
+		require.NoError(t, err)
+	}
+	require.Len(t, l.buckets, maxIdleBuckets)
+
+	// Once recovered, idle callers are dropped to make room
+	now = now.Add(time.Second)
+	_, err := l.Allow(ctx, "new")
+	require.NoError(t, err)
+	assert.Len(t, l.buckets, 1)
+}
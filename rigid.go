@@ -37,7 +37,7 @@
 // - HMAC-SHA256 cryptographic signatures prevent tampering and forgery
 // - Constant-time verification resists timing attacks
 // - Configurable signature lengths (4-32 bytes) for security/size trade-offs
-// - Thread-safe concurrent generation with monotonic entropy
+// - Thread-safe concurrent generation with monotonic entropy, optionally sharded across CPUs
 //
 // # ID Format
 //
@@ -45,6 +45,8 @@
 //
 // Example: 01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA-user:session:12345
 //
+// Instances derived with WithPrefix prepend a signed type prefix: PREFIX_ULID-SIGNATURE.
+//
 // # Compatibility
 //
 // This implementation is compatible with the Python rigid library when using
@@ -52,17 +54,31 @@
 package rigid
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
+	cryptorand "crypto/rand"
 	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/base32"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"math/rand"
+	"reflect"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
 
 	"github.com/oklog/ulid/v2"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Error variables returned by rigid operations.
@@ -73,10 +89,32 @@ var (
 	ErrInvalidULID = errors.New("invalid ULID")
 	// ErrIntegrityFailure indicates the signature verification failed.
 	ErrIntegrityFailure = errors.New("integrity verification failed")
+	// ErrSignatureLengthMismatch indicates the signature has a length produced by a different
+	// signature length setting than the verifier's. It wraps ErrIntegrityFailure.
+	ErrSignatureLengthMismatch = fmt.Errorf("%w: signature length mismatch", ErrIntegrityFailure)
 	// ErrEmptySecretKey indicates the provided secret key is empty or nil.
 	ErrEmptySecretKey = errors.New("secret key cannot be empty")
 	// ErrInvalidSigLength indicates the signature length is outside valid range.
 	ErrInvalidSigLength = errors.New("signature length must be positive")
+	// ErrInvalidPrefix indicates the ID prefix contains characters other than ASCII letters and digits.
+	ErrInvalidPrefix = errors.New("prefix must contain only ASCII letters and digits")
+	// ErrInvalidMetadata indicates the metadata could not be decoded or is not acceptable.
+	ErrInvalidMetadata = errors.New("invalid metadata")
+	// ErrRevoked indicates the ID has been revoked.
+	ErrRevoked = errors.New("rigid ID has been revoked")
+	// ErrReplayed indicates a single-use ID has already been used.
+	ErrReplayed = errors.New("rigid ID has already been used")
+	// ErrExpired indicates the ID is older than the lifetime configured with WithTTL.
+	ErrExpired = errors.New("rigid ID has expired")
+	// ErrNoRevocationStore indicates a revocation was requested without a configured store.
+	ErrNoRevocationStore = errors.New("no revocation store configured")
+	// ErrInvalidFormatVersion indicates an unknown format version.
+	ErrInvalidFormatVersion = errors.New("unknown format version")
+	// ErrTimestampOutOfRange indicates the timestamp of an ID lies outside the bounds set with
+	// WithTimestampBounds or too far in the future for WithMaxClockSkew.
+	ErrTimestampOutOfRange = errors.New("rigid ID timestamp out of range")
+	// ErrTooLong indicates an ID, or the ID to be generated, exceeds the instance's maximum length.
+	ErrTooLong = errors.New("rigid ID exceeds maximum length")
 )
 
 // Constants defining signature length constraints.
@@ -87,6 +125,8 @@ const (
 	MinSignatureLength = 4
 	// MaxSignatureLength is the maximum allowed signature length in bytes.
 	MaxSignatureLength = 32
+	// DefaultMaxLength is the default maximum length of an ID in bytes, see WithMaxLength.
+	DefaultMaxLength = 1024
 )
 
 // Rigid is the main structure for generating and verifying cryptographically secured ULIDs.
@@ -94,19 +134,244 @@ const (
 // All methods are thread-safe for concurrent use.
 type Rigid struct {
 	secretKey       []byte
+	keyID           string // the fingerprint of secretKey, see KeyID
+	issuer          string
+	trust           *TrustPolicy
+	namespace       string
+	boundContext    []byte // the context bound with WithContext, or nil
+	macKey          []byte // the key of macs, derived from secretKey for namespaces and contexts
 	signatureLength int
-	entropy         *ulid.MonotonicEntropy
-	mu              sync.Mutex
+	prefix          string
+	strict          bool
+	printable       bool
+	normalize       bool
+	maxLength       int
+	version         FormatVersion
+	legacy          bool
+	ttl             time.Duration
+	notBefore       time.Time
+	notAfter        time.Time
+	maxSkew         time.Duration
+	revocations     RevocationStore
+	replays         ReplayStore
+	replayTTL       time.Duration
+	onFailure       FailureHook
+	hooks           *hooks
+	audit           *auditor
+	issues          *issueRecorder
+	limiter         RateLimiter
+	bursts          *burstDetector
+	obfuscation     *timestampCipher
+	unordered       bool
+	stats           *stats
+	gen             *generator
+	macs            *sync.Pool
+	tagMACs         *sync.Pool // keyed for FormatV3 signatures of signatureLength bytes
+	buckets         *keyBuckets
+}
+
+// generator hands out ULIDs from one or more monotonic entropy shards.
+// It is shared between a Rigid instance and the instances derived from it.
+type generator struct {
+	shards []entropyShard
+	node   nodeID
+	state  *stateKeeper
+	source *failoverEntropy
+	next   atomic.Uint32
+}
+
+// entropyShard is a monotonic entropy source and the mutex serializing access to it.
+type entropyShard struct {
+	mu      sync.Mutex
+	random  io.Reader
+	entropy *ulid.MonotonicEntropy
+	scratch [10]byte
+	node    nodeID
+	// With a node ID, the millisecond, and the entropy bits the node ID replaces, of the last read
+	seq  bool
+	ms   uint64
+	high uint32
+	// Keep shards on separate cache lines, so goroutines using different shards do not contend
+	_ [64]byte
+}
+
+// newGenerator returns a generator with n entropy shards, each seeded independently, embedding
+// node in the ULIDs it hands out. A non-nil state keeps its timestamps after those of earlier runs.
+// A non-nil source supplies all entropy directly, see WithEntropySource.
+func newGenerator(n int, node nodeID, state *stateKeeper, source *failoverEntropy) *generator {
+	g := &generator{shards: make([]entropyShard, n), node: node, state: state, source: source}
+	for i := range g.shards {
+		var random io.Reader = source
+		if source == nil {
+			random = rand.New(rand.NewSource(newSeed()))
+		}
+		g.shards[i].random = random
+		g.shards[i].entropy = ulid.Monotonic(random, 0)
+		g.shards[i].node = node
+	}
+	return g
+}
+
+// newSeed returns a random seed for an entropy shard. Seeds are drawn from crypto/rand rather than
+// derived from the clock, so shards of generators created at the same instant do not share sequences.
+// It panics if crypto/rand fails, rather than generating guessable IDs.
+func newSeed() int64 {
+	var b [8]byte
+	if _, err := cryptorand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("rigid: %v: %v", ErrEntropyUnavailable, err))
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// newULID returns a ULID with timestamp t, monotonically increasing within each millisecond
+// for IDs drawn from the same shard. With several shards, the first idle one is used.
+func (g *generator) newULID(t time.Time) (ulid.ULID, error) {
+	ms := ulid.Timestamp(t)
+	if g.state != nil {
+		var err error
+		if ms, err = g.state.reserve(ms); err != nil {
+			return ulid.ULID{}, err
+		}
+	}
+
+	s := &g.shards[0]
+	if n := uint32(len(g.shards)); n > 1 {
+		start := g.next.Add(1) % n
+		for i := range n {
+			if c := &g.shards[(start+i)%n]; c.mu.TryLock() {
+				defer c.mu.Unlock()
+				return c.newULID(ms)
+			}
+		}
+		// All shards are busy; wait for the first one tried
+		s = &g.shards[start]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newULID(ms)
+}
+
+// newULID returns a ULID with the Unix millisecond timestamp ms from the shard's sequence.
+// s.mu must be held.
+func (s *entropyShard) newULID(ms uint64) (ulid.ULID, error) {
+	var id ulid.ULID
+	if err := id.SetTime(ms); err != nil {
+		return id, err
+	}
+
+	// Reading into the shard rather than through ulid.New keeps id off the heap
+	err := s.read(ms)
+	if errors.Is(err, ulid.ErrMonotonicOverflow) {
+		// The sequence of this millisecond is exhausted. Restart it from fresh entropy:
+		// IDs stay unique and only lose their ordering relative to earlier IDs of the millisecond.
+		s.entropy = ulid.Monotonic(s.random, 0)
+		s.seq = false
+		err = s.read(ms)
+	}
+	if err != nil {
+		return id, err
+	}
+
+	copy(id[6:], s.scratch[:])
+	s.node.embed(id[6:])
+	return id, nil
+}
+
+// read reads the next entropy of millisecond ms into s.scratch. With a node ID, the sequence is
+// also exhausted once it carries into the bits the node ID replaces. s.mu must be held.
+func (s *entropyShard) read(ms uint64) error {
+	if err := s.entropy.MonotonicRead(ms, s.scratch[:]); err != nil || s.node.bits == 0 {
+		return err
+	}
+	high := s.node.extract(s.scratch[:])
+	carried := s.seq && s.ms == ms && s.high != high
+	s.seq, s.ms, s.high = true, ms, high
+	if carried {
+		return ulid.ErrMonotonicOverflow
+	}
+	return nil
 }
 
 // VerifyResult contains the results of a rigid ID verification operation.
 type VerifyResult struct {
 	// Valid indicates whether the rigid ID passed integrity verification.
 	Valid bool
-	// ULID contains the extracted ULID string.
+	// ULID contains the extracted ULID in its canonical upper-case encoding. Verify accepts
+	// non-canonical encodings, such as lower case, unless the instance is strict, and reports
+	// them here as the ULID they decode to, so they do not pass for distinct IDs downstream.
 	ULID string
 	// Metadata contains the extracted metadata string, if any.
 	Metadata string
+	// Timestamp contains the creation time embedded in the ULID.
+	Timestamp time.Time
+	// ExpiresAt is the time the ID expires, or the zero time if the instance has no TTL.
+	ExpiresAt time.Time
+	// InGracePeriod indicates the ID has expired but was accepted within the grace period of
+	// WithGracePeriod.
+	InGracePeriod bool
+	// Issuer is the issuer of the ID, or empty if it carries none, see WithIssuer.
+	Issuer string
+	// KeyID is the key ID of the key the signature was verified with, see Rigid.KeyID: the key of
+	// the instance, or the key a TrustPolicy holds for the issuer of the ID. It tells which key
+	// accepted an ID during a key rotation, and is empty unless Valid is set.
+	KeyID string
+}
+
+// verifyResultJSON is the JSON representation of VerifyResult.
+type verifyResultJSON struct {
+	Valid         bool       `json:"valid"`
+	ULID          string     `json:"ulid"`
+	Metadata      string     `json:"metadata"`
+	Timestamp     *time.Time `json:"timestamp,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	InGracePeriod bool       `json:"in_grace_period,omitempty"`
+	Issuer        string     `json:"issuer,omitempty"`
+	KeyID         string     `json:"key_id,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler with stable snake_case field names:
+//
+//	{"valid":true,"ulid":"01ARZ3NDEKTSV4RRFFQ69G5FAV","metadata":"user:alice","timestamp":"2016-07-30T23:54:10.259Z"}
+//
+// Times are encoded in UTC and omitted when zero, so expires_at only appears for instances with a TTL.
+func (v VerifyResult) MarshalJSON() ([]byte, error) {
+	j := verifyResultJSON{Valid: v.Valid, ULID: v.ULID, Metadata: v.Metadata, InGracePeriod: v.InGracePeriod, Issuer: v.Issuer, KeyID: v.KeyID}
+	if !v.Timestamp.IsZero() {
+		t := v.Timestamp.UTC()
+		j.Timestamp = &t
+	}
+	if !v.ExpiresAt.IsZero() {
+		t := v.ExpiresAt.UTC()
+		j.ExpiresAt = &t
+	}
+
+	// Leave HTML escaping to the caller's encoder, which applies it to marshaler output as configured.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(j); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the representation produced by MarshalJSON.
+// It decodes the fields as they are and does not verify anything.
+func (v *VerifyResult) UnmarshalJSON(data []byte) error {
+	var j verifyResultJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*v = VerifyResult{Valid: j.Valid, ULID: j.ULID, Metadata: j.Metadata, InGracePeriod: j.InGracePeriod, Issuer: j.Issuer, KeyID: j.KeyID}
+	if j.Timestamp != nil {
+		v.Timestamp = *j.Timestamp
+	}
+	if j.ExpiresAt != nil {
+		v.ExpiresAt = *j.ExpiresAt
+	}
+	return nil
 }
 
 // NewRigid creates a new Rigid instance with the provided secret key.
@@ -118,24 +383,235 @@ func NewRigid(secretKey []byte, signatureLength ...int) (*Rigid, error) {
 		return nil, ErrEmptySecretKey
 	}
 
-	sigLen := DefaultSignatureLength
-	if len(signatureLength) > 0 {
-		sigLen = signatureLength[0]
-		if sigLen < MinSignatureLength || sigLen > MaxSignatureLength {
-			return nil, ErrInvalidSigLength
-		}
+	sigLen, err := signatureLengthArg(signatureLength)
+	if err != nil {
+		return nil, err
 	}
 
-	entropy := ulid.Monotonic(rand.New(rand.NewSource(time.Now().UnixNano())), 0)
+	return newRigid(append([]byte(nil), secretKey...), sigLen), nil
+}
+
+// signatureLengthArg returns the signature length passed as the optional argument of NewRigid.
+func signatureLengthArg(signatureLength []int) (int, error) {
+	if len(signatureLength) == 0 {
+		return DefaultSignatureLength, nil
+	}
+	sigLen := signatureLength[0]
+	if sigLen < MinSignatureLength || sigLen > MaxSignatureLength {
+		return 0, ErrInvalidSigLength
+	}
+	return sigLen, nil
+}
 
-	r := &Rigid{
-		secretKey:       make([]byte, len(secretKey)),
+// newRigid returns an instance with the default settings using key, which it takes ownership of.
+func newRigid(key []byte, sigLen int) *Rigid {
+	return &Rigid{
+		secretKey:       key,
+		keyID:           keyID(key),
+		macKey:          key,
 		signatureLength: sigLen,
-		entropy:         entropy,
+		version:         FormatV1,
+		maxLength:       DefaultMaxLength,
+		gen:             newGenerator(1, nodeID{}, nil, nil),
+		macs:            newMACPool(key),
+		stats:           newStats(),
 	}
-	copy(r.secretKey, secretKey)
+}
+
+// WithStrict returns a copy of r that only accepts IDs in the exact form produced by Generate.
+// Strict verification rejects non-canonical ULID encodings (lowercase or substituted characters),
+// signatures outside the upper-case base32 alphabet, and empty trailing metadata segments.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithStrict() *Rigid {
+	c := r.clone()
+	c.strict = true
+	return c
+}
+
+// WithPrintableMetadata returns a copy of r that only accepts metadata made of printable
+// UTF-8 characters: Generate and Verify reject metadata containing control characters such as
+// newlines, invalid UTF-8, or other non-printable characters with ErrInvalidMetadata, so
+// metadata can be written to logs and headers without escaping. Spaces are allowed.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithPrintableMetadata() *Rigid {
+	c := r.clone()
+	c.printable = true
+	return c
+}
+
+// WithNormalizedMetadata returns a copy of r that normalizes metadata to Unicode Normalization
+// Form C (NFC) before signing and verifying it. Generated IDs carry NFC metadata, and IDs whose
+// metadata was decomposed (NFD) by a normalizing system on the way still verify, reporting the
+// NFC form in VerifyResult.Metadata. Verifiers must normalize too, as the signature covers the NFC
+// form. The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithNormalizedMetadata() *Rigid {
+	c := r.clone()
+	c.normalize = true
+	return c
+}
+
+// WithMaxLength returns a copy of r that generates and accepts IDs of at most n bytes, including
+// prefix and metadata. Longer IDs are rejected with ErrTooLong before any signature is computed,
+// so oversized input cannot force large amounts of work in Verify. Instances start with
+// DefaultMaxLength; a zero or negative n removes the limit.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithMaxLength(n int) *Rigid {
+	c := r.clone()
+	c.maxLength = max(n, 0)
+	return c
+}
+
+// WithSignatureLength returns a copy of r producing and accepting signatures of n bytes.
+// The returned instance shares the secret key and entropy source with r, so no key material is copied.
+// Returns ErrInvalidSigLength if n is outside MinSignatureLength..MaxSignatureLength.
+func (r *Rigid) WithSignatureLength(n int) (*Rigid, error) {
+	if n < MinSignatureLength || n > MaxSignatureLength {
+		return nil, ErrInvalidSigLength
+	}
+
+	c := r.clone()
+	c.signatureLength = n
+	c.deriveTagKey()
+	return c, nil
+}
+
+// WithPrefix returns a copy of r whose IDs carry a type prefix, rendered as PREFIX_ULID-SIGNATURE.
+// The prefix is covered by the signature, so an ID cannot be relabeled as another type,
+// and Verify only accepts IDs carrying exactly this prefix. An empty prefix removes it.
+// The returned instance shares the secret key and entropy source with r.
+// Returns ErrInvalidPrefix if p contains characters other than ASCII letters and digits.
+func (r *Rigid) WithPrefix(p string) (*Rigid, error) {
+	if !validPrefix(p) {
+		return nil, ErrInvalidPrefix
+	}
+
+	c := r.clone()
+	c.prefix = qualifyPrefix(r.issuer, p)
+	return c, nil
+}
+
+// WithTTL returns a copy of r whose IDs expire d after the time embedded in their ULID.
+// Verification rejects expired IDs with ErrExpired and reports the expiry in VerifyResult.ExpiresAt.
+// The expiry is derived from the signed timestamp, so it cannot be extended by the holder of an ID.
+// A zero or negative d removes the expiry. The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithTTL(d time.Duration) *Rigid {
+	c := r.clone()
+	c.ttl = max(d, 0)
+	return c
+}
+
+// WithTimestampBounds returns a copy of r that rejects IDs whose timestamp lies before notBefore or
+// after notAfter with ErrTimestampOutOfRange, even when their signature is valid, so IDs with
+// fabricated timestamps cannot reach time-ordered storage. A zero bound leaves that side unbounded.
+// Bounds only apply to verification. The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithTimestampBounds(notBefore, notAfter time.Time) *Rigid {
+	c := r.clone()
+	c.notBefore, c.notAfter = notBefore, notAfter
+	return c
+}
 
-	return r, nil
+// WithMaxClockSkew returns a copy of r that rejects IDs whose timestamp is more than d ahead of the
+// current time with ErrTimestampOutOfRange, allowing for clock differences between the hosts
+// generating and verifying IDs. A zero or negative d removes the check.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithMaxClockSkew(d time.Duration) *Rigid {
+	c := r.clone()
+	c.maxSkew = max(d, 0)
+	return c
+}
+
+// FailureHook is called with every ID that fails verification, the error, and the remote party
+// presenting it as stored in the context by ContextWithRemote, or nil.
+type FailureHook func(id string, err error, remote any)
+
+// WithFailureHook returns a copy of r that calls h whenever verification fails, whether for a
+// forged signature, an invalid format, expiry or a revocation, so failures can be fed to audit
+// logs or a SIEM without wrapping every call site. h is called synchronously and must be safe
+// for concurrent use; a nil h removes the hook. The returned instance shares the secret key and
+// entropy source with r.
+func (r *Rigid) WithFailureHook(h FailureHook) *Rigid {
+	c := r.clone()
+	c.onFailure = h
+	return c
+}
+
+// WithEntropyShards returns a copy of r generating ULIDs from n independent entropy sources,
+// so concurrent Generate calls do not contend on a single mutex. If n is zero or negative,
+// runtime.GOMAXPROCS(0) shards are used.
+// IDs stay unique, but IDs generated within the same millisecond are only ordered relative to
+// IDs from the same shard, so use a single shard when strict monotonic ordering matters.
+// Unlike the other With methods, the returned instance does not share its entropy source with r.
+func (r *Rigid) WithEntropyShards(n int) *Rigid {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	c := r.clone()
+	c.gen = newGenerator(n, r.gen.node, r.gen.state, r.gen.source)
+	return c
+}
+
+// WithFormatVersion returns a copy of r generating and verifying IDs in format version v.
+// IDs of one version do not verify under another, so existing deployments migrating to
+// FormatV2 should verify with WithLegacyVerification until their FormatV1 IDs are retired.
+// The returned instance shares the secret key and entropy source with r.
+// Returns ErrInvalidFormatVersion if v is not a known version.
+func (r *Rigid) WithFormatVersion(v FormatVersion) (*Rigid, error) {
+	if !v.valid() {
+		return nil, ErrInvalidFormatVersion
+	}
+
+	c := r.clone()
+	c.version = v
+	c.deriveTagKey()
+	return c, nil
+}
+
+// WithLegacyVerification returns a copy of r that also accepts FormatV1 signatures when
+// verifying, whatever its format version. IDs are still generated in r's format version.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithLegacyVerification() *Rigid {
+	c := r.clone()
+	c.legacy = true
+	return c
+}
+
+// FormatVersion returns the format version of the IDs generated by r.
+func (r *Rigid) FormatVersion() FormatVersion {
+	return r.version
+}
+
+// TTL returns the lifetime of the IDs verified by r, or zero if they do not expire.
+func (r *Rigid) TTL() time.Duration {
+	return r.ttl
+}
+
+// KeyID returns a short fingerprint of the secret key, for telling keys apart in logs and
+// keystores without revealing them. Instances sharing a key share the key ID.
+func (r *Rigid) KeyID() string {
+	return r.keyID
+}
+
+// keyID returns the fingerprint of secret reported by KeyID.
+func keyID(secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("rigid key id"))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// deriveTagKey prepares the FormatV3 key for r's signature length, if r uses FormatV3, and the
+// key buckets for r's key.
+func (r *Rigid) deriveTagKey() {
+	r.buckets = r.buckets.rekeyed()
+	r.tagMACs = nil
+	if r.version == FormatV3 {
+		r.tagMACs = newMACPool(tagKey(r.macKey, r.signatureLength))
+	}
+}
+
+// clone returns a shallow copy of r sharing its secret key and entropy source.
+func (r *Rigid) clone() *Rigid {
+	c := *r
+	return &c
 }
 
 // Generate creates a new cryptographically secured ULID with optional metadata.
@@ -143,82 +619,336 @@ func NewRigid(secretKey []byte, signatureLength ...int) (*Rigid, error) {
 // Only the first metadata parameter is used if multiple are provided.
 // Returns the generated rigid ID string or an error if generation fails.
 func (r *Rigid) Generate(metadata ...string) (string, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	return r.GenerateAt(time.Now(), metadata...)
+}
 
-	now := time.Now()
-	ulidObj, err := ulid.New(ulid.Timestamp(now), r.entropy)
-	if err != nil {
+// GenerateAt creates a cryptographically secured ULID whose timestamp is t instead of the current time.
+// It is intended for backfills and migrations that must preserve original creation times.
+// The optional metadata parameter behaves as in Generate.
+// Returns an error if t cannot be represented in a ULID (before the Unix epoch or beyond year 10889).
+func (r *Rigid) GenerateAt(t time.Time, metadata ...string) (string, error) {
+	var metadataStr string
+	if len(metadata) > 0 {
+		metadataStr = metadata[0]
+	}
+
+	start := time.Now()
+	b := make([]byte, 0, EncodedLen(len(r.prefix), r.signatureLength, len(metadataStr)))
+	b, err := r.appendID(b, t, metadataStr)
+	var id string
+	if err == nil {
+		// b is not retained, so it backs the returned string without a copy, as in strings.Builder
+		id = unsafe.String(unsafe.SliceData(b), len(b))
+	}
+	if err = r.observeGenerate(start, id, err); err != nil {
 		return "", err
 	}
+	return id, nil
+}
 
-	ulidStr := ulidObj.String()
+// AppendGenerate appends a new rigid ID to dst and returns the extended buffer, like Generate
+// but without allocating when dst has enough capacity; see EncodedLen for the required size.
+// It is intended for hot paths producing large numbers of IDs into reused buffers.
+func (r *Rigid) AppendGenerate(dst []byte, metadata ...string) ([]byte, error) {
+	var metadataStr string
+	if len(metadata) > 0 {
+		metadataStr = metadata[0]
+	}
+
+	start := time.Now()
+	b, err := r.appendID(dst, start, metadataStr)
+	var id string
+	if err == nil && r.generateObserved() {
+		id = string(b[len(dst):])
+	}
+	if err = r.observeGenerate(start, id, err); err != nil {
+		return dst, err
+	}
+	return b, nil
+}
 
+// Sign returns the rigid ID of an existing ULID with optional metadata, for systems that generate
+// ULIDs themselves and for test vectors. Only the first metadata parameter is used. Signing is
+// counted, audited and reported to hooks like Generate.
+func (r *Rigid) Sign(u ulid.ULID, metadata ...string) (string, error) {
 	var metadataStr string
 	if len(metadata) > 0 {
 		metadataStr = metadata[0]
 	}
 
-	signature := r.generateSignature(ulidStr, metadataStr)
+	start := time.Now()
+	var id string
+	metadataStr, err := r.prepareMetadata(metadataStr)
+	if err == nil {
+		var b []byte
+		b, err = r.appendSigned(make([]byte, 0, EncodedLen(len(r.prefix), r.signatureLength, len(metadataStr))), u, metadataStr)
+		id = string(b)
+	}
+	if err = r.observeGenerate(start, id, err); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// StripSignature verifies id like Verify and returns its ULID and metadata, the inverse of Sign,
+// for handing IDs received at a boundary to code that works with ulid.ULID values.
+// Returns the verification error if id is not valid.
+func (r *Rigid) StripSignature(id string) (ulid.ULID, string, error) {
+	result, err := r.Verify(id)
+	if err != nil {
+		return ulid.ULID{}, "", err
+	}
+	u, err := ulid.ParseStrict(result.ULID)
+	if err != nil {
+		return ulid.ULID{}, "", ErrInvalidULID
+	}
+	return u, result.Metadata, nil
+}
+
+// appendID appends an ID with a new ULID of timestamp t and the given metadata to dst.
+func (r *Rigid) appendID(dst []byte, t time.Time, metadata string) ([]byte, error) {
+	metadata, err := r.prepareMetadata(metadata)
+	if err != nil {
+		return dst, err
+	}
 
-	result := ulidStr + "-" + signature
-	if metadataStr != "" {
-		result += "-" + metadataStr
+	ulidObj, err := r.newULID(t)
+	if err != nil {
+		return dst, err
 	}
+	return r.appendSigned(dst, ulidObj, metadata)
+}
 
-	return result, nil
+// newULID returns the ULID of an ID created at t, as configured for r.
+func (r *Rigid) newULID(t time.Time) (ulid.ULID, error) {
+	var ulidObj ulid.ULID
+	if r.unordered {
+		if r.gen.source != nil {
+			return r.gen.source.readULID()
+		}
+		_, err := cryptorand.Read(ulidObj[:])
+		return ulidObj, err
+	}
+	if r.obfuscation != nil {
+		t = r.obfuscation.obscure(t)
+	}
+	return r.gen.newULID(t)
+}
+
+// prepareMetadata returns metadata as it is signed, checking that it can be generated.
+func (r *Rigid) prepareMetadata(metadata string) (string, error) {
+	if r.normalize {
+		metadata = norm.NFC.String(metadata)
+	}
+	if r.printable && !printable(metadata) {
+		return "", ErrInvalidMetadata
+	}
+	if r.maxLength > 0 && EncodedLen(len(r.prefix), r.signatureLength, len(metadata)) > r.maxLength {
+		return "", ErrTooLong
+	}
+	return metadata, nil
+}
+
+// appendSigned appends the ID of ulidObj with the prepared metadata to dst.
+func (r *Rigid) appendSigned(dst []byte, ulidObj ulid.ULID, metadata string) ([]byte, error) {
+	var ulidText [ulid.EncodedSize]byte
+	if err := ulidObj.MarshalTextTo(ulidText[:]); err != nil {
+		return dst, err
+	}
+
+	pool := r.macPool(r.version)
+	if r.buckets != nil {
+		pool = r.buckets.pool(r, r.version, r.buckets.current())
+	}
+	mac := pool.Get().(*macState)
+	defer putMAC(pool, mac)
+	signature := mac.signature(r.version, r.prefix, string(ulidText[:]), metadata, r.signatureLength)
+
+	dst = appendPrefix(dst, r.prefix)
+	dst = append(dst, ulidText[:]...)
+	dst = append(dst, '-')
+	dst = append(dst, signature...)
+	if metadata != "" {
+		dst = append(dst, '-')
+		dst = append(dst, metadata...)
+	}
+	return dst, nil
 }
 
 // Verify checks the integrity and authenticity of a rigid ID.
 // Returns a VerifyResult containing validation status, extracted ULID, and metadata.
-// Returns an error if the ID format is invalid or verification fails.
-func (r *Rigid) Verify(secureULID string) (VerifyResult, error) {
+// Returns an error if the ID format is invalid or verification fails, or if the ID is rejected
+// by the configured revocation or replay store (see VerifyContext). Options such as WithMaxAge
+// tighten the checks of this call only.
+func (r *Rigid) Verify(secureULID string, opts ...VerifyOption) (VerifyResult, error) {
+	return r.VerifyContext(context.Background(), secureULID, opts...)
+}
+
+// VerifyBytes verifies a rigid ID held in a byte slice, like Verify but without converting
+// the slice to a string first. Rejected IDs cause no allocation unless a failure hook, hooks or an audit sink are set;
+// for accepted IDs, the ULID and metadata of the result are copied out of id, which may be
+// reused once VerifyBytes returns.
+func (r *Rigid) VerifyBytes(id []byte) (VerifyResult, error) {
+	start := time.Now()
+	// id is only read for the duration of verify, whose errors do not reference it
+	result, err := r.verify(unsafe.String(unsafe.SliceData(id), len(id)))
+	if err == nil {
+		// Copy the ID once and point the result into the copy. A ULID that was canonicalized
+		// or metadata that was normalized is already a copy.
+		seg, _ := splitID(string(id))
+		if result.ULID == seg.ulid {
+			result.ULID = seg.ulid
+		}
+		if result.Metadata == seg.metadata {
+			result.Metadata = seg.metadata
+		}
+		if result.Issuer != "" {
+			result.Issuer = seg.prefix[:len(result.Issuer)]
+		}
+
+		result, err = r.checkStores(context.Background(), result)
+	}
+	var idStr string
+	if r.verifyObserved() {
+		idStr = string(id)
+	}
+	r.observeVerify(context.Background(), start, idStr, result, err)
+	return result, err
+}
+
+// verify checks the format and signature of secureULID.
+func (r *Rigid) verify(secureULID string) (VerifyResult, error) {
+	result, _, err := r.verifySegments(secureULID, true)
+	return result, err
+}
+
+// verifySegments checks the format of secureULID, and its signature if checkSignature is set.
+// On failure it also returns the check that failed, one of the Check constants, for Explain.
+func (r *Rigid) verifySegments(secureULID string, checkSignature bool) (VerifyResult, string, error) {
 	result := VerifyResult{}
 
-	parts := strings.Split(secureULID, "-")
-	if len(parts) < 2 {
-		return result, ErrInvalidFormat
+	if r.maxLength > 0 && len(secureULID) > r.maxLength {
+		return result, CheckLength, ErrTooLong
 	}
 
-	ulidStr := parts[0]
-	signature := parts[1]
-	var metadata string
-	if len(parts) > 2 {
-		metadata = strings.Join(parts[2:], "-")
+	seg, err := splitID(secureULID)
+	if err != nil {
+		return result, CheckFormat, err
 	}
 
-	if _, err := ulid.Parse(ulidStr); err != nil {
-		return result, ErrInvalidULID
+	if seg.prefix != r.prefix {
+		if err := r.checkIssuer(seg.prefix); err != nil {
+			return result, CheckPrefix, err
+		}
 	}
 
-	expectedSignature := r.generateSignature(ulidStr, metadata)
+	// Normalizing returns the metadata itself when it already is in NFC, without allocating
+	metadata := seg.metadata
+	if r.normalize {
+		metadata = norm.NFC.String(metadata)
+	}
+	if r.printable && !printable(metadata) {
+		return result, CheckMetadata, ErrInvalidMetadata
+	}
 
-	if len(signature) != len(expectedSignature) {
-		return result, ErrIntegrityFailure
+	var ulidObj ulid.ULID
+	if r.strict {
+		ulidObj, err = checkStrict(seg)
+	} else if ulidObj, err = ulid.ParseStrict(seg.ulid); err != nil {
+		err = ErrInvalidULID
+	}
+	if err == ErrInvalidULID {
+		return result, CheckULID, err
+	} else if err != nil {
+		return result, CheckFormat, err
 	}
 
-	if subtle.ConstantTimeCompare([]byte(signature), []byte(expectedSignature)) != 1 {
-		return result, ErrIntegrityFailure
+	// The signature length is not secret, so a mismatch is reported before any MAC work
+	if len(seg.signature) != signatureEncoding.EncodedLen(r.signatureLength) {
+		if validSignatureLength(len(seg.signature)) {
+			return result, CheckSignatureLength, ErrSignatureLengthMismatch
+		}
+		return result, CheckSignatureLength, ErrIntegrityFailure
+	}
+
+	if checkSignature {
+		ok := r.signatureMatches(r.version, seg, metadata)
+		if !ok && r.legacy && r.version != FormatV1 {
+			ok = r.signatureMatches(FormatV1, seg, metadata)
+		}
+		if !ok {
+			return result, CheckSignature, ErrIntegrityFailure
+		}
+		result.Valid = true
+		result.KeyID = r.keyID
+		if seg.prefix != r.prefix {
+			result.KeyID = r.trust.keyID(issuerOf(seg.prefix))
+		}
+	} else if !validSignatureChars(seg.signature) {
+		return result, CheckSignature, ErrInvalidFormat
 	}
 
-	result.Valid = true
-	result.ULID = ulidStr
+	result.ULID = canonicalULID(ulidObj, seg.ulid)
 	result.Metadata = metadata
+	result.Issuer = issuerOf(seg.prefix)
+	if r.unordered {
+		return result, "", nil
+	}
+	result.Timestamp = ulid.Time(ulidObj.Time())
 
-	return result, nil
+	now := time.Now()
+	if (!r.notBefore.IsZero() && result.Timestamp.Before(r.notBefore)) ||
+		(!r.notAfter.IsZero() && result.Timestamp.After(r.notAfter)) ||
+		(r.maxSkew > 0 && result.Timestamp.After(now.Add(r.maxSkew))) {
+		return VerifyResult{}, CheckTimestamp, ErrTimestampOutOfRange
+	}
+
+	if r.ttl > 0 {
+		result.ExpiresAt = result.Timestamp.Add(r.ttl)
+		if !now.Before(result.ExpiresAt) {
+			return VerifyResult{}, CheckExpiry, ErrExpired
+		}
+	}
+
+	return result, "", nil
+}
+
+// VerifyInto verifies a rigid ID and decodes its metadata into dst.
+// If dst implements encoding.TextUnmarshaler the raw metadata is passed to it. If dst points to
+// a struct with rigid-tagged fields, the metadata is decoded into them as MetaOf encodes them,
+// and every field not tagged omitempty must be present. Otherwise the metadata is decoded as JSON,
+// typically into a pointer to a claims struct.
+// Returns the verification error, or an error wrapping ErrInvalidMetadata if decoding fails.
+func (r *Rigid) VerifyInto(secureULID string, dst any) error {
+	result, err := r.Verify(secureULID)
+	if err != nil {
+		return err
+	}
+
+	if u, ok := dst.(encoding.TextUnmarshaler); ok {
+		err = u.UnmarshalText([]byte(result.Metadata))
+	} else if rv := reflect.ValueOf(dst); rv.Kind() == reflect.Pointer && !rv.IsNil() && hasClaimTags(rv.Type().Elem()) {
+		err = unmarshalClaims(result.Metadata, rv)
+	} else {
+		err = json.Unmarshal([]byte(result.Metadata), dst)
+	}
+	if err != nil && !errors.Is(err, ErrInvalidMetadata) {
+		err = fmt.Errorf("%w: %w", ErrInvalidMetadata, err)
+	}
+	return err
 }
 
 // ExtractULID extracts and parses the ULID component from a rigid ID.
-// Returns the parsed ULID object or an error if extraction fails.
+// Any type prefix is skipped. Returns the parsed ULID object or an error if extraction fails.
 func (r *Rigid) ExtractULID(secureULID string) (ulid.ULID, error) {
 	var zeroULID ulid.ULID
 
-	parts := strings.Split(secureULID, "-")
-	if len(parts) < 2 {
-		return zeroULID, ErrInvalidFormat
+	seg, err := splitID(secureULID)
+	if err != nil {
+		return zeroULID, err
 	}
 
-	ulidObj, err := ulid.Parse(parts[0])
+	ulidObj, err := ulid.ParseStrict(seg.ulid)
 	if err != nil {
 		return zeroULID, ErrInvalidULID
 	}
@@ -227,8 +957,12 @@ func (r *Rigid) ExtractULID(secureULID string) (ulid.ULID, error) {
 }
 
 // ExtractTimestamp extracts the timestamp from the ULID component of a rigid ID.
-// Returns the embedded timestamp or an error if extraction fails.
+// Returns the embedded timestamp or an error if extraction fails, and ErrNoTimestamp if r
+// generates unordered IDs.
 func (r *Rigid) ExtractTimestamp(secureULID string) (time.Time, error) {
+	if r.unordered {
+		return time.Time{}, ErrNoTimestamp
+	}
 	ulidObj, err := r.ExtractULID(secureULID)
 	if err != nil {
 		return time.Time{}, err
@@ -237,15 +971,107 @@ func (r *Rigid) ExtractTimestamp(secureULID string) (time.Time, error) {
 	return ulid.Time(ulidObj.Time()), nil
 }
 
-func (r *Rigid) generateSignature(ulidStr, metadata string) string {
-	h := hmac.New(sha256.New, r.secretKey)
-	h.Write([]byte(ulidStr))
-	if metadata != "" {
-		h.Write([]byte(metadata))
+// segments holds the parts of a rigid ID as they appear in its string form.
+type segments struct {
+	prefix    string
+	ulid      string
+	signature string
+	metadata  string
+	// hasMetadata reports whether a metadata segment was present, even if empty.
+	hasMetadata bool
+}
+
+// splitID splits a rigid ID into its prefix, ULID, signature, and metadata segments.
+// Metadata may itself contain hyphens, but the signature segment may not be empty. The prefix is everything before the last
+// underscore of the first segment, which cannot occur inside a ULID.
+func splitID(secureULID string) (segments, error) {
+	var seg segments
+
+	head, rest, ok := strings.Cut(secureULID, "-")
+	if !ok {
+		return seg, ErrInvalidFormat
+	}
+
+	seg.ulid = head
+	if i := strings.LastIndexByte(head, '_'); i >= 0 {
+		if i == 0 {
+			return seg, ErrInvalidFormat
+		}
+		seg.prefix, seg.ulid = head[:i], head[i+1:]
 	}
 
-	sum := h.Sum(nil)
-	truncated := sum[:r.signatureLength]
+	seg.signature, seg.metadata, seg.hasMetadata = strings.Cut(rest, "-")
+	if seg.signature == "" {
+		return seg, ErrInvalidFormat
+	}
+	return seg, nil
+}
+
+// printable reports whether s is valid UTF-8 made only of printable characters and spaces.
+func printable(s string) bool {
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c < ' ' || c == 0x7f {
+				return false
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !unicode.IsPrint(r) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
 
-	return strings.ToUpper(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(truncated))
+// signatureMatches reports whether seg, with its metadata replaced by metadata, carries the
+// signature r computes in format version v.
+func (r *Rigid) signatureMatches(v FormatVersion, seg segments, metadata string) bool {
+	pool := r.macPool(v)
+	if seg.prefix != r.prefix {
+		// The ID carries the prefix of another issuer, which passed checkIssuer
+		if pool = r.trust.macPool(issuerOf(seg.prefix), v, r.signatureLength); pool == nil {
+			return false
+		}
+	} else if r.buckets != nil {
+		return r.bucketSignatureMatches(v, seg, metadata)
+	}
+	mac := pool.Get().(*macState)
+	defer putMAC(pool, mac)
+	return equalSignature(seg.signature, mac.signature(v, seg.prefix, seg.ulid, metadata, r.signatureLength))
+}
+
+// canonicalULID returns the canonical encoding of u, which was parsed from s.
+// It returns s itself when s is already canonical, so the common case does not allocate.
+func canonicalULID(u ulid.ULID, s string) string {
+	var canonical [ulid.EncodedSize]byte
+	if u.MarshalTextTo(canonical[:]) != nil || string(canonical[:]) == s {
+		return s
+	}
+	return string(canonical[:])
+}
+
+// checkStrict enforces the canonical encoding rules used by strict verification.
+// It returns the parsed ULID on success.
+func checkStrict(seg segments) (ulid.ULID, error) {
+	ulidObj, err := ulid.ParseStrict(seg.ulid)
+	if err != nil {
+		return ulid.ULID{}, ErrInvalidULID
+	}
+	var canonical [ulid.EncodedSize]byte
+	if ulidObj.MarshalTextTo(canonical[:]) != nil || string(canonical[:]) != seg.ulid {
+		return ulid.ULID{}, ErrInvalidULID
+	}
+
+	if seg.hasMetadata && seg.metadata == "" {
+		return ulid.ULID{}, ErrInvalidFormat
+	}
+
+	if !validSignatureChars(seg.signature) {
+		return ulid.ULID{}, ErrInvalidFormat
+	}
+
+	return ulidObj, nil
 }
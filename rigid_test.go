@@ -1,7 +1,16 @@
 package rigid
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -102,6 +111,11 @@ func TestVerifyValid(t *testing.T) {
 
 	parts := strings.Split(rigid, "-")
 	assert.Equal(t, parts[0], result.ULID)
+
+	timestamp, err := r.ExtractTimestamp(rigid)
+	require.NoError(t, err)
+	assert.True(t, timestamp.Equal(result.Timestamp))
+	assert.False(t, result.Timestamp.IsZero())
 }
 
 func TestVerifyWithMetadata(t *testing.T) {
@@ -270,26 +284,20 @@ func TestConcurrentGeneration(t *testing.T) {
 
 	for i := 0; i < goroutines; i++ {
 		wg.Add(1)
-		go func(goroutineID int) {
+		go func() {
 			defer wg.Done()
 			for j := 0; j < idsPerGoroutine; j++ {
-				// Add small delay to prevent monotonic entropy overflow
-				time.Sleep(time.Microsecond * time.Duration(goroutineID*10+j))
-
 				rigid, err := r.Generate()
 
 				mu.Lock()
 				if err != nil {
-					// Only fail on unexpected errors, not entropy overflow
-					if !strings.Contains(err.Error(), "monotonic entropy overflow") {
-						allErrors = append(allErrors, err)
-					}
+					allErrors = append(allErrors, err)
 				} else {
 					allRigids = append(allRigids, rigid)
 				}
 				mu.Unlock()
 			}
-		}(i)
+		}()
 	}
 
 	wg.Wait()
@@ -317,10 +325,8 @@ func TestConcurrentGeneration(t *testing.T) {
 		}
 	}
 
-	// We should have generated at least some IDs
-	assert.Greater(t, len(allRigids), 0, "Should generate at least some IDs")
-
-	t.Logf("Generated %d rigids, %d duplicates, %d unique", len(allRigids), duplicates, len(seen))
+	assert.Len(t, allRigids, goroutines*idsPerGoroutine)
+	assert.Zero(t, duplicates)
 }
 
 func TestSignatureLengthBoundaries(t *testing.T) {
@@ -371,6 +377,1018 @@ func TestMultipleMetadataParams(t *testing.T) {
 	assert.Equal(t, "first", result.Metadata)
 }
 
+func TestWithStrict(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	strict := r.WithStrict()
+
+	rigid, err := strict.Generate("user:alice")
+	require.NoError(t, err)
+
+	result, err := strict.Verify(rigid)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, "user:alice", result.Metadata)
+
+	// Strict and lax instances share the key and interoperate
+	_, err = r.Verify(rigid)
+	assert.NoError(t, err)
+	assert.False(t, r.strict, "WithStrict must not modify the receiver")
+}
+
+func TestWithStrictRejectsNonCanonical(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	strict := r.WithStrict()
+
+	rigid, err := r.Generate()
+	require.NoError(t, err)
+
+	// An empty trailing metadata segment verifies in lax mode only
+	_, err = r.Verify(rigid + "-")
+	assert.NoError(t, err)
+	_, err = strict.Verify(rigid + "-")
+	assert.Equal(t, ErrInvalidFormat, err)
+
+	// A lowercase ULID signed as-is is never emitted by Generate
+	lowerULID := strings.ToLower(rigid[:26])
+	lowerRigid := lowerULID + "-" + string(r.getMAC(r.version).signature(FormatV1, "", lowerULID, "", r.signatureLength))
+	_, err = r.Verify(lowerRigid)
+	assert.NoError(t, err)
+	_, err = strict.Verify(lowerRigid)
+	assert.Equal(t, ErrInvalidULID, err)
+
+	// Signature characters outside the upper-case base32 alphabet
+	parts := strings.Split(rigid, "-")
+	_, err = strict.Verify(parts[0] + "-" + strings.ToLower(parts[1]))
+	assert.Equal(t, ErrInvalidFormat, err)
+	_, err = strict.Verify(rigid + "\n")
+	assert.Equal(t, ErrInvalidFormat, err)
+}
+
+func TestVerifyInto(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	type claims struct {
+		User  string   `json:"user"`
+		Roles []string `json:"roles"`
+	}
+
+	rigid, err := r.Generate(`{"user":"alice","roles":["admin","ops"]}`)
+	require.NoError(t, err)
+
+	var c claims
+	require.NoError(t, r.VerifyInto(rigid, &c))
+	assert.Equal(t, claims{User: "alice", Roles: []string{"admin", "ops"}}, c)
+}
+
+func TestVerifyIntoErrors(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var c struct{ User string }
+
+	// Tampered IDs fail before any decoding
+	rigid, err := r.Generate(`{"User":"alice"}`)
+	require.NoError(t, err)
+	err = r.VerifyInto(strings.Replace(rigid, "alice", "mallory", 1), &c)
+	assert.Equal(t, ErrIntegrityFailure, err)
+	assert.Empty(t, c.User)
+
+	// Valid IDs with undecodable metadata
+	rigid, err = r.Generate("user:alice")
+	require.NoError(t, err)
+	err = r.VerifyInto(rigid, &c)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	rigid, err = r.Generate()
+	require.NoError(t, err)
+	err = r.VerifyInto(rigid, &c)
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+}
+
+type textClaims struct{ raw string }
+
+func (c *textClaims) UnmarshalText(b []byte) error {
+	c.raw = string(b)
+	return nil
+}
+
+func TestVerifyIntoTextUnmarshaler(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	rigid, err := r.Generate("user:alice:role:admin")
+	require.NoError(t, err)
+
+	var c textClaims
+	require.NoError(t, r.VerifyInto(rigid, &c))
+	assert.Equal(t, "user:alice:role:admin", c.raw)
+}
+
+func TestGenerateAt(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2019, time.March, 14, 9, 26, 53, 589000000, time.UTC)
+	rigid, err := r.GenerateAt(createdAt, "legacy:42")
+	require.NoError(t, err)
+
+	result, err := r.Verify(rigid)
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(result.Timestamp), "got %v", result.Timestamp)
+	assert.Equal(t, "legacy:42", result.Metadata)
+
+	// Historical IDs sort before fresh ones
+	current, err := r.Generate()
+	require.NoError(t, err)
+	assert.True(t, Before(rigid, current))
+}
+
+func TestSign(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	u := ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	rigid, err := r.Sign(u, "order:42")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rigid, u.String()+"-"))
+
+	again, err := r.Sign(u, "order:42")
+	require.NoError(t, err)
+	assert.Equal(t, rigid, again, "signing is deterministic")
+
+	result, err := r.Verify(rigid)
+	require.NoError(t, err)
+	assert.Equal(t, u.String(), result.ULID)
+	assert.Equal(t, "order:42", result.Metadata)
+	assert.Equal(t, uint64(2), r.Stats().Generated)
+}
+
+func TestStripSignature(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	u := ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	id, err := r.Sign(u, "order:42")
+	require.NoError(t, err)
+
+	got, metadata, err := r.StripSignature(id)
+	require.NoError(t, err)
+	assert.Equal(t, u, got)
+	assert.Equal(t, "order:42", metadata)
+
+	_, _, err = r.StripSignature(id[:27] + "AAAAAAAAAAAAA-order:42")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	revoked := r.WithRevocationStore(newMapStore())
+	require.NoError(t, revoked.Revoke(context.Background(), id, 0))
+	_, _, err = revoked.StripSignature(id)
+	assert.ErrorIs(t, err, ErrRevoked, "stores are consulted")
+}
+
+func TestGenerateAtOutOfRange(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	_, err = r.GenerateAt(time.Date(10890, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ulid.ErrBigTime)
+}
+
+func TestWithSignatureLength(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	long, err := r.WithSignatureLength(16)
+	require.NoError(t, err)
+	assert.Equal(t, 16, long.signatureLength)
+	assert.Equal(t, DefaultSignatureLength, r.signatureLength)
+
+	rigid, err := long.Generate()
+	require.NoError(t, err)
+
+	_, err = long.Verify(rigid)
+	assert.NoError(t, err)
+
+	// A derived instance matches one built from scratch with the same settings
+	fresh, err := NewRigid(testSecretKey, 16)
+	require.NoError(t, err)
+	_, err = fresh.Verify(rigid)
+	assert.NoError(t, err)
+
+	// Verifiers configured for another length report the mismatch explicitly
+	_, err = r.Verify(rigid)
+	assert.Equal(t, ErrSignatureLengthMismatch, err)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	for _, sigLen := range []int{0, 3, 33} {
+		_, err := r.WithSignatureLength(sigLen)
+		assert.Equal(t, ErrInvalidSigLength, err, "sigLen=%d", sigLen)
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	users, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	orders, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	rigid, err := users.Generate("alice")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rigid, "usr_"), rigid)
+	assert.True(t, IsWellFormed(rigid))
+
+	result, err := users.Verify(rigid)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", result.Metadata)
+	assert.Len(t, result.ULID, 26)
+
+	ulidObj, err := users.ExtractULID(rigid)
+	require.NoError(t, err)
+	assert.Equal(t, result.ULID, ulidObj.String())
+
+	// IDs are only accepted by instances with the same prefix
+	_, err = orders.Verify(rigid)
+	assert.Equal(t, ErrInvalidFormat, err)
+	_, err = r.Verify(rigid)
+	assert.Equal(t, ErrInvalidFormat, err)
+
+	// Relabeling the prefix invalidates the signature
+	relabeled := "ord_" + strings.TrimPrefix(rigid, "usr_")
+	_, err = orders.Verify(relabeled)
+	assert.Equal(t, ErrIntegrityFailure, err)
+
+	// Removing the prefix restores the plain format
+	plain, err := users.WithPrefix("")
+	require.NoError(t, err)
+	rigid, err = plain.Generate()
+	require.NoError(t, err)
+	_, err = r.Verify(rigid)
+	assert.NoError(t, err)
+}
+
+func TestWithPrefixInvalid(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, prefix := range []string{"usr_", "a-b", "ü", "with space"} {
+		_, err := r.WithPrefix(prefix)
+		assert.Equal(t, ErrInvalidPrefix, err, "prefix=%q", prefix)
+	}
+
+	_, err = r.Verify("_01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BA")
+	assert.Equal(t, ErrInvalidFormat, err)
+}
+
+func TestWithTTL(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	r := base.WithTTL(time.Hour)
+	assert.Equal(t, time.Hour, r.TTL())
+	assert.Zero(t, base.TTL())
+
+	fresh, err := r.GenerateAt(time.Now().Add(-59 * time.Minute))
+	require.NoError(t, err)
+	result, err := r.Verify(fresh)
+	require.NoError(t, err)
+	assert.Equal(t, result.Timestamp.Add(time.Hour), result.ExpiresAt)
+
+	expired, err := r.GenerateAt(time.Now().Add(-61 * time.Minute))
+	require.NoError(t, err)
+	result, err = r.Verify(expired)
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.False(t, result.Valid)
+
+	// Without a TTL the same ID is accepted and has no expiry.
+	result, err = base.Verify(expired)
+	require.NoError(t, err)
+	assert.True(t, result.ExpiresAt.IsZero())
+
+	// Forgery is reported before expiry.
+	_, err = r.Verify(expired[:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	assert.Zero(t, r.WithTTL(-time.Second).TTL())
+}
+
+func TestKeyID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	derived, err := r.WithSignatureLength(16)
+	require.NoError(t, err)
+	other, err := NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+
+	assert.Len(t, r.KeyID(), 16)
+	assert.Equal(t, r.KeyID(), derived.KeyID())
+	assert.NotEqual(t, r.KeyID(), other.KeyID())
+	assert.NotContains(t, r.KeyID(), string(testSecretKey))
+}
+
+func TestVerifyResultJSON(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2016, 7, 30, 23, 54, 10, 259_000_000, time.FixedZone("CEST", 2*60*60))
+	id, err := r.GenerateAt(createdAt, "user:alice")
+	require.NoError(t, err)
+
+	result, err := r.Verify(id)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"valid":true,"ulid":"`+id[:26]+`","metadata":"user:alice","timestamp":"2016-07-30T21:54:10.259Z","key_id":"`+r.KeyID()+`"}`, string(data))
+
+	var decoded VerifyResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, result.ULID, decoded.ULID)
+	assert.True(t, result.Timestamp.Equal(decoded.Timestamp))
+	assert.Equal(t, r.KeyID(), decoded.KeyID)
+
+	// expires_at appears for instances with a TTL.
+	result, err = r.WithTTL(100 * 365 * 24 * time.Hour).Verify(id)
+	require.NoError(t, err)
+	data, err = json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"expires_at":"2116-`)
+
+	// in_grace_period appears for IDs accepted within a grace period.
+	result, err = r.WithTTL(time.Hour).Verify(id, WithGracePeriod(100*365*24*time.Hour))
+	require.NoError(t, err)
+	data, err = json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"in_grace_period":true`)
+	decoded = VerifyResult{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.InGracePeriod)
+
+	data, err = json.Marshal(VerifyResult{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"valid":false,"ulid":"","metadata":""}`, string(data))
+}
+
+func TestGenerateEntropyOverflow(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// Start the sequence at its maximum, so the next ID of the same millisecond overflows it
+	r.gen.shards[0].entropy = ulid.Monotonic(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)), 1)
+
+	now := time.Now()
+	first, err := r.GenerateAt(now)
+	require.NoError(t, err)
+	second, err := r.GenerateAt(now)
+	require.NoError(t, err)
+	third, err := r.GenerateAt(now)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Less(t, second, third, "the restarted sequence is monotonic")
+	for _, id := range []string{first, second, third} {
+		_, err := r.Verify(id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestWithEntropyShards(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	sharded := r.WithEntropyShards(4)
+	assert.Len(t, sharded.gen.shards, 4)
+	assert.Len(t, r.gen.shards, 1, "the original instance is unchanged")
+	assert.Len(t, r.WithEntropyShards(0).gen.shards, runtime.GOMAXPROCS(0))
+
+	const goroutines = 8
+	const idsPerGoroutine = 200
+
+	ids := make([][]string, goroutines)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range idsPerGoroutine {
+				id, err := sharded.Generate("m")
+				assert.NoError(t, err)
+				ids[i] = append(ids[i], id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, batch := range ids {
+		for _, id := range batch {
+			assert.False(t, seen[id], "duplicate ID %s", id)
+			seen[id] = true
+
+			_, err := r.Verify(id)
+			assert.NoError(t, err, "IDs verify with the unsharded instance")
+		}
+	}
+	assert.Len(t, seen, goroutines*idsPerGoroutine)
+}
+
+func TestVerifyAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	typed = typed.WithStrict().WithTTL(time.Hour)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	withMetadata, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	typedID, err := typed.Generate()
+	require.NoError(t, err)
+	v3, err := r.WithFormatVersion(FormatV3)
+	require.NoError(t, err)
+	v3 = v3.WithLegacyVerification()
+	v3ID, err := v3.Generate("user:alice")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		r    *Rigid
+		id   string
+	}{
+		{"plain", r, id},
+		{"metadata", r, withMetadata},
+		{"prefix strict ttl", typed, typedID},
+		{"v3", v3, v3ID},
+		{"v3 legacy fallback", v3, withMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := tt.r.Verify(tt.id); err != nil {
+					t.Fatal(err)
+				}
+			})
+			assert.Zero(t, allocs)
+		})
+	}
+}
+
+func TestGenerateAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	// Only the returned string is allocated
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := typed.Generate("user:alice"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Equal(t, 1.0, allocs)
+}
+
+func TestSignatureWireFormat(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 12)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	for _, metadata := range []string{"", "user:alice-admin"} {
+		id, err := typed.Generate(metadata)
+		require.NoError(t, err)
+		parts, err := Parse(id)
+		require.NoError(t, err)
+
+		// HMAC-SHA256 over PREFIX_ULID followed by the metadata, truncated and base32-encoded
+		mac := hmac.New(sha256.New, testSecretKey)
+		mac.Write([]byte("ord_" + parts.ULID + metadata))
+		want := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mac.Sum(nil)[:12])
+		assert.Equal(t, want, parts.Signature)
+	}
+}
+
+func TestAppendGenerate(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	buf := []byte("ids: ")
+	buf, err = typed.AppendGenerate(buf, "user:alice")
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(buf, []byte("ids: ord_")))
+
+	id := string(buf[len("ids: "):])
+	assert.Len(t, id, EncodedLen(3, DefaultSignatureLength, len("user:alice")))
+	result, err := typed.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+
+	if !raceEnabled {
+		dst := make([]byte, 0, EncodedLen(0, DefaultSignatureLength, 0))
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := r.AppendGenerate(dst[:0]); err != nil {
+				t.Fatal(err)
+			}
+		})
+		assert.Zero(t, allocs)
+	}
+}
+
+func TestVerifyBytes(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	buf := []byte(id)
+	result, err := r.VerifyBytes(buf)
+	require.NoError(t, err)
+	want, err := r.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, want, result)
+
+	// The result does not alias the buffer
+	copy(buf, bytes.Repeat([]byte{'x'}, len(buf)))
+	assert.Equal(t, id[:26], result.ULID)
+	assert.Equal(t, "user:alice", result.Metadata)
+
+	_, err = r.VerifyBytes([]byte(id[:27] + "AAAAAAAAAAAAA-user:alice"))
+	assert.Equal(t, ErrIntegrityFailure, err)
+	_, err = r.VerifyBytes(nil)
+	assert.Error(t, err)
+
+	if !raceEnabled {
+		forged := []byte(id[:27] + "AAAAAAAAAAAAA")
+		assert.Zero(t, testing.AllocsPerRun(100, func() { _, _ = r.VerifyBytes(forged) }))
+		valid := []byte(id)
+		assert.Equal(t, 1.0, testing.AllocsPerRun(100, func() { _, _ = r.VerifyBytes(valid) }))
+	}
+}
+
+func TestWithFormatVersion(t *testing.T) {
+	v1, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	assert.Equal(t, FormatV1, v1.FormatVersion())
+
+	v2, err := v1.WithFormatVersion(FormatV2)
+	require.NoError(t, err)
+	assert.Equal(t, FormatV2, v2.FormatVersion())
+	assert.Equal(t, FormatV1, v1.FormatVersion())
+
+	legacyID, err := v1.Generate("user:alice")
+	require.NoError(t, err)
+	id, err := v2.Generate("user:alice")
+	require.NoError(t, err)
+
+	result, err := v2.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+
+	// Versions do not verify each other's IDs
+	_, err = v2.Verify(legacyID)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = v1.Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// Legacy verification accepts both, and still generates FormatV2 IDs
+	migrating := v2.WithLegacyVerification()
+	for _, id := range []string{id, legacyID} {
+		_, err := migrating.Verify(id)
+		assert.NoError(t, err, id)
+	}
+	generated, err := migrating.Generate()
+	require.NoError(t, err)
+	_, err = v1.Verify(generated)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// Legacy verification of a FormatV1 instance changes nothing
+	_, err = v1.WithLegacyVerification().Verify(id)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	// The signature length is bound into FormatV2 signatures, not only implied by their size
+	mac := v2.getMAC(FormatV2)
+	short := string(mac.signature(FormatV2, "", "X", "", 8))
+	long := string(mac.signature(FormatV2, "", "X", "", 16))
+	assert.NotEqual(t, short[:12], long[:12])
+	v2.putMAC(FormatV2, mac)
+
+	for _, v := range []FormatVersion{0, 4, 255} {
+		_, err := v1.WithFormatVersion(v)
+		assert.ErrorIs(t, err, ErrInvalidFormatVersion, v)
+	}
+}
+
+func TestFormatV2WireFormat(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 12)
+	require.NoError(t, err)
+	r, err = r.WithFormatVersion(FormatV2)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	for _, metadata := range []string{"", "user:alice-admin"} {
+		id, err := typed.Generate(metadata)
+		require.NoError(t, err)
+		parts, err := Parse(id)
+		require.NoError(t, err)
+
+		// A header binding the version, algorithm and signature length, then each segment
+		// preceded by its 4-byte big-endian length
+		input := []byte("rigid\x00\x02\x01\x0c")
+		for _, f := range []string{"ord", parts.ULID, metadata} {
+			input = binary.BigEndian.AppendUint32(input, uint32(len(f)))
+			input = append(input, f...)
+		}
+		mac := hmac.New(sha256.New, testSecretKey)
+		mac.Write(input)
+		want := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(mac.Sum(nil)[:12])
+		assert.Equal(t, want, parts.Signature)
+	}
+}
+
+func TestVerifyMalformedSegments(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	sign := func(ulidStr string) string {
+		mac := r.getMAC(r.version)
+		defer r.putMAC(r.version, mac)
+		return ulidStr + "-" + string(mac.signature(r.version, "", ulidStr, "", r.signatureLength))
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		err  error
+	}{
+		{"empty signature", "01ARZ3NDEKTSV4RRFFQ69G5FAV-", ErrInvalidFormat},
+		{"empty signature with metadata", "01ARZ3NDEKTSV4RRFFQ69G5FAV--user:alice", ErrInvalidFormat},
+		{"signed invalid ULID character", sign("01ARZ3NDEKTSV4RRFFQ69G5F!V"), ErrInvalidULID},
+		{"signed overflowing ULID", sign("8ZZZZZZZZZZZZZZZZZZZZZZZZZ"), ErrInvalidULID},
+		{"oversized signature", "01ARZ3NDEKTSV4RRFFQ69G5FAV-" + strings.Repeat("A", 1<<20), ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Verify(tt.id)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err = r.ExtractULID(sign("01ARZ3NDEKTSV4RRFFQ69G5F!V"))
+	assert.ErrorIs(t, err, ErrInvalidULID)
+}
+
+func TestVerifyCanonicalULID(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	canonical := id[:ulid.EncodedSize]
+
+	// A non-canonical encoding of the same ULID, signed as presented
+	lower := strings.ToLower(canonical)
+	mac := r.getMAC(r.version)
+	lowerID := lower + "-" + string(mac.signature(r.version, "", lower, "user:alice", r.signatureLength)) + "-user:alice"
+	r.putMAC(r.version, mac)
+
+	result, err := r.Verify(lowerID)
+	require.NoError(t, err)
+	assert.Equal(t, canonical, result.ULID)
+
+	result, err = r.VerifyBytes([]byte(lowerID))
+	require.NoError(t, err)
+	assert.Equal(t, canonical, result.ULID)
+
+	_, err = r.WithStrict().Verify(lowerID)
+	assert.ErrorIs(t, err, ErrInvalidULID)
+
+	// Canonical IDs report their own segment
+	result, err = r.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, canonical, result.ULID)
+}
+
+func TestWithPrintableMetadata(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	p := r.WithPrintableMetadata()
+
+	for _, metadata := range []string{"", "user:alice", "name=Ada Lovelace", "şehir:İstanbul", "emoji:🦀", "\ufffd"} {
+		id, err := p.Generate(metadata)
+		require.NoError(t, err, "%q", metadata)
+		result, err := p.Verify(id)
+		require.NoError(t, err, "%q", metadata)
+		assert.Equal(t, metadata, result.Metadata)
+	}
+
+	for _, metadata := range []string{"a\nb", "a\r\nSet-Cookie: x", "tab\there", "nul\x00", "del\x7f", "\xff\xfe", "bidi\u202e", "nbsp\u00a0", "c1\u0085"} {
+		_, err := p.Generate(metadata)
+		assert.ErrorIs(t, err, ErrInvalidMetadata, "%q", metadata)
+
+		// IDs signed without the restriction are rejected on verification
+		id, err := r.Generate(metadata)
+		require.NoError(t, err)
+		_, err = r.Verify(id)
+		require.NoError(t, err)
+		_, err = p.Verify(id)
+		assert.ErrorIs(t, err, ErrInvalidMetadata, "%q", metadata)
+	}
+}
+
+func TestWithMaxLength(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// The default admits metadata up to the remainder of DefaultMaxLength
+	fits := strings.Repeat("m", DefaultMaxLength-EncodedLen(0, DefaultSignatureLength, 0)-1)
+	id, err := r.Generate(fits)
+	require.NoError(t, err)
+	assert.Len(t, id, DefaultMaxLength)
+	_, err = r.Verify(id)
+	assert.NoError(t, err)
+
+	_, err = r.Generate(fits + "m")
+	assert.ErrorIs(t, err, ErrTooLong)
+	_, err = r.AppendGenerate(nil, fits+"m")
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	unlimited := r.WithMaxLength(0)
+	long, err := unlimited.Generate(strings.Repeat("m", 1<<16))
+	require.NoError(t, err)
+	_, err = unlimited.Verify(long)
+	assert.NoError(t, err)
+	_, err = r.Verify(long)
+	assert.ErrorIs(t, err, ErrTooLong)
+	_, err = r.VerifyBytes([]byte(long))
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	short := r.WithMaxLength(40)
+	_, err = short.Generate()
+	assert.NoError(t, err)
+	_, err = short.Generate("user:alice")
+	assert.ErrorIs(t, err, ErrTooLong)
+	_, err = short.Verify(id)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestWithTimestampBounds(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	now := time.Now()
+	past, err := r.GenerateAt(now.Add(-48 * time.Hour))
+	require.NoError(t, err)
+	current, err := r.Generate()
+	require.NoError(t, err)
+	future, err := r.GenerateAt(now.AddDate(30, 0, 0))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		r       *Rigid
+		invalid []string
+	}{
+		{"unbounded", r, nil},
+		{"min", r.WithTimestampBounds(now.Add(-time.Hour), time.Time{}), []string{past}},
+		{"max", r.WithTimestampBounds(time.Time{}, now.Add(time.Hour)), []string{future}},
+		{"both", r.WithTimestampBounds(now.Add(-time.Hour), now.Add(time.Hour)), []string{past, future}},
+		{"skew", r.WithMaxClockSkew(time.Minute), []string{future}},
+		{"skew removed", r.WithMaxClockSkew(time.Minute).WithMaxClockSkew(0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, id := range []string{past, current, future} {
+				_, err := tt.r.Verify(id)
+				if slices.Contains(tt.invalid, id) {
+					assert.ErrorIs(t, err, ErrTimestampOutOfRange, id)
+				} else {
+					assert.NoError(t, err, id)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFailureHook(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	type failure struct {
+		id     string
+		err    error
+		remote any
+	}
+	var (
+		mu       sync.Mutex
+		failures []failure
+	)
+	store := newMapStore()
+	hooked := r.WithRevocationStore(store).WithFailureHook(func(id string, err error, remote any) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, failure{id, err, remote})
+	})
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	forged := id[:27] + "AAAAAAAAAAAAA"
+	revoked, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, hooked.Revoke(context.Background(), revoked, 0))
+
+	// Successful verifications are not reported
+	_, err = hooked.Verify(id)
+	require.NoError(t, err)
+	_, err = hooked.VerifyBytes([]byte(id))
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+
+	ctx := ContextWithRemote(context.Background(), "203.0.113.7")
+	_, err = hooked.Verify(forged)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = hooked.VerifyContext(ctx, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = hooked.VerifyContext(ctx, revoked)
+	assert.ErrorIs(t, err, ErrRevoked)
+	_, err = hooked.VerifyBytes([]byte(forged))
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	err = hooked.VerifyInto(forged, &struct{}{})
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	assert.Equal(t, []failure{
+		{forged, ErrIntegrityFailure, nil},
+		{"garbage", ErrInvalidFormat, "203.0.113.7"},
+		{revoked, ErrRevoked, "203.0.113.7"},
+		{forged, ErrIntegrityFailure, nil},
+		{forged, ErrIntegrityFailure, nil},
+	}, failures)
+
+	// The hook is not shared with the parent, and can be removed
+	failures = nil
+	_, _ = r.Verify(forged)
+	_, _ = hooked.WithFailureHook(nil).Verify(forged)
+	assert.Empty(t, failures)
+}
+
+func TestWithNormalizedMetadata(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	n := r.WithNormalizedMetadata()
+
+	const nfc, nfd = "city:Z\u00fcrich", "city:Zu\u0308rich"
+
+	// Generated IDs carry NFC metadata whichever form was passed
+	for _, metadata := range []string{nfc, nfd} {
+		id, err := n.Generate(metadata)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(id, "-"+nfc), id)
+	}
+
+	id, err := n.Generate(nfc)
+	require.NoError(t, err)
+	decomposed := strings.Replace(id, nfc, nfd, 1)
+
+	// An ID decomposed in transit still verifies, reporting NFC metadata
+	for _, candidate := range []string{id, decomposed} {
+		result, err := n.Verify(candidate)
+		require.NoError(t, err, candidate)
+		assert.Equal(t, nfc, result.Metadata)
+
+		result, err = n.VerifyBytes([]byte(candidate))
+		require.NoError(t, err, candidate)
+		assert.Equal(t, nfc, result.Metadata)
+	}
+
+	// Without normalization, only the exact form signed verifies
+	_, err = r.Verify(id)
+	assert.NoError(t, err)
+	_, err = r.Verify(decomposed)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+}
+
+func TestFormatV3(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 12)
+	require.NoError(t, err)
+	v3, err := r.WithFormatVersion(FormatV3)
+	require.NoError(t, err)
+	v2, err := r.WithFormatVersion(FormatV2)
+	require.NoError(t, err)
+
+	id, err := v3.Generate("user:alice")
+	require.NoError(t, err)
+	result, err := v3.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+
+	for _, other := range []*Rigid{r, v2} {
+		_, err = other.Verify(id)
+		assert.ErrorIs(t, err, ErrIntegrityFailure)
+	}
+
+	// Legacy verification falls back to FormatV1, not FormatV2
+	legacy, err := r.Generate()
+	require.NoError(t, err)
+	_, err = v3.WithLegacyVerification().Verify(legacy)
+	assert.NoError(t, err)
+
+	// Derived instances with another signature length use their own key, matching fresh instances
+	long, err := v3.WithSignatureLength(16)
+	require.NoError(t, err)
+	fresh, err := NewRigid(testSecretKey, 16)
+	require.NoError(t, err)
+	fresh, err = fresh.WithFormatVersion(FormatV3)
+	require.NoError(t, err)
+	longID, err := long.Generate()
+	require.NoError(t, err)
+	_, err = fresh.Verify(longID)
+	assert.NoError(t, err)
+	_, err = v3.Verify(longID)
+	assert.ErrorIs(t, err, ErrSignatureLengthMismatch)
+}
+
+func TestFormatV3WireFormat(t *testing.T) {
+	r, err := NewRigid(testSecretKey, 12)
+	require.NoError(t, err)
+	r, err = r.WithFormatVersion(FormatV3)
+	require.NoError(t, err)
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+
+	hmacSHA256 := func(key []byte, data ...[]byte) []byte {
+		mac := hmac.New(sha256.New, key)
+		for _, d := range data {
+			mac.Write(d)
+		}
+		return mac.Sum(nil)
+	}
+	// HKDF-SHA256 with an empty salt, and the FormatV3 header as info
+	header := []byte("rigid\x00\x03\x01\x0c")
+	prk := hmacSHA256(make([]byte, 32), testSecretKey)
+	key := hmacSHA256(prk, header, []byte{1})
+
+	for _, metadata := range []string{"", "user:alice-admin"} {
+		id, err := typed.Generate(metadata)
+		require.NoError(t, err)
+		parts, err := Parse(id)
+		require.NoError(t, err)
+
+		input := append([]byte(nil), header...)
+		for _, f := range []string{"ord", parts.ULID, metadata} {
+			input = binary.BigEndian.AppendUint32(input, uint32(len(f)))
+			input = append(input, f...)
+		}
+		want := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hmacSHA256(key, input)[:12])
+		assert.Equal(t, want, parts.Signature)
+	}
+}
+
+func TestFormatV3IndependentLengths(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// Under FormatV1 a short signature is the prefix of the long one over the same input;
+	// under FormatV3 the signatures of different lengths are unrelated
+	signatures := func(v FormatVersion) (short, long string) {
+		for _, n := range []int{MinSignatureLength, MaxSignatureLength} {
+			d, err := r.WithSignatureLength(n)
+			require.NoError(t, err)
+			d, err = d.WithFormatVersion(v)
+			require.NoError(t, err)
+			mac := d.getMAC(v)
+			sig := string(mac.signature(v, "", "01ARZ3NDEKTSV4RRFFQ69G5FAV", "", n))
+			d.putMAC(v, mac)
+			if n == MinSignatureLength {
+				short = sig
+			} else {
+				long = sig
+			}
+		}
+		return short, long
+	}
+
+	short, long := signatures(FormatV1)
+	assert.Equal(t, short[:6], long[:6])
+	short, long = signatures(FormatV3)
+	assert.NotEqual(t, short[:6], long[:6])
+}
+
 // Benchmark tests
 func BenchmarkGenerate(b *testing.B) {
 	key := make([]byte, 32)
@@ -380,6 +1398,7 @@ func BenchmarkGenerate(b *testing.B) {
 	r, err := NewRigid(key)
 	require.NoError(b, err)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := r.Generate()
@@ -398,6 +1417,7 @@ func BenchmarkVerify(b *testing.B) {
 	rigid, err := r.Generate()
 	require.NoError(b, err)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := r.Verify(rigid)
@@ -415,9 +1435,37 @@ func BenchmarkGenerateWithMetadata(b *testing.B) {
 
 	metadata := "benchmark-metadata"
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_, err := r.Generate(metadata)
 		require.NoError(b, err)
 	}
 }
+
+func BenchmarkGenerateParallel(b *testing.B) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := r.Generate()
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkGenerateParallelSharded(b *testing.B) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(b, err)
+	r = r.WithEntropyShards(0)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := r.Generate()
+			require.NoError(b, err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Package rigidchi adapts rigid path parameter verification to chi.
+//
+// chi uses standard net/http middleware, so rigidhttp.Middleware is used unchanged for request IDs:
+//
+//	router := chi.NewRouter()
+//	router.Use(rigidhttp.Middleware(r))
+//	router.With(rigidchi.RequireURLParam(r, "id")).Get("/orders/{id}", func(w http.ResponseWriter, req *http.Request) {
+//		order, _ := rigidchi.Verified(req, "id")
+//		fmt.Fprintf(w, "order %s created %s", order.ULID, order.Timestamp)
+//	})
+package rigidchi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+type paramKey string
+
+// URLParam verifies the URL parameter name as a rigid ID, as rigidhttp.Verify does.
+func URLParam(req *http.Request, r *rigid.Rigid, name string) (rigid.VerifyResult, error) {
+	return rigidhttp.Verify(req, r, chi.URLParam(req, name))
+}
+
+// RequireURLParam returns middleware that verifies the URL parameter name as a rigid ID,
+// responding with 400 Bad Request if it is invalid. It must be installed on the route, for
+// example with Router.With, so that URL parameters are known. Handlers retrieve the result with Verified.
+func RequireURLParam(r *rigid.Rigid, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			result, err := URLParam(req, r, name)
+			if err != nil {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			ctx := context.WithValue(req.Context(), paramKey(name), result)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+// Verified returns the verification result stored by RequireURLParam for the URL parameter name.
+func Verified(req *http.Request, name string) (rigid.VerifyResult, bool) {
+	result, ok := req.Context().Value(paramKey(name)).(rigid.VerifyResult)
+	return result, ok
+}
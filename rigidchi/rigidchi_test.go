@@ -0,0 +1,50 @@
+package rigidchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestRequireURLParam(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	router.Use(rigidhttp.Middleware(r))
+	router.With(RequireURLParam(r, "id")).Get("/orders/{id}", func(w http.ResponseWriter, req *http.Request) {
+		order, ok := Verified(req, "id")
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(order.Metadata))
+	})
+
+	id, err := r.Generate("tenant:acme")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "tenant:acme", rec.Body.String())
+	assert.NotEmpty(t, rec.Header().Get(rigidhttp.DefaultHeader))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id+"x", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestVerifiedWithoutRequireURLParam(t *testing.T) {
+	_, ok := Verified(httptest.NewRequest(http.MethodGet, "/", nil), "id")
+	assert.False(t, ok)
+}
@@ -0,0 +1,55 @@
+// Package rigidecho adapts rigid request IDs and path parameter verification to Echo.
+//
+//	e := echo.New()
+//	e.Use(rigidecho.Middleware(r))
+//	e.GET("/orders/:id", func(c echo.Context) error {
+//		order, _ := rigidecho.Verified(c, "id")
+//		return c.JSON(http.StatusOK, map[string]any{"id": order.ULID, "created": order.Timestamp})
+//	}, rigidecho.RequireParam(r, "id"))
+package rigidecho
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+// Middleware returns Echo middleware behaving like rigidhttp.Middleware: it verifies the
+// incoming request ID or assigns a new one, and rejects requests carrying forged IDs.
+// The ID is available from the request context through rigidhttp.RequestID.
+func Middleware(r *rigid.Rigid, opts ...rigidhttp.Option) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(rigidhttp.Middleware(r, opts...))
+}
+
+// Param verifies the path parameter name as a rigid ID, as rigidhttp.Verify does.
+func Param(c echo.Context, r *rigid.Rigid, name string) (rigid.VerifyResult, error) {
+	return rigidhttp.Verify(c.Request(), r, c.Param(name))
+}
+
+// RequireParam returns route middleware that verifies the path parameter name as a rigid ID,
+// responding with 400 Bad Request if it is invalid. Handlers retrieve the result with Verified.
+func RequireParam(r *rigid.Rigid, name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			result, err := Param(c, r, name)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid "+name).SetInternal(err)
+			}
+			c.Set(contextKey(name), result)
+			return next(c)
+		}
+	}
+}
+
+// Verified returns the verification result stored by RequireParam for the path parameter name.
+func Verified(c echo.Context, name string) (rigid.VerifyResult, bool) {
+	result, ok := c.Get(contextKey(name)).(rigid.VerifyResult)
+	return result, ok
+}
+
+func contextKey(name string) string {
+	return "rigid.param." + name
+}
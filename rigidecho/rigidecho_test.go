@@ -0,0 +1,63 @@
+package rigidecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newServer(r *rigid.Rigid) *echo.Echo {
+	e := echo.New()
+	e.Use(Middleware(r))
+	e.GET("/orders/:id", func(c echo.Context) error {
+		order, ok := Verified(c, "id")
+		if !ok {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.String(http.StatusOK, order.ULID+" "+rigidhttp.RequestID(c.Request()))
+	}, RequireParam(r, "id"))
+	return e
+}
+
+func TestRequireParam(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	e := newServer(r)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id[:26]+" "+rec.Header().Get(rigidhttp.DefaultHeader), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id[:len(id)-1]+"9", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMiddlewareRejectsForgedID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	e := newServer(r)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/"+id, nil)
+	req.Header.Set(rigidhttp.DefaultHeader, "forged-request-id")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.NotContains(t, rec.Body.String(), id[:26])
+}
@@ -0,0 +1,317 @@
+// Package rigidfile provides a rigid.RevocationStore persisted to an append-only file, for
+// single-node deployments that need revocations to survive restarts without a database server.
+//
+//	store, err := rigidfile.Open("/var/lib/rigid/revocations.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	r = r.WithRevocationStore(store)
+//
+// Each revocation is appended to the file as a line of JSON. Lookups pick up lines appended by
+// other processes, such as the rigid revoke command, so revocations take effect without a restart.
+// Compact rewrites the file without expired and repeated revocations, so it does not grow forever.
+package rigidfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// entry is a line of the revocation file.
+type entry struct {
+	ULID      string     `json:"ulid"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Store is a file-backed revocation store. It is safe for concurrent use, and several
+// processes may append to the same file.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	f      *os.File
+	offset int64
+	// revoked maps ULIDs to their expiry, the zero time meaning never.
+	revoked map[string]time.Time
+}
+
+var (
+	_ rigid.RevocationStore  = (*Store)(nil)
+	_ rigid.RevocationLister = (*Store)(nil)
+)
+
+// Open opens the revocation file at path, creating it if it does not exist, and loads its entries.
+func Open(path string) (*Store, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, f: f, revoked: make(map[string]time.Time)}
+	if err := s.refresh(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// Revoke appends a revocation of the ID with the given ULID, expiring after ttl or never if
+// ttl is zero, and syncs the file before returning.
+func (s *Store) Revoke(_ context.Context, ulid string, ttl time.Duration) error {
+	e := entry{ULID: ulid}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl).UTC()
+		e.ExpiresAt = &expiresAt
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line = append(line, '\n')
+	if err := s.append(line); err != nil {
+		return err
+	}
+	// A compaction that replaced the file after reading it may have missed the line
+	replaced, err := s.reopenIfReplaced()
+	if err != nil {
+		return err
+	}
+	if replaced {
+		if err := s.append(line); err != nil {
+			return err
+		}
+	}
+	return s.refresh()
+}
+
+// append writes line to the file and syncs it. s.mu must be held.
+func (s *Store) append(line []byte) error {
+	// A single write keeps lines from concurrent writers intact
+	if _, err := s.f.Write(line); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// Compact replaces the file with one holding a single line per unexpired revocation. The new file
+// is written aside and renamed over the old one, so a crash leaves either file intact, and lines
+// appended by other processes while it runs are carried over. Other stores using the file switch
+// to the new one on their next operation.
+func (s *Store) Compact(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	now := time.Now()
+	ulids := make([]string, 0, len(s.revoked))
+	for ulid, expiresAt := range s.revoked {
+		if expired(expiresAt, now) {
+			delete(s.revoked, ulid)
+		} else {
+			ulids = append(ulids, ulid)
+		}
+	}
+	slices.Sort(ulids)
+
+	var buf bytes.Buffer
+	for _, ulid := range ulids {
+		e := entry{ULID: ulid}
+		if expiresAt := s.revoked[ulid]; !expiresAt.IsZero() {
+			e.ExpiresAt = &expiresAt
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".compact*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(buf.Bytes())
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	old, oldOffset := s.f, s.offset
+	s.f, s.offset = f, int64(buf.Len())
+	defer old.Close()
+
+	// Writers check for the replacement after appending, so lines they appended to the old file
+	// before the rename are found here, and later ones are appended again by the writers
+	info, err := old.Stat()
+	if err != nil {
+		return err
+	}
+	rest := make([]byte, info.Size()-oldOffset)
+	if _, err := old.ReadAt(rest, oldOffset); err != nil {
+		return err
+	}
+	if end := bytes.LastIndexByte(rest, '\n') + 1; end > 0 {
+		if err := s.append(rest[:end]); err != nil {
+			return err
+		}
+	}
+	return s.refresh()
+}
+
+// reopenIfReplaced switches to the file at the store's path if it is no longer the open one, as
+// after a compaction, and reports whether it did. s.mu must be held.
+func (s *Store) reopenIfReplaced() (bool, error) {
+	current, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	open, err := s.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(current, open) {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return false, err
+	}
+	_ = s.f.Close()
+	s.f = f
+	s.offset = 0
+	clear(s.revoked)
+	return true, nil
+}
+
+// IsRevoked reports whether the ID with the given ULID has an unexpired revocation.
+func (s *Store) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return false, err
+	}
+	expiresAt, ok := s.revoked[ulid]
+	return ok && !expired(expiresAt, time.Now()), nil
+}
+
+// ListRevoked returns the unexpired revocations, ordered by ULID.
+func (s *Store) ListRevoked(_ context.Context) ([]rigid.Revocation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	revocations := make([]rigid.Revocation, 0, len(s.revoked))
+	for ulid, expiresAt := range s.revoked {
+		if !expired(expiresAt, now) {
+			revocations = append(revocations, rigid.Revocation{ULID: ulid, ExpiresAt: expiresAt})
+		}
+	}
+	slices.SortFunc(revocations, func(a, b rigid.Revocation) int {
+		return strings.Compare(a.ULID, b.ULID)
+	})
+	return revocations, nil
+}
+
+// refresh loads the complete lines appended to the file since the last call.
+// s.mu must be held.
+func (s *Store) refresh() error {
+	if _, err := s.reopenIfReplaced(); err != nil {
+		return err
+	}
+	info, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < s.offset {
+		// The file was truncated; start over
+		s.offset = 0
+		clear(s.revoked)
+	}
+	if info.Size() == s.offset {
+		return nil
+	}
+
+	buf := make([]byte, info.Size()-s.offset)
+	if _, err := s.f.ReadAt(buf, s.offset); err != nil {
+		return err
+	}
+
+	// A trailing line without a newline is still being written; leave it for the next refresh
+	end := bytes.LastIndexByte(buf, '\n') + 1
+	for _, line := range bytes.Split(buf[:end], []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil || e.ULID == "" {
+			return fmt.Errorf("rigidfile: malformed entry %q in %s", line, s.f.Name())
+		}
+		s.add(e)
+	}
+	s.offset += int64(end)
+	return nil
+}
+
+// add records e, keeping the latest expiry when a ULID is revoked more than once.
+func (s *Store) add(e entry) {
+	var expiresAt time.Time
+	if e.ExpiresAt != nil {
+		expiresAt = *e.ExpiresAt
+	}
+
+	prev, ok := s.revoked[e.ULID]
+	if ok && (prev.IsZero() || (!expiresAt.IsZero() && expiresAt.Before(prev))) {
+		return
+	}
+	s.revoked[e.ULID] = expiresAt
+}
+
+// expired reports whether a revocation expiring at expiresAt has lapsed at now.
+func expired(expiresAt, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
@@ -0,0 +1,183 @@
+package rigidfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestRevocation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	store := openStore(t, path)
+	ctx := context.Background()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithRevocationStore(store)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(ctx, id, time.Hour))
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, rigid.ErrRevoked)
+
+	// Revocations survive reopening the file
+	reopened := openStore(t, path)
+	revoked, err := reopened.IsRevoked(ctx, id[:26])
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	verifier := openStore(t, path)
+	admin := openStore(t, path)
+	ctx := context.Background()
+
+	revoked, err := verifier.IsRevoked(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Entries appended by another writer are picked up on the next lookup
+	require.NoError(t, admin.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", 0))
+	revoked, err = verifier.IsRevoked(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Incomplete lines are left until their writer finishes them
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(`{"ulid":"01BX5ZZKBKACTAV9WEVGEMMVRZ"`)
+	require.NoError(t, err)
+
+	revoked, err = verifier.IsRevoked(ctx, "01BX5ZZKBKACTAV9WEVGEMMVRZ")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	_, err = f.WriteString("}\n")
+	require.NoError(t, err)
+	revoked, err = verifier.IsRevoked(ctx, "01BX5ZZKBKACTAV9WEVGEMMVRZ")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestListRevoked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	require.NoError(t, os.WriteFile(path, []byte(
+		`{"ulid":"01BX5ZZKBKACTAV9WEVGEMMVS0","expires_at":"`+past+`"}`+"\n"+
+			`{"ulid":"01BX5ZZKBKACTAV9WEVGEMMVRZ","expires_at":"`+past+`"}`+"\n"), 0o600))
+
+	store := openStore(t, path)
+	ctx := context.Background()
+
+	// Expired entries are ignored, and revoking again extends them
+	revoked, err := store.IsRevoked(ctx, "01BX5ZZKBKACTAV9WEVGEMMVS0")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, "01BX5ZZKBKACTAV9WEVGEMMVRZ", time.Hour))
+	require.NoError(t, store.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", 0))
+	require.NoError(t, store.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", time.Minute))
+
+	revocations, err := store.ListRevoked(ctx)
+	require.NoError(t, err)
+	require.Len(t, revocations, 2)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", revocations[0].ULID)
+	assert.True(t, revocations[0].ExpiresAt.IsZero(), "indefinite revocations are not shortened")
+	assert.Equal(t, "01BX5ZZKBKACTAV9WEVGEMMVRZ", revocations[1].ULID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), revocations[1].ExpiresAt, time.Minute)
+}
+
+func TestMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("not json\n"), 0o600))
+
+	_, err := Open(path)
+	assert.ErrorContains(t, err, "malformed entry")
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	lines := `{"ulid":"01ARZ3NDEKTSV4RRFFQ69G5FAV"}
+{"ulid":"01BX5ZZKBKACTAV9WEVGEMMVRZ","expires_at":"` + past + `"}
+{"ulid":"01ARZ3NDEKTSV4RRFFQ69G5FAV","expires_at":"` + past + `"}
+{"ulid":"01CRZ3NDEKTSV4RRFFQ69G5FAV","expires_at":"2999-01-01T00:00:00Z"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(lines), 0o600))
+	store := openStore(t, path)
+	reader := openStore(t, path)
+	ctx := context.Background()
+
+	before, err := store.ListRevoked(ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.Compact(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ulid":"01ARZ3NDEKTSV4RRFFQ69G5FAV"}
+{"ulid":"01CRZ3NDEKTSV4RRFFQ69G5FAV","expires_at":"2999-01-01T00:00:00Z"}
+`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	after, err := store.ListRevoked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	after, err = reader.ListRevoked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "other stores switch to the compacted file")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestCompactSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations.jsonl")
+	compactor := openStore(t, path)
+	writer := openStore(t, path)
+	ctx := context.Background()
+
+	require.NoError(t, writer.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", 0))
+	require.NoError(t, compactor.Compact(ctx))
+
+	// The writer still has the replaced file open; its revocation reaches the new one
+	require.NoError(t, writer.Revoke(ctx, "01BX5ZZKBKACTAV9WEVGEMMVRZ", 0))
+	for _, ulid := range []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "01BX5ZZKBKACTAV9WEVGEMMVRZ"} {
+		revoked, err := compactor.IsRevoked(ctx, ulid)
+		require.NoError(t, err)
+		assert.True(t, revoked, ulid)
+	}
+
+	reopened := openStore(t, path)
+	revocations, err := reopened.ListRevoked(ctx)
+	require.NoError(t, err)
+	assert.Len(t, revocations, 2)
+}
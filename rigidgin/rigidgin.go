@@ -0,0 +1,70 @@
+// Package rigidgin adapts rigid request IDs and path parameter verification to Gin.
+//
+//	router := gin.New()
+//	router.Use(rigidgin.Middleware(r))
+//	router.GET("/orders/:id", rigidgin.RequireParam(r, "id"), func(c *gin.Context) {
+//		order, _ := rigidgin.Verified(c, "id")
+//		c.JSON(http.StatusOK, gin.H{"id": order.ULID, "created": order.Timestamp})
+//	})
+package rigidgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+// Middleware returns Gin middleware behaving like rigidhttp.Middleware: it verifies the
+// incoming request ID or assigns a new one, and aborts requests carrying forged IDs.
+// The ID is available from the request context through rigidhttp.RequestID.
+func Middleware(r *rigid.Rigid, opts ...rigidhttp.Option) gin.HandlerFunc {
+	mw := rigidhttp.Middleware(r, opts...)
+	return func(c *gin.Context) {
+		next := false
+		mw(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			next = true
+			c.Request = req
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+
+		if !next {
+			c.Abort()
+		}
+	}
+}
+
+// Param verifies the path parameter name as a rigid ID, as rigidhttp.Verify does.
+func Param(c *gin.Context, r *rigid.Rigid, name string) (rigid.VerifyResult, error) {
+	return rigidhttp.Verify(c.Request, r, c.Param(name))
+}
+
+// RequireParam returns a handler that verifies the path parameter name as a rigid ID,
+// aborting with 400 Bad Request if it is invalid. Later handlers retrieve the result with Verified.
+func RequireParam(r *rigid.Rigid, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := Param(c, r, name)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+			return
+		}
+		c.Set(contextKey(name), result)
+		c.Next()
+	}
+}
+
+// Verified returns the verification result stored by RequireParam for the path parameter name.
+func Verified(c *gin.Context, name string) (rigid.VerifyResult, bool) {
+	v, ok := c.Get(contextKey(name))
+	if !ok {
+		return rigid.VerifyResult{}, false
+	}
+	result, ok := v.(rigid.VerifyResult)
+	return result, ok
+}
+
+func contextKey(name string) string {
+	return "rigid.param." + name
+}
@@ -0,0 +1,71 @@
+package rigidgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidhttp"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newRouter(r *rigid.Rigid) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(r))
+	router.GET("/orders/:id", RequireParam(r, "id"), func(c *gin.Context) {
+		order, ok := Verified(c, "id")
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, order.ULID+" "+rigidhttp.RequestID(c.Request))
+	})
+	return router
+}
+
+func TestRequireParam(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	router := newRouter(r)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id[:26]+" "+rec.Header().Get(rigidhttp.DefaultHeader), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/"+id[:len(id)-1]+"9", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestMiddlewareRejectsForgedID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	router := newRouter(r)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/"+id, nil)
+	req.Header.Set(rigidhttp.DefaultHeader, "forged-request-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.NotContains(t, rec.Body.String(), id[:26])
+}
+
+func TestVerifiedWithoutRequireParam(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, ok := Verified(c, "id")
+	assert.False(t, ok)
+}
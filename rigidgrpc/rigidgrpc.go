@@ -0,0 +1,159 @@
+// Package rigidgrpc provides gRPC interceptors that carry signed rigid request IDs.
+//
+// Server interceptors verify the request ID received in the incoming metadata, rejecting
+// calls whose ID is forged or malformed with codes.InvalidArgument, and generate a fresh
+// ID when none is present. The ID is stored in the handler context, where
+// rigid.RequestIDFromContext retrieves it, and echoed in the response header.
+//
+// Client interceptors send the request ID found in the context, generating one when the
+// call does not originate from an identified request.
+//
+//	srv := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(rigidgrpc.UnaryServerInterceptor(r)),
+//		grpc.ChainStreamInterceptor(rigidgrpc.StreamServerInterceptor(r)),
+//	)
+//
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithChainUnaryInterceptor(rigidgrpc.UnaryClientInterceptor(r)),
+//		grpc.WithChainStreamInterceptor(rigidgrpc.StreamClientInterceptor(r)),
+//	)
+package rigidgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// DefaultMetadataKey is the metadata key carrying the request ID.
+const DefaultMetadataKey = "x-request-id"
+
+type config struct {
+	key string
+}
+
+// Option configures the interceptors.
+type Option func(*config)
+
+// WithMetadataKey sets the metadata key carrying the request ID. Keys are lower-cased by gRPC.
+func WithMetadataKey(key string) Option {
+	return func(c *config) {
+		c.key = key
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{key: DefaultMetadataKey}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// UnaryServerInterceptor returns a server interceptor that verifies or assigns request IDs for unary calls.
+func UnaryServerInterceptor(r *rigid.Rigid, opts ...Option) grpc.UnaryServerInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id, err := c.incoming(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs(c.key, id)); err != nil {
+			return nil, err
+		}
+		return handler(rigid.ContextWithRequestID(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that verifies or assigns request IDs for streams.
+func StreamServerInterceptor(r *rigid.Rigid, opts ...Option) grpc.StreamServerInterceptor {
+	c := newConfig(opts)
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id, err := c.incoming(ss.Context(), r)
+		if err != nil {
+			return err
+		}
+		if err := ss.SetHeader(metadata.Pairs(c.key, id)); err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: rigid.ContextWithRequestID(ss.Context(), id)})
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that sends the context's request ID,
+// generating one if the context has none.
+func UnaryClientInterceptor(r *rigid.Rigid, opts ...Option) grpc.UnaryClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, err := c.outgoing(ctx, r)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that sends the context's request ID,
+// generating one if the context has none.
+func StreamClientInterceptor(r *rigid.Rigid, opts ...Option) grpc.StreamClientInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := c.outgoing(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, callOpts...)
+	}
+}
+
+// incoming returns the verified request ID from the incoming metadata, or a new one if absent.
+func (c config) incoming(ctx context.Context, r *rigid.Rigid) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(c.key)
+
+	if len(values) == 0 || values[0] == "" {
+		id, err := r.Generate()
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "generate request ID: %v", err)
+		}
+		return id, nil
+	}
+
+	var remote any
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	if _, err := r.VerifyContext(rigid.ContextWithRemote(ctx, remote), values[0]); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid request ID: %v", err)
+	}
+	return values[0], nil
+}
+
+// outgoing returns ctx with the request ID attached to the outgoing metadata.
+func (c config) outgoing(ctx context.Context, r *rigid.Rigid) (context.Context, error) {
+	id, ok := rigid.RequestIDFromContext(ctx)
+	if !ok {
+		var err error
+		if id, err = r.Generate(); err != nil {
+			return nil, status.Errorf(codes.Internal, "generate request ID: %v", err)
+		}
+		ctx = rigid.ContextWithRequestID(ctx, id)
+	}
+	return metadata.AppendToOutgoingContext(ctx, c.key, id), nil
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
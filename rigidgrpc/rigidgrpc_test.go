@@ -0,0 +1,159 @@
+package rigidgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+// startServer runs a health server behind the interceptors and returns a channel
+// receiving the request ID seen by each handler.
+func startServer(t *testing.T, r *rigid.Rigid, opts ...Option) (*bufconn.Listener, <-chan string) {
+	t.Helper()
+
+	seen := make(chan string, 10)
+	record := func(ctx context.Context) {
+		id, _ := rigid.RequestIDFromContext(ctx)
+		seen <- id
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(r, opts...),
+			func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				record(ctx)
+				return handler(ctx, req)
+			}),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(r, opts...),
+			func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				record(ss.Context())
+				return handler(srv, ss)
+			}),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis, seen
+}
+
+func dial(t *testing.T, lis *bufconn.Listener, opts ...grpc.DialOption) healthpb.HealthClient {
+	t.Helper()
+
+	opts = append(opts,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryPropagation(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	lis, seen := startServer(t, r)
+	client := dial(t, lis, grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(r)))
+
+	// A request ID from the context is sent and verified
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.Check(rigid.ContextWithRequestID(context.Background(), id), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, id, <-seen)
+	assert.Equal(t, []string{id}, header.Get(DefaultMetadataKey))
+
+	// Without one, the client interceptor generates a fresh ID
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	generated := <-seen
+	assert.NotEqual(t, id, generated)
+	_, err = r.Verify(generated)
+	assert.NoError(t, err)
+}
+
+func TestServerAssignsMissingID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	lis, seen := startServer(t, r)
+	client := dial(t, lis)
+
+	var header metadata.MD
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	id := <-seen
+	assert.Equal(t, []string{id}, header.Get(DefaultMetadataKey))
+	_, err = r.Verify(id)
+	assert.NoError(t, err)
+}
+
+func TestServerRejectsForgedID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	attacker, err := rigid.NewRigid([]byte("attacker-key"))
+	require.NoError(t, err)
+
+	lis, _ := startServer(t, r)
+	client := dial(t, lis)
+
+	forged, err := attacker.Generate()
+	require.NoError(t, err)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), DefaultMetadataKey, forged)
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamPropagation(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	key := WithMetadataKey("x-correlation-id")
+	lis, seen := startServer(t, r, key)
+	client := dial(t, lis, grpc.WithChainStreamInterceptor(StreamClientInterceptor(r, key)))
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(rigid.ContextWithRequestID(context.Background(), id))
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, id, <-seen)
+
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{id}, header.Get("x-correlation-id"))
+	assert.Empty(t, header.Get(DefaultMetadataKey))
+}
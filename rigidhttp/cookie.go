@@ -0,0 +1,115 @@
+package rigidhttp
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// CookieOption customizes cookies written by SetCookie.
+type CookieOption func(*http.Cookie)
+
+// CookiePath sets the cookie path. The default is "/".
+func CookiePath(path string) CookieOption {
+	return func(c *http.Cookie) {
+		c.Path = path
+	}
+}
+
+// CookieDomain sets the cookie domain. By default the cookie is host-only.
+func CookieDomain(domain string) CookieOption {
+	return func(c *http.Cookie) {
+		c.Domain = domain
+	}
+}
+
+// CookieSameSite sets the SameSite attribute. The default is http.SameSiteLaxMode.
+func CookieSameSite(mode http.SameSite) CookieOption {
+	return func(c *http.Cookie) {
+		c.SameSite = mode
+	}
+}
+
+// CookieInsecure clears the Secure attribute, allowing the cookie over plain HTTP
+// during local development.
+func CookieInsecure() CookieOption {
+	return func(c *http.Cookie) {
+		c.Secure = false
+	}
+}
+
+// SetCookie generates a signed ID carrying metadata and sets it as the value of the cookie name.
+// Cookies are HttpOnly, Secure and SameSite=Lax unless changed by opts. If r has a TTL,
+// the cookie expires together with the ID; otherwise it is a session cookie.
+// Returns an error wrapping rigid.ErrInvalidMetadata if metadata contains characters
+// that are not allowed in cookie values, such as spaces, commas, semicolons and quotes.
+func SetCookie(w http.ResponseWriter, name string, r *rigid.Rigid, metadata string, opts ...CookieOption) (string, error) {
+	if !validCookieValue(metadata) {
+		return "", fmt.Errorf("%w: not a valid cookie value", rigid.ErrInvalidMetadata)
+	}
+
+	now := time.Now()
+	id, err := r.GenerateAt(now, metadata)
+	if err != nil {
+		return "", err
+	}
+
+	c := &http.Cookie{
+		Name:     name,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if ttl := r.TTL(); ttl > 0 {
+		c.Expires = now.Add(ttl)
+		c.MaxAge = int(ttl / time.Second)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	http.SetCookie(w, c)
+	return id, nil
+}
+
+// VerifyCookie verifies the value of the cookie name with r, returning http.ErrNoCookie if it is absent.
+// Expired cookie values are rejected with rigid.ErrExpired even if the client kept the cookie.
+func VerifyCookie(req *http.Request, name string, r *rigid.Rigid) (rigid.VerifyResult, error) {
+	c, err := req.Cookie(name)
+	if err != nil {
+		return rigid.VerifyResult{}, err
+	}
+	return Verify(req, r, c.Value)
+}
+
+// DeleteCookie instructs the client to remove the cookie name. The path and domain must match
+// those the cookie was set with.
+func DeleteCookie(w http.ResponseWriter, name string, opts ...CookieOption) {
+	c := &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	http.SetCookie(w, c)
+}
+
+// validCookieValue reports whether s can be stored in a cookie value without quoting or loss.
+func validCookieValue(s string) bool {
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b <= ' ' || b >= 0x7f || b == '"' || b == ',' || b == ';' || b == '\\' {
+			return false
+		}
+	}
+	return true
+}
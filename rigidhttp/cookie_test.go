@@ -0,0 +1,100 @@
+package rigidhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestSessionCookie(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	id, err := SetCookie(rec, "session", r, "user:alice")
+	require.NoError(t, err)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	c := cookies[0]
+	assert.Equal(t, id, c.Value)
+	assert.Equal(t, "/", c.Path)
+	assert.True(t, c.HttpOnly)
+	assert.True(t, c.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, c.SameSite)
+	assert.Zero(t, c.MaxAge)
+	assert.True(t, c.Expires.IsZero())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(c)
+	result, err := VerifyCookie(req, "session", r)
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+}
+
+func TestExpiringCookie(t *testing.T) {
+	base, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r := base.WithTTL(30 * time.Minute)
+
+	rec := httptest.NewRecorder()
+	_, err = SetCookie(rec, "session", r, "user:alice", CookiePath("/app"), CookieInsecure())
+	require.NoError(t, err)
+
+	c := rec.Result().Cookies()[0]
+	assert.Equal(t, 1800, c.MaxAge)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), c.Expires, 2*time.Second)
+	assert.Equal(t, "/app", c.Path)
+	assert.False(t, c.Secure)
+
+	// A client that ignores the cookie expiry is still rejected.
+	expired, err := r.GenerateAt(time.Now().Add(-time.Hour), "user:alice")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: expired})
+	_, err = VerifyCookie(req, "session", r)
+	assert.ErrorIs(t, err, rigid.ErrExpired)
+}
+
+func TestVerifyCookieErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = VerifyCookie(req, "session", r)
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "session", Value: id[:27] + "AAAAAAAAAAAAA-user:alice"})
+	_, err = VerifyCookie(req, "session", r)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
+
+func TestSetCookieRejectsUnsafeMetadata(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	for _, metadata := range []string{"user alice", "a;b", `"quoted"`, "a,b", "café"} {
+		rec := httptest.NewRecorder()
+		_, err := SetCookie(rec, "session", r, metadata)
+		assert.ErrorIs(t, err, rigid.ErrInvalidMetadata, metadata)
+		assert.Empty(t, rec.Result().Cookies())
+	}
+}
+
+func TestDeleteCookie(t *testing.T) {
+	rec := httptest.NewRecorder()
+	DeleteCookie(rec, "session", CookieDomain("example.com"))
+
+	c := rec.Result().Cookies()[0]
+	assert.Equal(t, "session", c.Name)
+	assert.Equal(t, -1, c.MaxAge)
+	assert.Equal(t, "example.com", c.Domain)
+}
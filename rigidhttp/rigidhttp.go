@@ -0,0 +1,118 @@
+// Package rigidhttp provides net/http middleware that assigns and verifies signed rigid request IDs.
+//
+// The middleware verifies the request ID received in the X-Request-ID header, rejecting
+// requests whose ID is forged or malformed with 400 Bad Request, and generates a fresh ID
+// when none is present. The ID is echoed in the response header and stored in the request
+// context, where RequestID and rigid.RequestIDFromContext retrieve it.
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/orders", func(w http.ResponseWriter, req *http.Request) {
+//		log.Printf("request %s", rigidhttp.RequestID(req))
+//	})
+//	http.ListenAndServe(":8080", rigidhttp.Middleware(r)(mux))
+//
+// Transport propagates the request ID to outgoing requests, so one signed ID correlates a request
+// across service hops. Services facing untrusted clients use WithTrustBoundary to replace invalid
+// IDs instead of rejecting the request; internal services keep rejecting them, so only the edge
+// ever regenerates an ID received from a client.
+//
+//	client := &http.Client{Transport: rigidhttp.Transport(r, nil)}
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://inventory/items", nil)
+//	resp, err := client.Do(req)
+//
+// SetCookie and VerifyCookie use signed IDs as session cookie values, expiring together
+// with the ID when the Rigid instance has a TTL.
+package rigidhttp
+
+import (
+	"net/http"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// DefaultHeader is the header carrying the request ID.
+const DefaultHeader = "X-Request-ID"
+
+type config struct {
+	header   string
+	boundary bool
+}
+
+// Option configures the middleware and the transport.
+type Option func(*config)
+
+// WithHeader sets the header carrying the request ID.
+func WithHeader(name string) Option {
+	return func(c *config) {
+		c.header = name
+	}
+}
+
+// WithTrustBoundary makes the middleware replace forged, malformed or otherwise invalid request IDs
+// with fresh ones instead of rejecting the request, for services receiving requests from outside
+// the trust boundary, such as public API gateways. Invalid IDs are still reported to the failure
+// hook of the instance. It has no effect on Transport.
+func WithTrustBoundary() Option {
+	return func(c *config) {
+		c.boundary = true
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{header: DefaultHeader}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Middleware returns middleware that verifies or assigns a signed request ID for every request.
+// Requests with an invalid ID are rejected with 400 Bad Request, unless WithTrustBoundary is set.
+// The request handed to the next handler carries the assigned ID in its header as well.
+func Middleware(r *rigid.Rigid, opts ...Option) func(http.Handler) http.Handler {
+	c := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			id := req.Header.Get(c.header)
+			if id != "" {
+				if _, err := Verify(req, r, id); err != nil {
+					if !c.boundary {
+						http.Error(w, "invalid request ID", http.StatusBadRequest)
+						return
+					}
+					id = ""
+				}
+			}
+			if id == "" {
+				var err error
+				if id, err = r.Generate(); err != nil {
+					http.Error(w, "failed to generate request ID", http.StatusInternalServerError)
+					return
+				}
+			}
+
+			w.Header().Set(c.header, id)
+			sent := req.Header.Get(c.header)
+			req = req.WithContext(rigid.ContextWithRequestID(req.Context(), id))
+			if sent != id {
+				// Replace the header of the shallow copy only, so a rejected ID is not forwarded
+				// by handlers or Transport
+				req.Header = req.Header.Clone()
+				req.Header.Set(c.header, id)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Verify verifies id with r on behalf of req, passing the request context to the configured stores
+// and the client address to the failure hook as the remote party, see rigid.WithFailureHook.
+func Verify(req *http.Request, r *rigid.Rigid, id string) (rigid.VerifyResult, error) {
+	return r.VerifyContext(rigid.ContextWithRemote(req.Context(), req.RemoteAddr), id)
+}
+
+// RequestID returns the request ID assigned by Middleware, or the empty string if there is none.
+func RequestID(req *http.Request) string {
+	id, _ := rigid.RequestIDFromContext(req.Context())
+	return id
+}
@@ -0,0 +1,168 @@
+package rigidhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newHandler(t *testing.T, r *rigid.Rigid, opts ...Option) (http.Handler, *string) {
+	t.Helper()
+
+	var seen string
+	h := Middleware(r, opts...)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = RequestID(req)
+	}))
+	return h, &seen
+}
+
+func TestMiddlewareAssignsID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	h, seen := newHandler(t, r)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotEmpty(t, *seen)
+	assert.Equal(t, *seen, rec.Header().Get(DefaultHeader))
+
+	_, err = r.Verify(*seen)
+	assert.NoError(t, err)
+}
+
+func TestMiddlewareKeepsVerifiedID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	h, seen := newHandler(t, r, WithHeader("X-Correlation-ID"))
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", id)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id, *seen)
+	assert.Equal(t, id, rec.Header().Get("X-Correlation-ID"))
+	assert.Empty(t, rec.Header().Get(DefaultHeader))
+}
+
+func TestMiddlewareRejectsForgedID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	attacker, err := rigid.NewRigid([]byte("attacker-key"))
+	require.NoError(t, err)
+
+	h, seen := newHandler(t, r)
+
+	forged, err := attacker.Generate()
+	require.NoError(t, err)
+
+	for _, id := range []string{forged, "not-a-rigid-id"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(DefaultHeader, id)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, id)
+		assert.Empty(t, *seen, "handler must not run for %q", id)
+	}
+}
+
+func TestMiddlewareTrustBoundary(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var seen, header string
+	h := Middleware(r, WithTrustBoundary())(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = RequestID(req)
+		header = req.Header.Get(DefaultHeader)
+	}))
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	for _, sent := range []string{"not-a-rigid-id", id} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(DefaultHeader, sent)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, seen, rec.Header().Get(DefaultHeader))
+		assert.Equal(t, seen, header, "the handler sees the replacement ID in the header")
+		_, err = r.Verify(seen)
+		assert.NoError(t, err)
+		assert.Equal(t, sent, req.Header.Get(DefaultHeader), "the caller's request is not modified")
+	}
+	assert.Equal(t, id, seen, "valid IDs are kept")
+}
+
+func TestMiddlewareTrustBoundaryForwardsFreshID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var forwarded string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		forwarded = req.Header.Get(DefaultHeader)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport(r, nil)}
+	h := Middleware(r, WithTrustBoundary())(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		out, err := http.NewRequestWithContext(req.Context(), http.MethodGet, upstream.URL, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		out.Header = req.Header.Clone()
+		resp, err := client.Do(out)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(DefaultHeader, "not-a-rigid-id")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, rec.Header().Get(DefaultHeader), forwarded)
+	_, err = r.Verify(forwarded)
+	assert.NoError(t, err, "the rejected ID does not cross the boundary")
+}
+
+func TestRequestIDWithoutMiddleware(t *testing.T) {
+	assert.Empty(t, RequestID(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestMiddlewareReportsRemote(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var failures []any
+	r = r.WithFailureHook(func(id string, err error, remote any) {
+		failures = append(failures, remote)
+	})
+	h, _ := newHandler(t, r)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set(DefaultHeader, "forged")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, []any{"203.0.113.7:51234"}, failures)
+}
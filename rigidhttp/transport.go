@@ -0,0 +1,47 @@
+package rigidhttp
+
+import (
+	"net/http"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// Transport returns a round tripper that sends the request ID found in the request context, as
+// stored by Middleware or rigid.ContextWithRequestID, in the request ID header of outgoing
+// requests, then passes them to base. Requests that do not originate from an identified request
+// are sent with a freshly generated ID, and requests that already carry the header are sent
+// unchanged. A nil base means http.DefaultTransport.
+func Transport(r *rigid.Rigid, base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{r: r, base: base, config: newConfig(opts)}
+}
+
+type transport struct {
+	r    *rigid.Rigid
+	base http.RoundTripper
+	config
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(t.header) != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	id, ok := rigid.RequestIDFromContext(req.Context())
+	if !ok {
+		var err error
+		if id, err = t.r.Generate(); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, id)
+	return t.base.RoundTrip(req)
+}
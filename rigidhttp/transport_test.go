@@ -0,0 +1,85 @@
+package rigidhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+func TestTransportPropagatesID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var internalID string
+	internal := httptest.NewServer(Middleware(r)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		internalID = RequestID(req)
+	})))
+	defer internal.Close()
+
+	client := &http.Client{Transport: Transport(r, nil)}
+	var edgeID string
+	edge := httptest.NewServer(Middleware(r, WithTrustBoundary())(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		edgeID = RequestID(req)
+		out, _ := http.NewRequestWithContext(req.Context(), http.MethodGet, internal.URL, nil)
+		resp, err := client.Do(out)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+	})))
+	defer edge.Close()
+
+	req, err := http.NewRequest(http.MethodGet, edge.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(DefaultHeader, "forged-by-client")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, edgeID)
+	assert.NotEqual(t, "forged-by-client", edgeID, "the edge replaces invalid IDs")
+	assert.Equal(t, edgeID, internalID, "internal hops keep the ID of the edge")
+	assert.Equal(t, edgeID, resp.Header.Get(DefaultHeader))
+}
+
+func TestTransportHeader(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	var sent []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.Header.Get("X-Correlation-ID"))
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	rt := Transport(r, base, WithHeader("X-Correlation-ID"))
+
+	// Without a request ID in the context, a fresh one is generated
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	_, err = r.Verify(sent[0])
+	assert.NoError(t, err)
+	assert.Empty(t, req.Header.Get("X-Correlation-ID"), "the request is not modified")
+
+	// Headers set by the caller are kept
+	req.Header.Set("X-Correlation-ID", "explicit")
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, "explicit", sent[1])
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,53 @@
+// Package rigidkgo adapts rigidmsg event IDs to franz-go Kafka records.
+//
+//	s := rigidmsg.New(r)
+//	rec := &kgo.Record{Topic: "orders", Value: body}
+//	id, err := rigidkgo.Stamp(s, rec, "order:42")
+//	...
+//	result, err := rigidkgo.Verify(s, fetched)
+package rigidkgo
+
+import (
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+// Stamp writes a new signed event ID to the headers of rec.
+func Stamp(s *rigidmsg.Stamper, rec *kgo.Record, metadata ...string) (string, error) {
+	return s.Stamp(Carrier(rec), metadata...)
+}
+
+// Verify verifies the event ID carried in the headers of rec.
+func Verify(s *rigidmsg.Stamper, rec *kgo.Record) (rigid.VerifyResult, error) {
+	return s.Verify(Carrier(rec))
+}
+
+// Carrier returns a rigidmsg.Carrier over the headers of rec.
+func Carrier(rec *kgo.Record) rigidmsg.Carrier {
+	return carrier{rec}
+}
+
+type carrier struct {
+	rec *kgo.Record
+}
+
+func (c carrier) Get(key string) string {
+	for _, h := range c.rec.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c carrier) Set(key, value string) {
+	headers := c.rec.Headers[:0]
+	for _, h := range c.rec.Headers {
+		if h.Key != key {
+			headers = append(headers, h)
+		}
+	}
+	c.rec.Headers = append(headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+}
@@ -0,0 +1,54 @@
+package rigidkgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestStampAndVerify(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := rigidmsg.New(r, rigidmsg.WithHeader("event-id"))
+
+	rec := &kgo.Record{
+		Topic:   "orders",
+		Headers: []kgo.RecordHeader{{Key: "trace", Value: []byte("abc")}},
+	}
+	_, err = Stamp(s, rec)
+	require.NoError(t, err)
+	id, err := Stamp(s, rec, "order:42")
+	require.NoError(t, err)
+
+	require.Len(t, rec.Headers, 2)
+	assert.Equal(t, "trace", rec.Headers[0].Key)
+	assert.Equal(t, kgo.RecordHeader{Key: "event-id", Value: []byte(id)}, rec.Headers[1])
+
+	result, err := Verify(s, rec)
+	require.NoError(t, err)
+	assert.Equal(t, "order:42", result.Metadata)
+}
+
+func TestVerifyErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := rigidmsg.New(r)
+
+	_, err = Verify(s, &kgo.Record{})
+	assert.ErrorIs(t, err, rigidmsg.ErrMissingID)
+
+	rec := &kgo.Record{}
+	id, err := Stamp(s, rec, "amount:10")
+	require.NoError(t, err)
+	rec.Headers[0].Value = []byte(id[:len(id)-2] + "99")
+
+	_, err = Verify(s, rec)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
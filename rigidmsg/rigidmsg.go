@@ -0,0 +1,101 @@
+// Package rigidmsg stamps messages with signed rigid event IDs and verifies them on consume.
+//
+// A Stamper writes a freshly generated ID into a message header when publishing, and
+// consumers verify the header before processing, rejecting events whose ID was forged or
+// tampered with in transit. Only the ID and its metadata are signed, not the message payload:
+// a valid header can be copied onto another body, so payloads needing integrity must be signed
+// separately. Messages are accessed through the Carrier interface; the
+// rigidsarama, rigidkgo and rigidnats packages adapt Kafka and NATS clients.
+//
+//	s := rigidmsg.New(r)
+//	id, err := s.Stamp(carrier, "order:42")
+//	...
+//	result, err := s.Verify(carrier)
+package rigidmsg
+
+import (
+	"errors"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// DefaultHeader is the header carrying the event ID.
+const DefaultHeader = "rigid-event-id"
+
+// ErrMissingID is returned by Verify when the message carries no event ID.
+var ErrMissingID = errors.New("message has no event ID")
+
+// Carrier reads and writes message headers.
+type Carrier interface {
+	// Get returns the value of the header key, or "" if absent.
+	Get(key string) string
+	// Set sets the header key to value, replacing any existing values.
+	Set(key, value string)
+}
+
+type config struct {
+	header string
+}
+
+// Option configures a Stamper.
+type Option func(*config)
+
+// WithHeader sets the header carrying the event ID.
+func WithHeader(name string) Option {
+	return func(c *config) {
+		c.header = name
+	}
+}
+
+// Stamper stamps and verifies message event IDs.
+type Stamper struct {
+	r      *rigid.Rigid
+	header string
+}
+
+// New returns a Stamper generating and verifying IDs with r.
+func New(r *rigid.Rigid, opts ...Option) *Stamper {
+	c := config{header: DefaultHeader}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Stamper{r: r, header: c.header}
+}
+
+// Header returns the header carrying the event ID.
+func (s *Stamper) Header() string {
+	return s.header
+}
+
+// Stamp generates a signed event ID with the given metadata and writes it to the carrier.
+// The signature covers the ID and metadata only, not the message payload.
+func (s *Stamper) Stamp(c Carrier, metadata ...string) (string, error) {
+	id, err := s.r.Generate(metadata...)
+	if err != nil {
+		return "", err
+	}
+	c.Set(s.header, id)
+	return id, nil
+}
+
+// Verify verifies the event ID carried by the message, returning ErrMissingID if it has none.
+func (s *Stamper) Verify(c Carrier) (rigid.VerifyResult, error) {
+	id := c.Get(s.header)
+	if id == "" {
+		return rigid.VerifyResult{}, ErrMissingID
+	}
+	return s.r.Verify(id)
+}
+
+// MapCarrier is a Carrier backed by a map, for transports whose headers are plain string maps.
+type MapCarrier map[string]string
+
+// Get returns the value of the header key.
+func (m MapCarrier) Get(key string) string {
+	return m[key]
+}
+
+// Set sets the header key to value.
+func (m MapCarrier) Set(key, value string) {
+	m[key] = value
+}
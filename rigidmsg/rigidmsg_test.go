@@ -0,0 +1,58 @@
+package rigidmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestStampAndVerify(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := New(r)
+
+	c := MapCarrier{}
+	id, err := s.Stamp(c, "order:42")
+	require.NoError(t, err)
+	assert.Equal(t, id, c[DefaultHeader])
+
+	result, err := s.Verify(c)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, "order:42", result.Metadata)
+}
+
+func TestStampReplacesExistingID(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := New(r, WithHeader("event-id"))
+	assert.Equal(t, "event-id", s.Header())
+
+	c := MapCarrier{"event-id": "stale"}
+	id, err := s.Stamp(c)
+	require.NoError(t, err)
+	assert.Equal(t, id, c["event-id"])
+}
+
+func TestVerifyErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := New(r)
+
+	_, err = s.Verify(MapCarrier{})
+	assert.ErrorIs(t, err, ErrMissingID)
+
+	other, err := rigid.NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+	c := MapCarrier{}
+	_, err = New(other).Stamp(c)
+	require.NoError(t, err)
+
+	_, err = s.Verify(c)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
@@ -0,0 +1,31 @@
+// Package rigidnats adapts rigidmsg event IDs to NATS messages.
+//
+//	s := rigidmsg.New(r)
+//	msg := nats.NewMsg("orders.created")
+//	id, err := rigidnats.Stamp(s, msg, "order:42")
+//	err = nc.PublishMsg(msg)
+//	...
+//	nc.Subscribe("orders.created", func(msg *nats.Msg) {
+//		result, err := rigidnats.Verify(s, msg)
+//	})
+package rigidnats
+
+import (
+	"github.com/nats-io/nats.go"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+// Stamp writes a new signed event ID to the headers of msg, allocating them if needed.
+func Stamp(s *rigidmsg.Stamper, msg *nats.Msg, metadata ...string) (string, error) {
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	return s.Stamp(msg.Header, metadata...)
+}
+
+// Verify verifies the event ID carried in the headers of msg.
+func Verify(s *rigidmsg.Stamper, msg *nats.Msg) (rigid.VerifyResult, error) {
+	return s.Verify(msg.Header)
+}
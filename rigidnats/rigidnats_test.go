@@ -0,0 +1,46 @@
+package rigidnats
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestStampAndVerify(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := rigidmsg.New(r)
+
+	msg := &nats.Msg{Subject: "orders.created"}
+	id, err := Stamp(s, msg, "order:42")
+	require.NoError(t, err)
+	assert.Equal(t, id, msg.Header.Get(rigidmsg.DefaultHeader))
+
+	result, err := Verify(s, msg)
+	require.NoError(t, err)
+	assert.Equal(t, "order:42", result.Metadata)
+}
+
+func TestVerifyErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	s := rigidmsg.New(r)
+
+	_, err = Verify(s, &nats.Msg{})
+	assert.ErrorIs(t, err, rigidmsg.ErrMissingID)
+
+	msg := nats.NewMsg("orders.created")
+	id, err := Stamp(s, msg)
+	require.NoError(t, err)
+	msg.Header.Set(rigidmsg.DefaultHeader, id[:len(id)-2]+"99")
+
+	_, err = Verify(s, msg)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
@@ -0,0 +1,163 @@
+// Package rigidotel instruments rigid ID generation and verification with OpenTelemetry spans
+// and carries signed IDs in trace baggage.
+//
+// Tracer wraps a Rigid instance and records a span for every Generate and Verify call. Spans
+// carry the ULID of the ID but never its signature, so traces do not leak usable IDs.
+//
+//	t := rigidotel.New(r)
+//	id, err := t.Generate(ctx, "order:42")
+//
+// ContextWithID attaches a signed ID to the baggage of a context, which the configured
+// propagator forwards to downstream services, where VerifyBaggage checks it:
+//
+//	ctx, err := rigidotel.ContextWithID(ctx, orderID)
+//	...
+//	result, err := rigidotel.VerifyBaggage(ctx, r)
+package rigidotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/bahadrix/rigid-go/rigidotel"
+
+// DefaultBaggageKey is the baggage key carrying the signed ID.
+const DefaultBaggageKey = "rigid.id"
+
+// Span attribute keys.
+const (
+	ULIDKey  = attribute.Key("rigid.ulid")
+	ValidKey = attribute.Key("rigid.valid")
+)
+
+// ErrNoID is returned by VerifyBaggage when the baggage carries no ID.
+var ErrNoID = errors.New("no rigid ID in baggage")
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	baggageKey     string
+}
+
+// Option configures the tracer and the baggage helpers.
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. The global provider is used by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithBaggageKey sets the baggage key carrying the signed ID.
+func WithBaggageKey(key string) Option {
+	return func(c *config) {
+		c.baggageKey = key
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{baggageKey: DefaultBaggageKey}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	return c
+}
+
+// Tracer wraps a Rigid instance, recording a span for every Generate and Verify call.
+type Tracer struct {
+	r      *rigid.Rigid
+	tracer trace.Tracer
+}
+
+// New returns a Tracer instrumenting r.
+func New(r *rigid.Rigid, opts ...Option) *Tracer {
+	c := newConfig(opts)
+	return &Tracer{r: r, tracer: c.tracerProvider.Tracer(ScopeName)}
+}
+
+// Rigid returns the wrapped instance.
+func (t *Tracer) Rigid() *rigid.Rigid {
+	return t.r
+}
+
+// Generate creates a new signed ID within a "rigid.Generate" span.
+func (t *Tracer) Generate(ctx context.Context, metadata ...string) (string, error) {
+	_, span := t.tracer.Start(ctx, "rigid.Generate", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	id, err := t.r.Generate(metadata...)
+	if err != nil {
+		fail(span, err)
+		return "", err
+	}
+	if u, err := t.r.ExtractULID(id); err == nil {
+		span.SetAttributes(ULIDKey.String(u.String()))
+	}
+	return id, nil
+}
+
+// Verify checks the integrity of id within a "rigid.Verify" span, passing the span context to
+// the stores, rate limiter and hooks of the instance as rigid.Rigid.VerifyContext does.
+func (t *Tracer) Verify(ctx context.Context, id string) (rigid.VerifyResult, error) {
+	ctx, span := t.tracer.Start(ctx, "rigid.Verify", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	result, err := t.r.VerifyContext(ctx, id)
+	if u, uerr := t.r.ExtractULID(id); uerr == nil {
+		span.SetAttributes(ULIDKey.String(u.String()))
+	}
+	span.SetAttributes(ValidKey.Bool(err == nil && result.Valid))
+	if err != nil {
+		fail(span, err)
+	}
+	return result, err
+}
+
+func fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// ContextWithID returns ctx with id stored in its baggage, replacing any ID already present.
+func ContextWithID(ctx context.Context, id string, opts ...Option) (context.Context, error) {
+	c := newConfig(opts)
+	member, err := baggage.NewMemberRaw(c.baggageKey, id)
+	if err != nil {
+		return ctx, fmt.Errorf("rigidotel: %w", err)
+	}
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("rigidotel: %w", err)
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// IDFromContext returns the ID stored in the baggage of ctx without verifying it.
+func IDFromContext(ctx context.Context, opts ...Option) (string, bool) {
+	c := newConfig(opts)
+	id := baggage.FromContext(ctx).Member(c.baggageKey).Value()
+	return id, id != ""
+}
+
+// VerifyBaggage verifies the ID stored in the baggage of ctx with ctx, returning ErrNoID if there is none.
+func VerifyBaggage(ctx context.Context, r *rigid.Rigid, opts ...Option) (rigid.VerifyResult, error) {
+	id, ok := IDFromContext(ctx, opts...)
+	if !ok {
+		return rigid.VerifyResult{}, ErrNoID
+	}
+	return r.VerifyContext(ctx, id)
+}
@@ -0,0 +1,193 @@
+package rigidotel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newTracer(t *testing.T) (*Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	return New(r, WithTracerProvider(tp)), rec
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracerGenerateAndVerify(t *testing.T) {
+	tr, rec := newTracer(t)
+	ctx := context.Background()
+
+	id, err := tr.Generate(ctx, "order:42")
+	require.NoError(t, err)
+
+	result, err := tr.Verify(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "rigid.Generate", spans[0].Name())
+	assert.Equal(t, id[:26], attrs(spans[0])[ULIDKey].AsString())
+
+	assert.Equal(t, "rigid.Verify", spans[1].Name())
+	assert.Equal(t, id[:26], attrs(spans[1])[ULIDKey].AsString())
+	assert.True(t, attrs(spans[1])[ValidKey].AsBool())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+
+	for _, span := range spans {
+		for _, kv := range span.Attributes() {
+			assert.NotEqual(t, id, kv.Value.AsString(), "spans must not carry the signed ID")
+		}
+	}
+}
+
+func TestTracerVerifyFailure(t *testing.T) {
+	tr, rec := newTracer(t)
+
+	id, err := tr.Rigid().Generate()
+	require.NoError(t, err)
+
+	_, err = tr.Verify(context.Background(), id[:27]+"AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.False(t, attrs(spans[0])[ValidKey].AsBool())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+// spanStore is a rigid.RevocationStore recording the span context it is consulted with.
+type spanStore struct {
+	seen trace.SpanContext
+}
+
+func (s *spanStore) Revoke(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (s *spanStore) IsRevoked(ctx context.Context, _ string) (bool, error) {
+	s.seen = trace.SpanContextFromContext(ctx)
+	return false, nil
+}
+
+func TestTracerVerifyPassesContext(t *testing.T) {
+	tr, rec := newTracer(t)
+	store := &spanStore{}
+	var remote any
+	tr.r = tr.r.WithRevocationStore(store).WithFailureHook(func(_ string, _ error, r any) {
+		remote = r
+	})
+
+	id, err := tr.Rigid().Generate()
+	require.NoError(t, err)
+	ctx := rigid.ContextWithRemote(context.Background(), "192.0.2.1")
+
+	_, err = tr.Verify(ctx, id)
+	require.NoError(t, err)
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), store.seen.SpanID(), "stores run within the span")
+
+	_, err = tr.Verify(ctx, id[:27]+"AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+	assert.Equal(t, "192.0.2.1", remote)
+
+	remote = nil
+	other, err := rigid.NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+	forged, err := other.Generate()
+	require.NoError(t, err)
+	ctx, err = ContextWithID(ctx, forged)
+	require.NoError(t, err)
+	_, err = VerifyBaggage(ctx, tr.Rigid())
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+	assert.Equal(t, "192.0.2.1", remote)
+}
+
+func TestBaggagePropagation(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate("tenant:acme, region=eu")
+	require.NoError(t, err)
+
+	ctx, err := ContextWithID(context.Background(), id)
+	require.NoError(t, err)
+
+	got, ok := IDFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, id, got)
+
+	// Round trip through the W3C baggage propagator, as between services.
+	header := http.Header{}
+	propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(header))
+	remote := propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	result, err := VerifyBaggage(remote, r)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant:acme, region=eu", result.Metadata)
+}
+
+func TestBaggageCustomKey(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	ctx, err := ContextWithID(context.Background(), id, WithBaggageKey("order.id"))
+	require.NoError(t, err)
+
+	_, ok := IDFromContext(ctx)
+	assert.False(t, ok)
+
+	_, err = VerifyBaggage(ctx, r, WithBaggageKey("order.id"))
+	assert.NoError(t, err)
+}
+
+func TestVerifyBaggageErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	_, err = VerifyBaggage(context.Background(), r)
+	assert.ErrorIs(t, err, ErrNoID)
+
+	other, err := rigid.NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+	forged, err := other.Generate()
+	require.NoError(t, err)
+
+	ctx, err := ContextWithID(context.Background(), forged)
+	require.NoError(t, err)
+	_, err = VerifyBaggage(ctx, r)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
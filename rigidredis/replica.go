@@ -0,0 +1,206 @@
+package rigidredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// ErrReplicaStale is returned by Replica.IsRevoked when the replica has not synchronized with
+// Redis for too long to answer.
+var ErrReplicaStale = errors.New("revocation replica is stale")
+
+// event is a revocation change published by the store.
+type event struct {
+	ULID      string     `json:"ulid"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Unrevoked bool       `json:"unrevoked,omitempty"`
+}
+
+func encodeEvent(e event) string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+// Replica is a rigid.RevocationStore answering lookups from an in-memory copy of the revocations
+// of a Store, for verifiers that should not make a round trip to Redis per ID. The copy is loaded
+// when the replica starts and kept current with the revocations the store publishes, so a
+// revocation made on one instance reaches every replica within the delivery latency of Redis
+// pub/sub. Published messages are not persisted, so the replica also reloads the revocations
+// whenever its subscription reconnects and every resync interval, bounding how long a missed
+// message goes unnoticed.
+//
+// Reloads list revocations as Store.ListRevoked does, so a replica of a cluster sees the
+// revocations of a single node only.
+type Replica struct {
+	store    *Store
+	sub      *redis.PubSub
+	interval time.Duration
+	now      func() time.Time
+
+	mu sync.RWMutex
+	// revoked maps ULIDs to their expiry, the zero time meaning never.
+	revoked  map[string]time.Time
+	lastSync time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	_ rigid.RevocationStore  = (*Replica)(nil)
+	_ rigid.RevocationLister = (*Replica)(nil)
+)
+
+// NewReplica subscribes to the revocations published by s, loads the current ones and returns a
+// Replica reloading them every resyncInterval. A replica that has not reloaded successfully for
+// three intervals fails lookups with ErrReplicaStale rather than accepting IDs whose revocation
+// it may have missed. Close the replica to stop it.
+func (s *Store) NewReplica(ctx context.Context, resyncInterval time.Duration) (*Replica, error) {
+	if resyncInterval <= 0 {
+		return nil, fmt.Errorf("resync interval must be positive, got %s", resyncInterval)
+	}
+
+	// Subscribe before loading, so no revocation falls between the two
+	sub := s.client.Subscribe(ctx, s.channel())
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+	r := &Replica{store: s, sub: sub, interval: resyncInterval, now: time.Now, done: make(chan struct{})}
+	if err := r.resync(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	loopCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.cancel = cancel
+	go r.run(loopCtx)
+	return r, nil
+}
+
+// Revoke revokes the ID in the store, which publishes the revocation to every replica, and
+// applies it to this replica at once.
+func (r *Replica) Revoke(ctx context.Context, ulid string, ttl time.Duration) error {
+	if err := r.store.Revoke(ctx, ulid, ttl); err != nil {
+		return err
+	}
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = r.now().Add(ttl)
+	}
+	r.mu.Lock()
+	r.revoked[ulid] = expiresAt
+	r.mu.Unlock()
+	return nil
+}
+
+// IsRevoked reports whether the ID with the given ULID has been revoked, without querying Redis.
+// Returns ErrReplicaStale if the replica has not synchronized for three resync intervals.
+func (r *Replica) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if now.Sub(r.lastSync) >= 3*r.interval {
+		return false, ErrReplicaStale
+	}
+	expiresAt, ok := r.revoked[ulid]
+	return ok && (expiresAt.IsZero() || now.Before(expiresAt)), nil
+}
+
+// ListRevoked returns the revocations held by the replica, ordered by ULID.
+func (r *Replica) ListRevoked(context.Context) ([]rigid.Revocation, error) {
+	now := r.now()
+	r.mu.RLock()
+	revocations := make([]rigid.Revocation, 0, len(r.revoked))
+	for u, expiresAt := range r.revoked {
+		if expiresAt.IsZero() || now.Before(expiresAt) {
+			revocations = append(revocations, rigid.Revocation{ULID: u, ExpiresAt: expiresAt})
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(revocations, func(a, b rigid.Revocation) int { return strings.Compare(a.ULID, b.ULID) })
+	return revocations, nil
+}
+
+// Close unsubscribes the replica and stops its reloads.
+func (r *Replica) Close() error {
+	r.cancel()
+	err := r.sub.Close()
+	<-r.done
+	return err
+}
+
+// run applies published revocations and reloads the revocations until ctx is canceled.
+func (r *Replica) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	messages := r.sub.ChannelWithSubscriptions()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			switch msg := msg.(type) {
+			case *redis.Message:
+				r.apply(msg.Payload)
+			case *redis.Subscription:
+				// The subscription reconnected, and may have missed messages meanwhile. Messages
+				// published during the reload queue up and are applied after it, in order.
+				_ = r.resync(ctx)
+			}
+		case <-ticker.C:
+			_ = r.resync(ctx)
+		}
+	}
+}
+
+// apply applies a published revocation change. Malformed messages are ignored.
+func (r *Replica) apply(payload string) {
+	var e event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil || e.ULID == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case e.Unrevoked:
+		delete(r.revoked, e.ULID)
+	case e.ExpiresAt != nil:
+		r.revoked[e.ULID] = *e.ExpiresAt
+	default:
+		r.revoked[e.ULID] = time.Time{}
+	}
+}
+
+// resync replaces the revocations of the replica with those of the store.
+func (r *Replica) resync(ctx context.Context) error {
+	revocations, err := r.store.ListRevoked(ctx)
+	if err != nil {
+		return err
+	}
+	revoked := make(map[string]time.Time, len(revocations))
+	for _, rev := range revocations {
+		revoked[rev.ULID] = rev.ExpiresAt
+	}
+	now := r.now()
+	r.mu.Lock()
+	r.revoked = revoked
+	r.lastSync = now
+	r.mu.Unlock()
+	return nil
+}
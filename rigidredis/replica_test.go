@@ -0,0 +1,131 @@
+package rigidredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// eventually asserts that the replica reports the ULID as revoked or not within a second.
+func eventually(t *testing.T, replica *Replica, ulid string, revoked bool) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		got, err := replica.IsRevoked(context.Background(), ulid)
+		return err == nil && got == revoked
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestReplica(t *testing.T) {
+	store, _ := newStore(t)
+	ctx := context.Background()
+	require.NoError(t, store.Revoke(ctx, "before", time.Hour))
+
+	replica, err := store.NewReplica(ctx, time.Hour)
+	require.NoError(t, err)
+	defer replica.Close()
+	other, err := store.NewReplica(ctx, time.Hour)
+	require.NoError(t, err)
+	defer other.Close()
+
+	// Revocations made before the replica started are loaded
+	revoked, err := replica.IsRevoked(ctx, "before")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Revocations made through the store or another replica are published
+	require.NoError(t, store.RevokeAll(ctx, []string{"a", "b"}, 0))
+	require.NoError(t, other.Revoke(ctx, "c", time.Hour))
+	for _, u := range []string{"a", "b", "c"} {
+		eventually(t, replica, u, true)
+	}
+	revoked, err = other.IsRevoked(ctx, "c")
+	require.NoError(t, err)
+	assert.True(t, revoked, "a replica applies its own revocations at once")
+
+	require.NoError(t, store.Unrevoke(ctx, "a"))
+	eventually(t, replica, "a", false)
+
+	list, err := replica.ListRevoked(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, []string{"b", "before", "c"}, []string{list[0].ULID, list[1].ULID, list[2].ULID})
+	assert.True(t, list[0].ExpiresAt.IsZero())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), list[2].ExpiresAt, time.Minute)
+}
+
+func TestReplicaVerification(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+	replica, err := store.NewReplica(ctx, time.Hour)
+	require.NoError(t, err)
+	defer replica.Close()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithRevocationStore(replica)
+	id, err := r.Generate()
+	require.NoError(t, err)
+	res, err := r.Verify(id)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Revoke(ctx, res.ULID, time.Hour))
+	eventually(t, replica, res.ULID, true)
+
+	// Lookups are answered from memory
+	mr.Close()
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, rigid.ErrRevoked)
+}
+
+func TestReplicaResync(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+	replica, err := store.NewReplica(ctx, 20*time.Millisecond)
+	require.NoError(t, err)
+	defer replica.Close()
+
+	// Changes made without publishing are picked up by the periodic reload
+	require.NoError(t, mr.Set(store.revokedKey("silent"), "1"))
+	eventually(t, replica, "silent", true)
+	mr.Del(store.revokedKey("silent"))
+	eventually(t, replica, "silent", false)
+
+	// Malformed messages are ignored
+	mr.Publish(store.channel(), "not json")
+	mr.Publish(store.channel(), `{"ulid":""}`)
+	revoked, err := replica.IsRevoked(ctx, "silent")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestReplicaStale(t *testing.T) {
+	store, _ := newStore(t)
+	ctx := context.Background()
+	replica, err := store.NewReplica(ctx, time.Minute)
+	require.NoError(t, err)
+	defer replica.Close()
+
+	now := time.Now()
+	replica.mu.Lock()
+	replica.now = func() time.Time { return now.Add(3 * time.Minute) }
+	replica.mu.Unlock()
+	_, err = replica.IsRevoked(ctx, "a")
+	assert.ErrorIs(t, err, ErrReplicaStale)
+}
+
+func TestReplicaErrors(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+
+	_, err := store.NewReplica(ctx, 0)
+	assert.Error(t, err)
+
+	mr.Close()
+	_, err = store.NewReplica(ctx, time.Minute)
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+// Package rigidredis provides Redis-backed rigid.RevocationStore and rigid.ReplayStore
+// implementations, so verifier instances share revocation and replay state.
+//
+//	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	store := rigidredis.New(client)
+//	r = r.WithRevocationStore(store).WithReplayStore(store, time.Hour)
+//
+// Entries are plain keys under a configurable prefix and expire with the TTL passed to the
+// store, so Redis forgets revocations once the IDs they cover can no longer be presented.
+// Revocations are also published on a channel, so verifiers can keep them in memory with a
+// Replica instead of querying Redis for every ID.
+package rigidredis
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// DefaultKeyPrefix is the prefix of the keys written by the store.
+const DefaultKeyPrefix = "rigid:"
+
+// Store is a Redis-backed revocation and replay store.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var (
+	_ rigid.RevocationStore        = (*Store)(nil)
+	_ rigid.RevocationLister       = (*Store)(nil)
+	_ rigid.RevocationBatchChecker = (*Store)(nil)
+	_ rigid.ReplayStore            = (*Store)(nil)
+)
+
+type config struct {
+	prefix string
+}
+
+// Option configures a Store.
+type Option func(*config)
+
+// WithKeyPrefix sets the prefix of the keys written by the store.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// New returns a Store using client, which may be a single-node, cluster or sentinel client.
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	c := config{prefix: DefaultKeyPrefix}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Store{client: client, prefix: c.prefix}
+}
+
+// Revoke marks the ID with the given ULID as revoked for ttl, or indefinitely if ttl is zero,
+// and publishes the revocation to replicas.
+func (s *Store) Revoke(ctx context.Context, ulid string, ttl time.Duration) error {
+	return s.RevokeAll(ctx, []string{ulid}, ttl)
+}
+
+// IsRevoked reports whether the ID with the given ULID has been revoked.
+func (s *Store) IsRevoked(ctx context.Context, ulid string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.revokedKey(ulid)).Result()
+	return n > 0, err
+}
+
+// AreRevoked reports for each ULID whether it has been revoked, checking them in a single pipeline.
+func (s *Store) AreRevoked(ctx context.Context, ulids []string) ([]bool, error) {
+	cmds := make([]*redis.IntCmd, len(ulids))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, u := range ulids {
+			cmds[i] = p.Exists(ctx, s.revokedKey(u))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make([]bool, len(ulids))
+	for i, cmd := range cmds {
+		revoked[i] = cmd.Val() > 0
+	}
+	return revoked, nil
+}
+
+// RevokeAll marks the IDs with the given ULIDs as revoked in a single pipeline, and publishes
+// the revocations to replicas.
+func (s *Store) RevokeAll(ctx context.Context, ulids []string, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl).UTC()
+		expiresAt = &t
+	}
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, u := range ulids {
+			p.Set(ctx, s.revokedKey(u), 1, ttl)
+			p.Publish(ctx, s.channel(), encodeEvent(event{ULID: u, ExpiresAt: expiresAt}))
+		}
+		return nil
+	})
+	return err
+}
+
+// Unrevoke removes the revocation of the ID with the given ULID, and publishes the removal to
+// replicas.
+func (s *Store) Unrevoke(ctx context.Context, ulid string) error {
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, s.revokedKey(ulid))
+		p.Publish(ctx, s.channel(), encodeEvent(event{ULID: ulid, Unrevoked: true}))
+		return nil
+	})
+	return err
+}
+
+// ListRevoked returns the revocations under the store's key prefix, ordered by ULID.
+// Keys are enumerated with SCAN, so the list may miss or repeat entries changed while it runs;
+// on a cluster client only the node serving the command is scanned.
+func (s *Store) ListRevoked(ctx context.Context) ([]rigid.Revocation, error) {
+	prefix := s.revokedKey("")
+	var keys []string
+	iter := s.client.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	cmds := make([]*redis.DurationCmd, len(keys))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, k := range keys {
+			cmds[i] = p.PTTL(ctx, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	revocations := make([]rigid.Revocation, 0, len(keys))
+	for i, k := range keys {
+		ttl := cmds[i].Val()
+		if ttl == -2 {
+			// Expired or deleted since the scan
+			continue
+		}
+		rev := rigid.Revocation{ULID: strings.TrimPrefix(k, prefix)}
+		if ttl > 0 {
+			rev.ExpiresAt = now.Add(ttl)
+		}
+		revocations = append(revocations, rev)
+	}
+	return revocations, nil
+}
+
+// MarkUsed atomically records a use of the ID with the given ULID and reports whether it was
+// the first. The record expires after ttl, or never if ttl is zero.
+func (s *Store) MarkUsed(ctx context.Context, ulid string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.usedKey(ulid), 1, ttl).Result()
+}
+
+func (s *Store) revokedKey(ulid string) string {
+	return s.prefix + "revoked:" + ulid
+}
+
+func (s *Store) usedKey(ulid string) string {
+	return s.prefix + "used:" + ulid
+}
+
+// channel is the channel revocations are published on.
+func (s *Store) channel() string {
+	return s.prefix + "revocations"
+}
@@ -0,0 +1,133 @@
+package rigidredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, opts...), mr
+}
+
+func TestRevocation(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithRevocationStore(store)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(ctx, id, time.Hour))
+
+	assert.True(t, mr.Exists("rigid:revoked:"+id[:26]))
+	assert.Equal(t, time.Hour, mr.TTL("rigid:revoked:"+id[:26]))
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, rigid.ErrRevoked)
+
+	mr.FastForward(time.Hour)
+	_, err = r.Verify(id)
+	assert.NoError(t, err)
+}
+
+func TestRevokeWithoutTTL(t *testing.T) {
+	store, mr := newStore(t, WithKeyPrefix("app:"))
+	ctx := context.Background()
+
+	require.NoError(t, store.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", 0))
+	assert.True(t, mr.Exists("app:revoked:01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	assert.Zero(t, mr.TTL("app:revoked:01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+
+	require.NoError(t, store.Unrevoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	revoked, err := store.IsRevoked(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestBatch(t *testing.T) {
+	store, _ := newStore(t)
+	ctx := context.Background()
+
+	ulids := []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "01BX5ZZKBKACTAV9WEVGEMMVRZ", "01BX5ZZKBKACTAV9WEVGEMMVS0"}
+	require.NoError(t, store.RevokeAll(ctx, []string{ulids[0], ulids[2]}, time.Minute))
+
+	revoked, err := store.AreRevoked(ctx, ulids)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, revoked)
+
+	revoked, err = store.AreRevoked(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, revoked)
+}
+
+func TestListRevoked(t *testing.T) {
+	store, mr := newStore(t)
+	ctx := context.Background()
+
+	revocations, err := store.ListRevoked(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, revocations)
+
+	require.NoError(t, store.Revoke(ctx, "01BX5ZZKBKACTAV9WEVGEMMVRZ", time.Hour))
+	require.NoError(t, store.Revoke(ctx, "01ARZ3NDEKTSV4RRFFQ69G5FAV", 0))
+	_, err = store.MarkUsed(ctx, "01BX5ZZKBKACTAV9WEVGEMMVS0", 0)
+	require.NoError(t, err)
+	mr.Set("other:revoked:01BX5ZZKBKACTAV9WEVGEMMVS1", "1")
+
+	revocations, err = store.ListRevoked(ctx)
+	require.NoError(t, err)
+	require.Len(t, revocations, 2)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", revocations[0].ULID)
+	assert.True(t, revocations[0].ExpiresAt.IsZero())
+	assert.Equal(t, "01BX5ZZKBKACTAV9WEVGEMMVRZ", revocations[1].ULID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), revocations[1].ExpiresAt, time.Minute)
+}
+
+func TestReplay(t *testing.T) {
+	store, mr := newStore(t)
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithReplayStore(store, time.Minute)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	_, err = r.Verify(id)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, mr.TTL("rigid:used:"+id[:26]))
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, rigid.ErrReplayed)
+}
+
+func TestStoreUnavailable(t *testing.T) {
+	store, mr := newStore(t)
+	mr.Close()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	_, err = r.WithRevocationStore(store).Verify(id)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, rigid.ErrRevoked)
+}
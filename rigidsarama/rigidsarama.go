@@ -0,0 +1,81 @@
+// Package rigidsarama adapts rigidmsg event IDs to sarama Kafka messages.
+//
+//	s := rigidmsg.New(r)
+//	msg := &sarama.ProducerMessage{Topic: "orders", Value: sarama.StringEncoder(body)}
+//	id, err := rigidsarama.Stamp(s, msg, "order:42")
+//	...
+//	result, err := rigidsarama.Verify(s, consumed)
+package rigidsarama
+
+import (
+	"github.com/IBM/sarama"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+// Stamp writes a new signed event ID to the headers of msg.
+func Stamp(s *rigidmsg.Stamper, msg *sarama.ProducerMessage, metadata ...string) (string, error) {
+	return s.Stamp(ProducerCarrier(msg), metadata...)
+}
+
+// Verify verifies the event ID carried in the headers of msg.
+func Verify(s *rigidmsg.Stamper, msg *sarama.ConsumerMessage) (rigid.VerifyResult, error) {
+	return s.Verify(ConsumerCarrier(msg))
+}
+
+// ProducerCarrier returns a rigidmsg.Carrier over the headers of a producer message.
+func ProducerCarrier(msg *sarama.ProducerMessage) rigidmsg.Carrier {
+	return producerCarrier{msg}
+}
+
+// ConsumerCarrier returns a rigidmsg.Carrier over the headers of a consumer message.
+func ConsumerCarrier(msg *sarama.ConsumerMessage) rigidmsg.Carrier {
+	return consumerCarrier{msg}
+}
+
+type producerCarrier struct {
+	msg *sarama.ProducerMessage
+}
+
+func (c producerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c producerCarrier) Set(key, value string) {
+	headers := c.msg.Headers[:0]
+	for _, h := range c.msg.Headers {
+		if string(h.Key) != key {
+			headers = append(headers, h)
+		}
+	}
+	c.msg.Headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+type consumerCarrier struct {
+	msg *sarama.ConsumerMessage
+}
+
+func (c consumerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c consumerCarrier) Set(key, value string) {
+	headers := c.msg.Headers[:0]
+	for _, h := range c.msg.Headers {
+		if h != nil && string(h.Key) != key {
+			headers = append(headers, h)
+		}
+	}
+	c.msg.Headers = append(headers, &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
@@ -0,0 +1,78 @@
+package rigidsarama
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidmsg"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func newStamper(t *testing.T) *rigidmsg.Stamper {
+	t.Helper()
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	return rigidmsg.New(r)
+}
+
+// deliver converts a producer message to the consumer message a broker would hand out.
+func deliver(msg *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	consumed := &sarama.ConsumerMessage{Topic: msg.Topic}
+	for i := range msg.Headers {
+		consumed.Headers = append(consumed.Headers, &msg.Headers[i])
+	}
+	return consumed
+}
+
+func TestStampAndVerify(t *testing.T) {
+	s := newStamper(t)
+
+	msg := &sarama.ProducerMessage{
+		Topic:   "orders",
+		Headers: []sarama.RecordHeader{{Key: []byte("trace"), Value: []byte("abc")}},
+	}
+	id, err := Stamp(s, msg, "order:42")
+	require.NoError(t, err)
+	require.Len(t, msg.Headers, 2)
+
+	result, err := Verify(s, deliver(msg))
+	require.NoError(t, err)
+	assert.Equal(t, "order:42", result.Metadata)
+	assert.Equal(t, id, ProducerCarrier(msg).Get(rigidmsg.DefaultHeader))
+}
+
+func TestStampReplacesExistingID(t *testing.T) {
+	s := newStamper(t)
+
+	msg := &sarama.ProducerMessage{Topic: "orders"}
+	_, err := Stamp(s, msg)
+	require.NoError(t, err)
+	id, err := Stamp(s, msg)
+	require.NoError(t, err)
+
+	require.Len(t, msg.Headers, 1)
+	assert.Equal(t, id, string(msg.Headers[0].Value))
+}
+
+func TestVerifyRejectsTamperedHeader(t *testing.T) {
+	s := newStamper(t)
+
+	_, err := Verify(s, &sarama.ConsumerMessage{})
+	assert.ErrorIs(t, err, rigidmsg.ErrMissingID)
+
+	msg := &sarama.ProducerMessage{Topic: "orders"}
+	id, err := Stamp(s, msg, "amount:10")
+	require.NoError(t, err)
+
+	consumed := deliver(msg)
+	ConsumerCarrier(consumed).Set(rigidmsg.DefaultHeader, id[:len(id)-2]+"99")
+	require.Len(t, consumed.Headers, 1)
+
+	_, err = Verify(s, consumed)
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
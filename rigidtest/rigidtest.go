@@ -0,0 +1,138 @@
+// Package rigidtest provides the cross-language conformance test vectors of rigid IDs and a test
+// helper running them against an implementation, so compatibility between the Go library and
+// implementations in other languages is proven by tests rather than asserted.
+//
+//	func TestConformance(t *testing.T) {
+//		rigidtest.RunConformance(t, pythonRigid{}) // a wrapper calling the Python library
+//	}
+//
+// The vectors are kept in vectors.json in this package, as written by the rigid vectors command.
+package rigidtest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/oklog/ulid/v2"
+)
+
+//go:embed vectors.json
+var vectorsJSON []byte
+
+// Vectors returns the conformance test vectors.
+func Vectors() rigid.TestVectors {
+	var doc rigid.TestVectors
+	if err := json.Unmarshal(vectorsJSON, &doc); err != nil {
+		panic(fmt.Sprintf("rigidtest: invalid vectors.json: %v", err))
+	}
+	return doc
+}
+
+// Implementation is a rigid implementation under test. Both methods use FormatV1.
+type Implementation interface {
+	// Sign returns the ID of ulid and metadata signed with key, truncated to signatureLength
+	// bytes and typed with prefix, which may be empty.
+	Sign(key []byte, signatureLength int, prefix, ulid, metadata string) (string, error)
+	// Verify verifies id with key, signatureLength and prefix, and returns its ULID and metadata.
+	Verify(key []byte, signatureLength int, prefix, id string) (ulid, metadata string, err error)
+}
+
+// Go returns the Implementation of this library.
+func Go() Implementation {
+	return goImpl{}
+}
+
+type goImpl struct{}
+
+func (goImpl) Sign(key []byte, signatureLength int, prefix, ulidStr, metadata string) (string, error) {
+	r, err := newRigid(key, signatureLength, prefix)
+	if err != nil {
+		return "", err
+	}
+	u, err := ulid.ParseStrict(ulidStr)
+	if err != nil {
+		return "", err
+	}
+	return r.Sign(u, metadata)
+}
+
+func (goImpl) Verify(key []byte, signatureLength int, prefix, id string) (string, string, error) {
+	r, err := newRigid(key, signatureLength, prefix)
+	if err != nil {
+		return "", "", err
+	}
+	result, err := r.Verify(id)
+	return result.ULID, result.Metadata, err
+}
+
+// newRigid returns an instance with key, signatureLength and prefix.
+func newRigid(key []byte, signatureLength int, prefix string) (*rigid.Rigid, error) {
+	r, err := rigid.NewRigid(key, signatureLength)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithPrefix(prefix)
+}
+
+// RunConformance checks impl against every conformance test vector, in a subtest per vector:
+// signing the vector's inputs must produce the vector's ID, verifying the ID must return its ULID
+// and metadata, and the ID with a tampered signature must fail verification.
+func RunConformance(t *testing.T, impl Implementation) {
+	t.Helper()
+	doc := Vectors()
+	if doc.Version != rigid.TestVectorsVersion {
+		t.Fatalf("unsupported vectors version %d", doc.Version)
+	}
+
+	for i, v := range doc.Vectors {
+		t.Run(fmt.Sprintf("vector%03d", i), func(t *testing.T) {
+			for _, err := range check(impl, v) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// check checks impl against v and returns the failures.
+func check(impl Implementation, v rigid.TestVector) []error {
+	var errs []error
+	key := []byte(v.Key)
+	id, err := impl.Sign(key, v.SignatureLength, v.Prefix, v.ULID, v.Metadata)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("sign: %w", err))
+	} else if id != v.ID {
+		errs = append(errs, fmt.Errorf("sign: got %s, want %s", id, v.ID))
+	}
+
+	ulidStr, metadata, err := impl.Verify(key, v.SignatureLength, v.Prefix, v.ID)
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("verify %s: %w", v.ID, err))
+	case ulidStr != v.ULID || metadata != v.Metadata:
+		errs = append(errs, fmt.Errorf("verify %s: got ULID %s and metadata %q, want %s and %q", v.ID, ulidStr, metadata, v.ULID, v.Metadata))
+	}
+
+	if _, _, err := impl.Verify(key, v.SignatureLength, v.Prefix, tamper(v)); err == nil {
+		errs = append(errs, fmt.Errorf("verify %s: tampered signature accepted", tamper(v)))
+	}
+	return errs
+}
+
+// tamper returns the ID of v with the first character of its signature changed.
+func tamper(v rigid.TestVector) string {
+	at := len(v.ID) - len(v.Metadata)
+	if v.Metadata != "" {
+		at--
+	}
+	at -= (v.SignatureLength*8 + 4) / 5
+	b := []byte(v.ID)
+	if b[at] == 'A' {
+		b[at] = 'B'
+	} else {
+		b[at] = 'A'
+	}
+	return string(b)
+}
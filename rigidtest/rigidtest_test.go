@@ -0,0 +1,58 @@
+package rigidtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	RunConformance(t, Go())
+}
+
+func TestVectors(t *testing.T) {
+	doc := Vectors()
+	assert.Equal(t, rigid.TestVectorsVersion, doc.Version)
+	require.NotEmpty(t, doc.Vectors)
+
+	prefixed := 0
+	for _, v := range doc.Vectors {
+		parts, err := rigid.Parse(v.ID)
+		require.NoError(t, err, v.ID)
+		assert.Equal(t, v.Prefix, parts.Prefix)
+		assert.Equal(t, v.ULID, parts.ULID)
+		assert.Equal(t, v.SignatureLength, parts.SignatureLength)
+		assert.Equal(t, v.Metadata, parts.Metadata)
+		assert.NotEqual(t, v.ID, tamper(v))
+		if v.Prefix != "" {
+			prefixed++
+		}
+	}
+	assert.Positive(t, prefixed)
+	assert.NoError(t, rigid.CheckCompatibility(bytes.NewReader(vectorsJSON)))
+}
+
+// brokenImpl signs with the wrong key and accepts any ID.
+type brokenImpl struct{ Implementation }
+
+func (b brokenImpl) Sign(key []byte, signatureLength int, prefix, ulid, metadata string) (string, error) {
+	return b.Implementation.Sign(append(key, 'x'), signatureLength, prefix, ulid, metadata)
+}
+
+func (b brokenImpl) Verify(key []byte, signatureLength int, prefix, id string) (string, string, error) {
+	return "", "", nil
+}
+
+func TestCheck(t *testing.T) {
+	v := Vectors().Vectors[1]
+	assert.Empty(t, check(Go(), v))
+
+	errs := check(brokenImpl{Go()}, v)
+	require.Len(t, errs, 3)
+	assert.ErrorContains(t, errs[0], "sign: got ")
+	assert.ErrorContains(t, errs[1], "got ULID  and metadata")
+	assert.ErrorContains(t, errs[2], "tampered signature accepted")
+}
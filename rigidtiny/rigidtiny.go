@@ -0,0 +1,253 @@
+// Package rigidtiny verifies rigid IDs on constrained targets, such as firmware built with TinyGo
+// that verifies device-provisioning IDs offline. It depends on a few standard library packages
+// only, none of which need reflection, and implements verification without the rigid package
+// and its dependencies:
+//
+//	v, err := rigidtiny.NewVerifier(key, rigidtiny.WithPrefix("dev"))
+//	result, err := v.Verify(id)
+//
+// Verification matches rigid.Rigid.Verify for the signature length, prefix and format version
+// configured, but without TTLs, timestamp bounds, metadata normalization, issuers trusted
+// through a policy, or revocation and replay stores. IDs are generated with the rigid package.
+package rigidtiny
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+)
+
+// Errors returned by NewVerifier and Verify. They have the same messages as their counterparts
+// in the rigid package.
+var (
+	ErrInvalidFormat        = errors.New("invalid rigid format")
+	ErrInvalidULID          = errors.New("invalid ULID")
+	ErrIntegrityFailure     = errors.New("integrity verification failed")
+	ErrEmptySecretKey       = errors.New("secret key cannot be empty")
+	ErrInvalidSigLength     = errors.New("signature length must be positive")
+	ErrInvalidPrefix        = errors.New("prefix must contain only ASCII letters and digits")
+	ErrInvalidFormatVersion = errors.New("unknown format version")
+)
+
+// Signature lengths and format versions, as in the rigid package.
+const (
+	DefaultSignatureLength = 8
+	MinSignatureLength     = 4
+	MaxSignatureLength     = 32
+
+	FormatV1 = 1
+	FormatV2 = 2
+	FormatV3 = 3
+)
+
+// formatDomain starts the signed input of framed format versions.
+const formatDomain = "rigid\x00"
+
+// algHMACSHA256 identifies HMAC-SHA256 in framed signed input.
+const algHMACSHA256 = 1
+
+// ulidSize is the length of an encoded ULID.
+const ulidSize = 26
+
+// crockford is the alphabet of encoded ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var signatureEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type config struct {
+	signatureLength int
+	prefix          string
+	version         int
+}
+
+// Option configures a Verifier.
+type Option func(*config)
+
+// WithSignatureLength sets the signature length in bytes, DefaultSignatureLength by default.
+func WithSignatureLength(n int) Option {
+	return func(c *config) {
+		c.signatureLength = n
+	}
+}
+
+// WithPrefix sets the prefix segment of the IDs verified, such as "dev", or "ISSUER.dev" for IDs
+// of an issuer. IDs without a prefix are verified by default.
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithFormatVersion sets the format version of the IDs verified, FormatV1 by default.
+func WithFormatVersion(v int) Option {
+	return func(c *config) {
+		c.version = v
+	}
+}
+
+// Verifier verifies rigid IDs signed with one key. It is safe for concurrent use.
+type Verifier struct {
+	key  []byte
+	conf config
+}
+
+// Result holds the segments of a verified ID.
+type Result struct {
+	// ULID is the ULID in its canonical upper-case encoding.
+	ULID string
+	// Timestamp is the creation time embedded in the ULID, in Unix milliseconds.
+	Timestamp uint64
+	// Metadata is the metadata, or empty if the ID has none.
+	Metadata string
+}
+
+// NewVerifier returns a Verifier of IDs signed with key. The key is copied.
+// Returns ErrEmptySecretKey, ErrInvalidSigLength, ErrInvalidPrefix or ErrInvalidFormatVersion
+// if the key or an option is invalid.
+func NewVerifier(key []byte, opts ...Option) (*Verifier, error) {
+	conf := config{signatureLength: DefaultSignatureLength, version: FormatV1}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if len(key) == 0 {
+		return nil, ErrEmptySecretKey
+	}
+	if conf.signatureLength < MinSignatureLength || conf.signatureLength > MaxSignatureLength {
+		return nil, ErrInvalidSigLength
+	}
+	if !validPrefixSegment(conf.prefix) {
+		return nil, ErrInvalidPrefix
+	}
+	if conf.version < FormatV1 || conf.version > FormatV3 {
+		return nil, ErrInvalidFormatVersion
+	}
+
+	v := &Verifier{key: append([]byte(nil), key...), conf: conf}
+	if conf.version == FormatV3 {
+		v.key = hkdf(v.key, formatDomain+string([]byte{FormatV3, algHMACSHA256, byte(conf.signatureLength)}))
+	}
+	return v, nil
+}
+
+// Verify checks the structure and signature of id and returns its segments.
+// Returns ErrInvalidFormat if id is malformed or has another prefix, ErrInvalidULID if its ULID
+// is invalid, and ErrIntegrityFailure if its signature does not match.
+func (v *Verifier) Verify(id string) (Result, error) {
+	head, rest, ok := strings.Cut(id, "-")
+	if !ok {
+		return Result{}, ErrInvalidFormat
+	}
+	prefix, ulidStr := "", head
+	if i := strings.LastIndexByte(head, '_'); i >= 0 {
+		if i == 0 {
+			return Result{}, ErrInvalidFormat
+		}
+		prefix, ulidStr = head[:i], head[i+1:]
+	}
+	signature, metadata, _ := strings.Cut(rest, "-")
+	if signature == "" || prefix != v.conf.prefix {
+		return Result{}, ErrInvalidFormat
+	}
+
+	canonical, timestamp, ok := parseULID(ulidStr)
+	if !ok {
+		return Result{}, ErrInvalidULID
+	}
+	if !hmac.Equal([]byte(signature), v.signature(prefix, ulidStr, metadata)) {
+		return Result{}, ErrIntegrityFailure
+	}
+	return Result{ULID: canonical, Timestamp: timestamp, Metadata: metadata}, nil
+}
+
+// signature computes the encoded signature of an ID with the given segments.
+func (v *Verifier) signature(prefix, ulidStr, metadata string) []byte {
+	var input []byte
+	if v.conf.version >= FormatV2 {
+		input = append(input, formatDomain...)
+		input = append(input, byte(v.conf.version), algHMACSHA256, byte(v.conf.signatureLength))
+		input = appendField(input, prefix)
+		input = appendField(input, ulidStr)
+		input = appendField(input, metadata)
+	} else {
+		if prefix != "" {
+			input = append(input, prefix...)
+			input = append(input, '_')
+		}
+		input = append(input, ulidStr...)
+		input = append(input, metadata...)
+	}
+
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write(input)
+	sum := mac.Sum(nil)
+	enc := make([]byte, signatureEncoding.EncodedLen(v.conf.signatureLength))
+	signatureEncoding.Encode(enc, sum[:v.conf.signatureLength])
+	return enc
+}
+
+// parseULID decodes an encoded ULID, in either case, and returns its canonical encoding and
+// timestamp. It accepts the same ULIDs as ulid.ParseStrict.
+func parseULID(s string) (string, uint64, bool) {
+	if len(s) != ulidSize || s[0] > '7' {
+		return "", 0, false
+	}
+	var canonical [ulidSize]byte
+	var timestamp uint64
+	for i := 0; i < ulidSize; i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		d := strings.IndexByte(crockford, c)
+		if d < 0 {
+			return "", 0, false
+		}
+		canonical[i] = c
+		// The first 10 characters hold the 48-bit timestamp, as the first is at most 7
+		if i < 10 {
+			timestamp = timestamp<<5 | uint64(d)
+		}
+	}
+	return string(canonical[:]), timestamp, true
+}
+
+// appendField appends f to dst preceded by its length as a 4-byte big-endian integer.
+func appendField(dst []byte, f string) []byte {
+	n := uint32(len(f))
+	dst = append(dst, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	return append(dst, f...)
+}
+
+// hkdf derives a 32-byte key from key with HKDF-SHA256 (RFC 5869), using an empty salt and the
+// given info.
+func hkdf(key []byte, info string) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+// validPrefixSegment reports whether p is a type prefix made of ASCII letters and digits,
+// optionally qualified by an issuer as ISSUER.PREFIX.
+func validPrefixSegment(p string) bool {
+	if issuer, prefix, ok := strings.Cut(p, "."); ok {
+		return issuer != "" && validPrefix(issuer) && validPrefix(prefix)
+	}
+	return validPrefix(p)
+}
+
+// validPrefix reports whether p is made of ASCII letters and digits.
+func validPrefix(p string) bool {
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package rigidtiny
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/rigidtest"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+func TestVerifyVectors(t *testing.T) {
+	for _, vec := range rigidtest.Vectors().Vectors {
+		v, err := NewVerifier([]byte(vec.Key), WithSignatureLength(vec.SignatureLength), WithPrefix(vec.Prefix))
+		require.NoError(t, err)
+
+		result, err := v.Verify(vec.ID)
+		require.NoError(t, err, vec.ID)
+		assert.Equal(t, vec.ULID, result.ULID)
+		assert.Equal(t, vec.Metadata, result.Metadata)
+	}
+}
+
+func TestVerifyFormatVersions(t *testing.T) {
+	for _, version := range []rigid.FormatVersion{rigid.FormatV1, rigid.FormatV2, rigid.FormatV3} {
+		r, err := rigid.NewRigid(testSecretKey, 12)
+		require.NoError(t, err)
+		r, err = r.WithFormatVersion(version)
+		require.NoError(t, err)
+		r, err = r.WithPrefix("dev")
+		require.NoError(t, err)
+		id, err := r.Generate("device:42")
+		require.NoError(t, err)
+		want, err := r.Verify(id)
+		require.NoError(t, err)
+
+		v, err := NewVerifier(testSecretKey, WithSignatureLength(12), WithPrefix("dev"), WithFormatVersion(int(version)))
+		require.NoError(t, err)
+		result, err := v.Verify(id)
+		require.NoError(t, err, "format version %d", version)
+		assert.Equal(t, want.ULID, result.ULID)
+		assert.Equal(t, uint64(want.Timestamp.UnixMilli()), result.Timestamp)
+		assert.Equal(t, "device:42", result.Metadata)
+
+		other, err := NewVerifier(testSecretKey, WithSignatureLength(12), WithPrefix("dev"), WithFormatVersion(int(version%3+1)))
+		require.NoError(t, err)
+		_, err = other.Verify(id)
+		assert.ErrorIs(t, err, ErrIntegrityFailure)
+	}
+}
+
+func TestVerifyIssuer(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithIssuer("factory")
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	v, err := NewVerifier(testSecretKey, WithPrefix("factory."))
+	require.NoError(t, err)
+	_, err = v.Verify(id)
+	assert.NoError(t, err)
+}
+
+func TestVerifyFailures(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("device:42")
+	require.NoError(t, err)
+	v, err := NewVerifier(testSecretKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		id   string
+		want error
+	}{
+		{id[:27] + "AAAAAAAAAAAAA" + id[40:], ErrIntegrityFailure},
+		{id + "x", ErrIntegrityFailure},
+		{id[:40], ErrIntegrityFailure},
+		{strings.ToLower(id[:26]) + id[26:], ErrIntegrityFailure},
+		{"dev_" + id, ErrInvalidFormat},
+		{"_" + id, ErrInvalidFormat},
+		{id[:26], ErrInvalidFormat},
+		{id[:26] + "-", ErrInvalidFormat},
+		{"8" + id[1:], ErrInvalidULID},
+		{id[:5] + "U" + id[6:], ErrInvalidULID},
+		{id[1:], ErrInvalidULID},
+	}
+	for _, tt := range tests {
+		_, err := v.Verify(tt.id)
+		assert.ErrorIs(t, err, tt.want, tt.id)
+	}
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	_, err := NewVerifier(nil)
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewVerifier(testSecretKey, WithSignatureLength(MaxSignatureLength+1))
+	assert.ErrorIs(t, err, ErrInvalidSigLength)
+	_, err = NewVerifier(testSecretKey, WithPrefix("a-b"))
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+	_, err = NewVerifier(testSecretKey, WithPrefix(".dev"))
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+	_, err = NewVerifier(testSecretKey, WithFormatVersion(4))
+	assert.ErrorIs(t, err, ErrInvalidFormatVersion)
+}
+
+func TestVerifierCopiesKey(t *testing.T) {
+	key := append([]byte(nil), testSecretKey...)
+	v, err := NewVerifier(key)
+	require.NoError(t, err)
+	key[0] ^= 1
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate()
+	require.NoError(t, err)
+	_, err = v.Verify(id)
+	assert.NoError(t, err)
+}
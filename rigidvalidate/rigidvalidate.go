@@ -0,0 +1,53 @@
+// Package rigidvalidate registers rigid ID validation with go-playground/validator and kin-openapi,
+// so request DTOs and OpenAPI documents can declare rigid ID fields declaratively.
+//
+//	validate := validator.New()
+//	if err := rigidvalidate.RegisterValidation(validate); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	type CreateRefundRequest struct {
+//		OrderID string `json:"order_id" validate:"required,rigid"`
+//	}
+//
+// In OpenAPI documents, call RegisterOpenAPIFormat once and declare fields with the rigid format:
+//
+//	order_id:
+//	  type: string
+//	  format: rigid
+//
+// Both validators follow rigid.ID: a structural check by default, or full signature
+// verification once rigid.SetDefaultVerifier has been called.
+package rigidvalidate
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// RegisterValidation registers the rigid.FormatName tag with v. The tag applies to string
+// fields, including rigid.ID. Empty strings fail; combine with omitempty for optional fields.
+func RegisterValidation(v *validator.Validate) error {
+	return v.RegisterValidation(rigid.FormatName, validateField)
+}
+
+// RegisterOpenAPIFormat defines the rigid.FormatName string format for kin-openapi schema validation.
+// kin-openapi keeps string formats in a package-level registry, so this affects all schemas.
+func RegisterOpenAPIFormat() {
+	openapi3.DefineStringFormatCallback(rigid.FormatName, func(s string) error {
+		return rigid.ID(s).Validate()
+	})
+}
+
+func validateField(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	return s != "" && rigid.ID(s).Validate() == nil
+}
@@ -0,0 +1,51 @@
+package rigidvalidate
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+type refundRequest struct {
+	OrderID  string   `validate:"required,rigid"`
+	ParentID rigid.ID `validate:"omitempty,rigid"`
+}
+
+func TestRegisterValidation(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	v := validator.New()
+	require.NoError(t, RegisterValidation(v))
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	assert.NoError(t, v.Struct(refundRequest{OrderID: id}))
+	assert.NoError(t, v.Struct(refundRequest{OrderID: id, ParentID: rigid.ID(id)}))
+	assert.Error(t, v.Struct(refundRequest{OrderID: "bad-id"}))
+	assert.Error(t, v.Struct(refundRequest{OrderID: id, ParentID: "bad-id"}))
+	assert.Error(t, v.Var(42, "rigid"))
+}
+
+func TestRegisterOpenAPIFormat(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	RegisterOpenAPIFormat()
+
+	schema := openapi3.NewStringSchema().WithFormat(rigid.FormatName)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.VisitJSON(id))
+	assert.Error(t, schema.VisitJSON("bad-id"))
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/pb"
+)
+
+// GRPCServer implements pb.RigidServiceServer, the gRPC counterpart of Server.
+// Authenticate callers with interceptors on the grpc.Server it is registered with:
+//
+//	srv := grpc.NewServer(grpc.UnaryInterceptor(auth))
+//	pb.RegisterRigidServiceServer(srv, server.NewGRPC(r))
+type GRPCServer struct {
+	pb.UnimplementedRigidServiceServer
+
+	r *rigid.Rigid
+}
+
+// NewGRPC returns a GRPCServer generating and verifying IDs with r.
+func NewGRPC(r *rigid.Rigid) *GRPCServer {
+	return &GRPCServer{r: r}
+}
+
+// Generate implements pb.RigidServiceServer.
+func (s *GRPCServer) Generate(_ context.Context, req *pb.GenerateRequest) (*pb.GenerateResponse, error) {
+	count := int(req.GetCount())
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 || count > MaxCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", MaxCount)
+	}
+
+	resp := &pb.GenerateResponse{Ids: make([]string, count)}
+	for i := range resp.Ids {
+		id, err := s.r.Generate(req.GetMetadata())
+		if err != nil {
+			if invalidInput(err) {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil, status.Errorf(codes.Internal, "generate ID: %v", err)
+		}
+		resp.Ids[i] = id
+	}
+	return resp, nil
+}
+
+// Verify implements pb.RigidServiceServer.
+func (s *GRPCServer) Verify(_ context.Context, req *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	v := Verify(s.r, req.GetId())
+	resp := &pb.VerifyResponse{
+		Valid:    v.Result.Valid,
+		Ulid:     v.Result.ULID,
+		Metadata: v.Result.Metadata,
+		Error:    v.Error,
+	}
+	if v.Result.Valid {
+		resp.Timestamp = timestamppb.New(v.Result.Timestamp)
+		if !v.Result.ExpiresAt.IsZero() {
+			resp.ExpiresAt = timestamppb.New(v.Result.ExpiresAt)
+		}
+	}
+	return resp, nil
+}
+
+// Inspect implements pb.RigidServiceServer.
+func (s *GRPCServer) Inspect(_ context.Context, req *pb.InspectRequest) (*pb.InspectResponse, error) {
+	v := Inspect(s.r, req.GetId())
+	resp := &pb.InspectResponse{
+		Prefix:          v.Prefix,
+		Ulid:            v.ULID,
+		Signature:       v.Signature,
+		SignatureLength: int32(v.SignatureLength),
+		Metadata:        v.Metadata,
+		Verified:        v.Verified != nil && *v.Verified,
+		Error:           v.Error,
+	}
+	if v.Timestamp != nil {
+		resp.Timestamp = timestamppb.New(*v.Timestamp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/pb"
+)
+
+func grpcClient(t *testing.T, r *rigid.Rigid) pb.RigidServiceClient {
+	t.Helper()
+
+	srv := grpc.NewServer()
+	pb.RegisterRigidServiceServer(srv, NewGRPC(r))
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewRigidServiceClient(conn)
+}
+
+func TestGRPCGenerate(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	client := grpcClient(t, r)
+	ctx := context.Background()
+
+	resp, err := client.Generate(ctx, &pb.GenerateRequest{Metadata: "order:42", Count: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.GetIds(), 2)
+	for _, id := range resp.GetIds() {
+		result, err := r.Verify(id)
+		require.NoError(t, err)
+		assert.Equal(t, "order:42", result.Metadata)
+	}
+
+	resp, err = client.Generate(ctx, &pb.GenerateRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetIds(), 1)
+
+	_, err = client.Generate(ctx, &pb.GenerateRequest{Count: MaxCount + 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Generate(ctx, &pb.GenerateRequest{Metadata: strings.Repeat("m", rigid.DefaultMaxLength)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCVerify(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour)
+	client := grpcClient(t, r)
+	ctx := context.Background()
+
+	id, err := r.Generate("user")
+	require.NoError(t, err)
+
+	resp, err := client.Verify(ctx, &pb.VerifyRequest{Id: id})
+	require.NoError(t, err)
+	assert.True(t, resp.GetValid())
+	assert.Equal(t, "user", resp.GetMetadata())
+	assert.Equal(t, id[:26], resp.GetUlid())
+	assert.NotNil(t, resp.GetTimestamp())
+	assert.Equal(t, resp.GetTimestamp().AsTime().Add(time.Hour), resp.GetExpiresAt().AsTime())
+	assert.Empty(t, resp.GetError())
+
+	resp, err = client.Verify(ctx, &pb.VerifyRequest{Id: id[:len(id)-1] + "9"})
+	require.NoError(t, err)
+	assert.False(t, resp.GetValid())
+	assert.Nil(t, resp.GetTimestamp())
+	assert.NotEmpty(t, resp.GetError())
+}
+
+func TestGRPCInspect(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	client := grpcClient(t, r)
+	ctx := context.Background()
+
+	id, err := r.Generate("42")
+	require.NoError(t, err)
+
+	resp, err := client.Inspect(ctx, &pb.InspectRequest{Id: id})
+	require.NoError(t, err)
+	assert.True(t, resp.GetVerified())
+	assert.Equal(t, "42", resp.GetMetadata())
+	assert.Equal(t, int32(8), resp.GetSignatureLength())
+	assert.NotNil(t, resp.GetTimestamp())
+
+	resp, err = client.Inspect(ctx, &pb.InspectRequest{Id: "not-an-id"})
+	require.NoError(t, err)
+	assert.False(t, resp.GetVerified())
+	assert.NotEmpty(t, resp.GetError())
+}
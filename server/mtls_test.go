@@ -0,0 +1,136 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// testCA issues certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a certificate for name usable for usage.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestClientCertificates(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	ca, other := newTestCA(t), newTestCA(t)
+
+	ts := httptest.NewUnstartedServer(New(r, WithClientCertificates(ca.pool, "billing")))
+	ts.TLS = TLSConfig(ca.issue(t, "server", x509.ExtKeyUsageServerAuth), ca.pool)
+	ts.StartTLS()
+	defer ts.Close()
+
+	status := func(path string, certs ...tls.Certificate) int {
+		t.Helper()
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      ca.pool,
+			Certificates: certs,
+		}}}
+		resp, err := client.Post(ts.URL+path, "application/json", strings.NewReader(`{}`))
+		if err != nil {
+			// The handshake rejected the certificate
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, status("/generate", ca.issue(t, "billing", x509.ExtKeyUsageClientAuth)))
+	assert.Equal(t, http.StatusUnauthorized, status("/generate"))
+	assert.Equal(t, http.StatusUnauthorized, status("/generate", ca.issue(t, "reporting", x509.ExtKeyUsageClientAuth)))
+	assert.Equal(t, 0, status("/generate", other.issue(t, "billing", x509.ExtKeyUsageClientAuth)))
+	assert.Equal(t, 0, status("/generate", ca.issue(t, "billing", x509.ExtKeyUsageServerAuth)))
+
+	// Health checks need no certificate
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool}}}
+	resp, err := client.Get(ts.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestVerifyClient(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, "billing", x509.ExtKeyUsageClientAuth)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+
+	assert.True(t, verifyClient(state, ca.pool, nil))
+	assert.True(t, verifyClient(state, ca.pool, []string{"reporting", "billing"}))
+	assert.False(t, verifyClient(state, ca.pool, []string{"reporting"}))
+	assert.False(t, verifyClient(state, newTestCA(t).pool, nil), "the chain is verified, not trusted from the handshake")
+	assert.False(t, verifyClient(&tls.ConnectionState{}, ca.pool, nil))
+	assert.False(t, verifyClient(nil, ca.pool, nil))
+}
+
+func TestClientCertificatesWithAPIKeys(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	ca := newTestCA(t)
+	srv := New(r, WithClientCertificates(ca.pool), WithAPIKeys("key-one"))
+
+	// Both checks apply; plain HTTP requests carry no certificate
+	assert.Equal(t, http.StatusUnauthorized, do(t, srv, "POST", "/generate", `{}`, "key-one", nil))
+}
@@ -0,0 +1,406 @@
+// Package server exposes rigid ID generation, verification and inspection over HTTP,
+// so services written in other languages can use rigid without reimplementing it.
+//
+// All endpoints take and return JSON:
+//
+//	POST /generate  {"metadata": "order:42", "count": 2}  -> {"ids": ["01ARZ...", "01ARZ..."]}
+//	POST /verify    {"id": "01ARZ..."}                      -> {"id": "...", "result": {...}, "error": "..."}
+//	POST /inspect   {"id": "01ARZ..."}                      -> {"id": "...", "ulid": "...", "verified": true, ...}
+//	POST /introspect token=01ARZ... (form-encoded)          -> {"active": true, "jti": "...", "iat": ..., ...}
+//	GET  /healthz                                           -> {"status": "ok"}
+//	GET  /debug/rigid                                       -> {"config": {...}, "stats": {...}}
+//
+// Verification failures are reported in the response body with status 200; non-2xx statuses
+// indicate malformed or unauthorized requests. /introspect follows OAuth 2.0 Token Introspection
+// (RFC 7662), so API gateways that introspect tokens can validate rigid IDs directly. Requests are authenticated with WithAPIKeys or
+// any middleware passed to WithMiddleware, such as apikey.Middleware; /healthz is always open.
+// /debug/rigid is only served with WithDebug.
+//
+//	srv := server.New(r, server.WithAPIKeys(os.Getenv("RIGID_API_KEY")))
+//	http.ListenAndServe(":8080", srv)
+//
+// GRPCServer offers the same operations as the pb.RigidService gRPC service.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// MaxCount is the largest number of IDs a single /generate request may ask for.
+const MaxCount = 1000
+
+// maxBodySize bounds request bodies.
+const maxBodySize = 1 << 20
+
+// GenerateRequest is the body of a /generate request.
+type GenerateRequest struct {
+	// Metadata is bound to the generated IDs.
+	Metadata string `json:"metadata,omitempty"`
+	// Count is the number of IDs to generate, 1 if zero.
+	Count int `json:"count,omitempty"`
+}
+
+// GenerateResponse is the body of a /generate response.
+type GenerateResponse struct {
+	IDs []string `json:"ids"`
+}
+
+// IDRequest is the body of /verify and /inspect requests.
+type IDRequest struct {
+	ID string `json:"id"`
+}
+
+// VerifyResponse is the body of a /verify response.
+type VerifyResponse struct {
+	ID     string             `json:"id"`
+	Result rigid.VerifyResult `json:"result"`
+	// Error describes why verification failed, or is empty if the ID is valid.
+	Error string `json:"error,omitempty"`
+}
+
+// Verify verifies id with r and describes the outcome.
+func Verify(r *rigid.Rigid, id string) VerifyResponse {
+	result, err := r.Verify(id)
+	return newVerifyResponse(id, result, err)
+}
+
+func newVerifyResponse(id string, result rigid.VerifyResult, err error) VerifyResponse {
+	resp := VerifyResponse{ID: id, Result: result}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// InspectResponse is the body of an /inspect response.
+type InspectResponse struct {
+	ID              string     `json:"id"`
+	Prefix          string     `json:"prefix,omitempty"`
+	ULID            string     `json:"ulid,omitempty"`
+	Timestamp       *time.Time `json:"timestamp,omitempty"`
+	Signature       string     `json:"signature,omitempty"`
+	SignatureLength int        `json:"signature_length,omitempty"`
+	Metadata        string     `json:"metadata,omitempty"`
+	// Verified reports whether the ID is authentic. It is nil if no key was available.
+	Verified *bool `json:"verified,omitempty"`
+	// Error describes why the ID is malformed or failed verification.
+	Error string `json:"error,omitempty"`
+}
+
+// Inspect describes the segments of id and, if r is not nil, verifies it using the prefix found
+// in the ID, so IDs of any type can be inspected with one key. The signature length of r applies:
+// IDs with a shorter signature are not verified, as a short signature of FormatV1 is a prefix of
+// the full one and accepting it would let callers forge signatures a byte at a time.
+func Inspect(r *rigid.Rigid, id string) InspectResponse {
+	resp := InspectResponse{ID: id}
+
+	parts, err := rigid.Parse(id)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	ts := parts.Timestamp.UTC()
+	resp.Prefix = parts.Prefix
+	resp.ULID = parts.ULID
+	resp.Timestamp = &ts
+	resp.Signature = parts.Signature
+	resp.SignatureLength = parts.SignatureLength
+	resp.Metadata = parts.Metadata
+
+	if r != nil {
+		err := verifyParts(r, parts, id)
+		verified := err == nil
+		resp.Verified = &verified
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	return resp
+}
+
+// IntrospectResponse is the body of an /introspect response, as defined by RFC 7662. Inactive
+// IDs are reported with Active alone, so callers learn nothing about them.
+type IntrospectResponse struct {
+	Active bool `json:"active"`
+	// JTI is the ULID of the ID.
+	JTI string `json:"jti,omitempty"`
+	// IssuedAt is the creation time of the ID in Unix seconds.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// ExpiresAt is the expiry time of the ID in Unix seconds, or zero if it does not expire.
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// Issuer is the issuer of the ID, see rigid.Rigid.WithIssuer.
+	Issuer string `json:"iss,omitempty"`
+	// TokenType is "rigid" for active IDs.
+	TokenType string `json:"token_type,omitempty"`
+	// Metadata is the metadata of the ID.
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// Introspect verifies token with r and describes it as RFC 7662 token introspection does.
+func Introspect(r *rigid.Rigid, token string) IntrospectResponse {
+	result, err := r.Verify(token)
+	return newIntrospectResponse(result, err)
+}
+
+func newIntrospectResponse(result rigid.VerifyResult, err error) IntrospectResponse {
+	if err != nil {
+		return IntrospectResponse{}
+	}
+
+	resp := IntrospectResponse{
+		Active:    true,
+		JTI:       result.ULID,
+		Issuer:    result.Issuer,
+		TokenType: "rigid",
+		Metadata:  result.Metadata,
+	}
+	if !result.Timestamp.IsZero() {
+		resp.IssuedAt = result.Timestamp.Unix()
+	}
+	if !result.ExpiresAt.IsZero() {
+		resp.ExpiresAt = result.ExpiresAt.Unix()
+	}
+	return resp
+}
+
+func verifyParts(r *rigid.Rigid, parts rigid.Parts, id string) error {
+	r, err := r.WithPrefix(parts.Prefix)
+	if err != nil {
+		return err
+	}
+	_, err = r.Verify(id)
+	return err
+}
+
+type config struct {
+	middleware []func(http.Handler) http.Handler
+	debug      bool
+	cacheTTL   time.Duration
+	cacheSize  int
+}
+
+// Option configures a Server.
+type Option func(*config)
+
+// WithAPIKeys requires requests to carry one of keys as a bearer token in the Authorization header.
+// Keys are compared by hash in constant time. Empty keys are ignored, such as that of an unset
+// environment variable, and without any other key all requests are refused.
+func WithAPIKeys(keys ...string) Option {
+	hashes := make([][32]byte, 0, len(keys))
+	for _, k := range keys {
+		if strings.TrimSpace(k) != "" {
+			hashes = append(hashes, sha256.Sum256([]byte(k)))
+		}
+	}
+
+	return WithMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+			token = strings.TrimSpace(token)
+			sum := sha256.Sum256([]byte(token))
+
+			ok := 0
+			for _, h := range hashes {
+				ok |= subtle.ConstantTimeCompare(sum[:], h[:])
+			}
+			if !strings.EqualFold(scheme, "Bearer") || token == "" || ok != 1 {
+				writeError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// WithMiddleware wraps the generate, verify, inspect and introspect endpoints in mw, typically to
+// authenticate requests.
+// Middleware added first runs first.
+func WithMiddleware(mw func(http.Handler) http.Handler) Option {
+	return func(c *config) {
+		c.middleware = append(c.middleware, mw)
+	}
+}
+
+// WithDebug serves the configuration and statistics of the instance at /debug/rigid, as returned
+// by Rigid.DebugVars, for quick production inspection. The secret key is never exposed, but the
+// endpoint is protected by the middleware like the generate, verify and inspect endpoints.
+func WithDebug() Option {
+	return func(c *config) {
+		c.debug = true
+	}
+}
+
+// Server serves the rigid HTTP API.
+type Server struct {
+	r     *rigid.Rigid
+	mux   *http.ServeMux
+	cache *verifyCache
+}
+
+// New returns a Server generating and verifying IDs with r.
+// Without authentication options, the API is open to anyone who can reach it.
+func New(r *rigid.Rigid, opts ...Option) *Server {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	s := &Server{r: r, mux: http.NewServeMux()}
+	if c.cacheTTL > 0 && c.cacheSize > 0 && !r.Config().ReplayStore {
+		s.cache = newVerifyCache(c.cacheTTL, c.cacheSize)
+	}
+
+	protect := func(h http.HandlerFunc) http.Handler {
+		var handler http.Handler = h
+		for i := len(c.middleware) - 1; i >= 0; i-- {
+			handler = c.middleware[i](handler)
+		}
+		return handler
+	}
+
+	s.mux.Handle("POST /generate", protect(s.generate))
+	s.mux.Handle("POST /verify", protect(s.verify))
+	s.mux.Handle("POST /inspect", protect(s.inspect))
+	s.mux.Handle("POST /introspect", protect(s.introspect))
+	if c.debug {
+		s.mux.Handle("GET /debug/rigid", protect(s.debug))
+	}
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(w, req)
+}
+
+func (s *Server) generate(w http.ResponseWriter, req *http.Request) {
+	var body GenerateRequest
+	if !readJSON(w, req, &body) {
+		return
+	}
+	if body.Count == 0 {
+		body.Count = 1
+	}
+	if body.Count < 0 || body.Count > MaxCount {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("count must be between 1 and %d", MaxCount))
+		return
+	}
+
+	resp := GenerateResponse{IDs: make([]string, body.Count)}
+	for i := range resp.IDs {
+		id, err := s.r.Generate(body.Metadata)
+		if err != nil {
+			if invalidInput(err) {
+				writeError(w, http.StatusBadRequest, err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "failed to generate ID")
+			}
+			return
+		}
+		resp.IDs[i] = id
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) verify(w http.ResponseWriter, req *http.Request) {
+	var body IDRequest
+	if readJSON(w, req, &body) {
+		result, err := s.verifyID(req.Context(), body.ID)
+		writeJSON(w, http.StatusOK, newVerifyResponse(body.ID, result, err))
+	}
+}
+
+// verifyID verifies id, answering from the verification cache if enabled.
+func (s *Server) verifyID(ctx context.Context, id string) (rigid.VerifyResult, error) {
+	if s.cache == nil {
+		return s.r.VerifyContext(ctx, id)
+	}
+	if result, ok := s.cache.get(id); ok {
+		return result, nil
+	}
+	result, err := s.r.VerifyContext(ctx, id)
+	if err == nil {
+		s.cache.put(id, result)
+	}
+	return result, err
+}
+
+func (s *Server) debug(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.r.DebugVars())
+}
+
+func (s *Server) inspect(w http.ResponseWriter, req *http.Request) {
+	var body IDRequest
+	if readJSON(w, req, &body) {
+		writeJSON(w, http.StatusOK, Inspect(s.r, body.ID))
+	}
+}
+
+// introspect serves RFC 7662 introspection requests, which carry the token as a form parameter.
+// The token_type_hint parameter is ignored, as the server knows one type of token.
+func (s *Server) introspect(w http.ResponseWriter, req *http.Request) {
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodySize)
+	if err := req.ParseForm(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		} else {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+		}
+		return
+	}
+	token := req.PostForm.Get("token")
+	if token == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	result, err := s.verifyID(req.Context(), token)
+	writeJSON(w, http.StatusOK, newIntrospectResponse(result, err))
+}
+
+// readJSON decodes the request body into v, writing a 400 response and returning false on failure.
+func readJSON(w http.ResponseWriter, req *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		} else {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+		}
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// invalidInput reports whether a Generate error is caused by the requested metadata.
+func invalidInput(err error) bool {
+	return errors.Is(err, rigid.ErrTooLong) || errors.Is(err, rigid.ErrInvalidMetadata)
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+	"github.com/bahadrix/rigid-go/apikey"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+// do sends a request to h and decodes the JSON response into v, returning the status code.
+func do(t *testing.T, h http.Handler, method, path, body, key string, v any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if v != nil {
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+	return rec.Code
+}
+
+func TestGenerate(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r)
+
+	var resp GenerateResponse
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/generate", `{}`, "", &resp))
+	require.Len(t, resp.IDs, 1)
+	_, err = r.Verify(resp.IDs[0])
+	assert.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/generate", `{"metadata":"order:42","count":3}`, "", &resp))
+	require.Len(t, resp.IDs, 3)
+	for _, id := range resp.IDs {
+		result, err := r.Verify(id)
+		require.NoError(t, err)
+		assert.Equal(t, "order:42", result.Metadata)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, "POST", "/generate", `{"count":1001}`, "", nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, "POST", "/generate", `{"count":-1}`, "", nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, "POST", "/generate", `{"metadata":"`+strings.Repeat("m", rigid.DefaultMaxLength)+`"}`, "", nil))
+}
+
+func TestVerify(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r)
+
+	id, err := r.Generate("user")
+	require.NoError(t, err)
+
+	var resp VerifyResponse
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/verify", `{"id":"`+id+`"}`, "", &resp))
+	assert.Equal(t, id, resp.ID)
+	assert.True(t, resp.Result.Valid)
+	assert.Equal(t, "user", resp.Result.Metadata)
+	assert.Empty(t, resp.Error)
+
+	attacker, err := rigid.NewRigid([]byte("attacker-key"))
+	require.NoError(t, err)
+	forged, err := attacker.Generate("user")
+	require.NoError(t, err)
+
+	resp = VerifyResponse{}
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/verify", `{"id":"`+forged+`"}`, "", &resp))
+	assert.False(t, resp.Result.Valid)
+	assert.Equal(t, rigid.ErrIntegrityFailure.Error(), resp.Error)
+}
+
+func TestInspect(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r)
+
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	id, err := typed.Generate("42")
+	require.NoError(t, err)
+
+	var resp InspectResponse
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/inspect", `{"id":"`+id+`"}`, "", &resp))
+	assert.Equal(t, "ord", resp.Prefix)
+	assert.Equal(t, "42", resp.Metadata)
+	assert.NotNil(t, resp.Timestamp)
+	require.NotNil(t, resp.Verified)
+	assert.True(t, *resp.Verified)
+
+	// A shorter signature is not verified, even where it is a prefix of the full one
+	short, err := r.WithSignatureLength(rigid.MinSignatureLength)
+	require.NoError(t, err)
+	shortID, err := short.Generate("42")
+	require.NoError(t, err)
+	resp = InspectResponse{}
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/inspect", `{"id":"`+shortID+`"}`, "", &resp))
+	require.NotNil(t, resp.Verified)
+	assert.False(t, *resp.Verified)
+	assert.Equal(t, rigid.ErrSignatureLengthMismatch.Error(), resp.Error)
+
+	resp = InspectResponse{}
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/inspect", `{"id":"not-an-id"}`, "", &resp))
+	assert.Nil(t, resp.Verified)
+	assert.NotEmpty(t, resp.Error)
+}
+
+// introspect sends an introspection request for token to h and returns the status code and response.
+func introspect(t *testing.T, h http.Handler, token string) (int, IntrospectResponse) {
+	t.Helper()
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req := httptest.NewRequest("POST", "/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp IntrospectResponse
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec.Code, resp
+}
+
+func TestIntrospect(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithIssuer("auth")
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour)
+	srv := New(r)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	result, err := r.Verify(id)
+	require.NoError(t, err)
+
+	code, resp := introspect(t, srv, id)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, IntrospectResponse{
+		Active:    true,
+		JTI:       result.ULID,
+		IssuedAt:  result.Timestamp.Unix(),
+		ExpiresAt: result.ExpiresAt.Unix(),
+		Issuer:    "auth",
+		TokenType: "rigid",
+		Metadata:  "user:alice",
+	}, resp)
+
+	// Inactive tokens carry no other members
+	code, resp = introspect(t, srv, id[:len(id)-1])
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, IntrospectResponse{}, resp)
+
+	code, _ = introspect(t, srv, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/introspect", strings.NewReader("token="+strings.Repeat("a", maxBodySize)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Introspection is authenticated like the other endpoints
+	code, _ = introspect(t, New(r, WithAPIKeys("key-one")), id)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestAPIKeys(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r, WithAPIKeys("key-one", "key-two"))
+
+	assert.Equal(t, http.StatusUnauthorized, do(t, srv, "POST", "/generate", `{}`, "", nil))
+	assert.Equal(t, http.StatusUnauthorized, do(t, srv, "POST", "/verify", `{"id":""}`, "wrong", nil))
+	assert.Equal(t, http.StatusOK, do(t, srv, "POST", "/generate", `{}`, "key-one", nil))
+	assert.Equal(t, http.StatusOK, do(t, srv, "POST", "/inspect", `{"id":""}`, "key-two", nil))
+
+	// Health checks are never authenticated
+	var health map[string]string
+	assert.Equal(t, http.StatusOK, do(t, srv, "GET", "/healthz", "", "", &health))
+	assert.Equal(t, "ok", health["status"])
+}
+
+func TestAPIKeysEmpty(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// An unset environment variable must not open the API to bare bearer headers
+	for _, srv := range []*Server{New(r, WithAPIKeys("")), New(r, WithAPIKeys("", "key-one"))} {
+		req := httptest.NewRequest("POST", "/generate", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+	assert.Equal(t, http.StatusOK, do(t, New(r, WithAPIKeys("", "key-one")), "POST", "/generate", `{}`, "key-one", nil))
+}
+
+func TestMiddleware(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	m, err := apikey.NewManager(r, "sk", apikey.NewMemoryStore())
+	require.NoError(t, err)
+	key, _, err := m.Issue(context.Background(), "client")
+	require.NoError(t, err)
+
+	srv := New(r, WithMiddleware(apikey.Middleware(m)))
+	assert.Equal(t, http.StatusUnauthorized, do(t, srv, "POST", "/generate", `{}`, "", nil))
+	assert.Equal(t, http.StatusOK, do(t, srv, "POST", "/generate", `{}`, key, nil))
+}
+
+func TestDebug(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// The endpoint is opt-in
+	assert.Equal(t, http.StatusNotFound, do(t, New(r), "GET", "/debug/rigid", "", "", nil))
+
+	srv := New(r, WithDebug(), WithAPIKeys("key-one"))
+	assert.Equal(t, http.StatusUnauthorized, do(t, srv, "GET", "/debug/rigid", "", "", nil))
+	require.Equal(t, http.StatusOK, do(t, srv, "POST", "/generate", `{"count":2}`, "key-one", nil))
+
+	var vars struct {
+		Config map[string]any `json:"config"`
+		Stats  map[string]any `json:"stats"`
+	}
+	require.Equal(t, http.StatusOK, do(t, srv, "GET", "/debug/rigid", "", "key-one", &vars))
+	assert.Equal(t, r.KeyID(), vars.Config["key_id"])
+	assert.EqualValues(t, 2, vars.Stats["generated"])
+}
+
+func TestInvalidRequests(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, "POST", "/verify", `{"id":`, "", nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, srv, "POST", "/verify", `{"token":"x"}`, "", nil))
+
+	large := `{"id":"` + strings.Repeat("a", maxBodySize) + `"}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(t, srv, "POST", "/verify", large, "", nil))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/verify", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// WithVerifyCache caches successful verifications of /verify and /introspect for ttl, keeping at
+// most maxEntries IDs, for sidecars answering thousands of verifications per second next to the
+// services they serve. Results are never cached past the expiry of the ID, and failed
+// verifications are not cached. A revocation may thus take up to ttl to reach IDs in the cache,
+// and cache hits bypass the instance's statistics, hooks and audit trail.
+// The cache is disabled for instances with a replay store, which must record every use.
+func WithVerifyCache(ttl time.Duration, maxEntries int) Option {
+	return func(c *config) {
+		c.cacheTTL = ttl
+		c.cacheSize = maxEntries
+	}
+}
+
+// verifyCache holds the results of successful verifications by ID.
+type verifyCache struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result  rigid.VerifyResult
+	expires time.Time
+}
+
+func newVerifyCache(ttl time.Duration, maxEntries int) *verifyCache {
+	return &verifyCache{ttl: ttl, max: maxEntries, now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+// get returns the cached result of id, if any.
+func (c *verifyCache) get(id string) (rigid.VerifyResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok {
+		return rigid.VerifyResult{}, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, id)
+		return rigid.VerifyResult{}, false
+	}
+	return e.result, true
+}
+
+// put caches the result of id, evicting expired entries, or arbitrary ones, when full.
+func (c *verifyCache) put(id string, result rigid.VerifyResult) {
+	now := c.now()
+	expires := now.Add(c.ttl)
+	if !result.ExpiresAt.IsZero() && result.ExpiresAt.Before(expires) {
+		expires = result.ExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; !ok && len(c.entries) >= c.max {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.max {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[id] = cacheEntry{result: result, expires: expires}
+}
+
+// RevocationBatcher is a rigid.RevocationStore coalescing the revocation lookups of concurrent
+// verifications into batches, so a verifier under high load makes one round trip to the store per
+// batch rather than one per ID. Stores implementing rigid.RevocationBatchChecker, such as
+// rigidredis.Store, are queried for a batch at once; others are queried once per distinct ID of
+// a batch. Revocations are passed through to the store.
+type RevocationBatcher struct {
+	store    rigid.RevocationStore
+	window   time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *revocationBatch
+}
+
+var _ rigid.RevocationStore = (*RevocationBatcher)(nil)
+
+// revocationBatch is a batch of lookups, answered when done is closed.
+type revocationBatch struct {
+	ctx   context.Context
+	ulids []string
+	timer *time.Timer
+
+	done    chan struct{}
+	revoked map[string]bool
+	err     error
+}
+
+// NewRevocationBatcher returns a RevocationBatcher looking up revocations in s. A lookup waits at
+// most window for others to join its batch, and batches of maxBatch lookups are sent at once; a
+// maxBatch of zero or less leaves batches unbounded.
+func NewRevocationBatcher(s rigid.RevocationStore, window time.Duration, maxBatch int) *RevocationBatcher {
+	return &RevocationBatcher{store: s, window: window, maxBatch: maxBatch}
+}
+
+// Revoke implements rigid.RevocationStore.
+func (b *RevocationBatcher) Revoke(ctx context.Context, ulid string, ttl time.Duration) error {
+	return b.store.Revoke(ctx, ulid, ttl)
+}
+
+// IsRevoked implements rigid.RevocationStore, answering with the batch the lookup joins.
+// Batches are looked up with the context of their first lookup, without its cancellation.
+func (b *RevocationBatcher) IsRevoked(ctx context.Context, ulid string) (bool, error) {
+	b.mu.Lock()
+	p := b.pending
+	if p == nil {
+		p = &revocationBatch{ctx: context.WithoutCancel(ctx), done: make(chan struct{})}
+		b.pending = p
+		p.timer = time.AfterFunc(b.window, func() { b.flush(p) })
+	}
+	p.ulids = append(p.ulids, ulid)
+	full := b.maxBatch > 0 && len(p.ulids) >= b.maxBatch
+	if full {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+
+	if full {
+		p.timer.Stop()
+		b.lookup(p)
+	}
+	select {
+	case <-p.done:
+		return p.revoked[ulid], p.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// flush looks up p unless it has been sent already.
+func (b *RevocationBatcher) flush(p *revocationBatch) {
+	b.mu.Lock()
+	if b.pending != p {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+	b.lookup(p)
+}
+
+// lookup answers the lookups of p.
+func (b *RevocationBatcher) lookup(p *revocationBatch) {
+	defer close(p.done)
+
+	ulids := make([]string, 0, len(p.ulids))
+	seen := make(map[string]bool, len(p.ulids))
+	for _, u := range p.ulids {
+		if !seen[u] {
+			seen[u] = true
+			ulids = append(ulids, u)
+		}
+	}
+
+	p.revoked = make(map[string]bool, len(ulids))
+	if batch, ok := b.store.(rigid.RevocationBatchChecker); ok {
+		revoked, err := batch.AreRevoked(p.ctx, ulids)
+		if err == nil && len(revoked) != len(ulids) {
+			err = fmt.Errorf("revocation store answered %d of %d lookups", len(revoked), len(ulids))
+		}
+		if err != nil {
+			p.err = err
+			return
+		}
+		for i, u := range ulids {
+			p.revoked[u] = revoked[i]
+		}
+		return
+	}
+	for _, u := range ulids {
+		revoked, err := b.store.IsRevoked(p.ctx, u)
+		if err != nil {
+			p.err = err
+			return
+		}
+		p.revoked[u] = revoked
+	}
+}
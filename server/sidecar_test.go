@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+// countingStore is a revocation store counting its lookups.
+type countingStore struct {
+	mu      sync.Mutex
+	revoked map[string]bool
+	lookups int
+	batches [][]string
+	err     error
+}
+
+func (s *countingStore) Revoke(_ context.Context, ulid string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[ulid] = true
+	return nil
+}
+
+func (s *countingStore) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	return s.revoked[ulid], s.err
+}
+
+// batchStore is a countingStore checking batches at once.
+type batchStore struct{ *countingStore }
+
+func (s batchStore) AreRevoked(_ context.Context, ulids []string) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, ulids)
+	revoked := make([]bool, len(ulids))
+	for i, u := range ulids {
+		revoked[i] = s.revoked[u]
+	}
+	return revoked, s.err
+}
+
+func TestVerifyCache(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithTTL(time.Hour)
+	srv := New(r, WithVerifyCache(time.Minute, 10))
+	now := time.Now()
+	srv.cache.now = func() time.Time { return now }
+
+	id, err := r.Generate("order:42")
+	require.NoError(t, err)
+	for range 3 {
+		var resp VerifyResponse
+		require.Equal(t, http.StatusOK, do(t, srv, "POST", "/verify", `{"id":"`+id+`"}`, "", &resp))
+		assert.Empty(t, resp.Error)
+		assert.Equal(t, "order:42", resp.Result.Metadata)
+	}
+	code, resp := introspect(t, srv, id)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Active)
+	assert.Equal(t, uint64(1), r.Stats().Verified, "later verifications are answered from the cache")
+
+	// Failures are not cached
+	for range 2 {
+		do(t, srv, "POST", "/verify", `{"id":"`+id+`x"}`, "", nil)
+	}
+	assert.Equal(t, uint64(2), r.Stats().VerifyFailures)
+
+	now = now.Add(time.Minute)
+	do(t, srv, "POST", "/verify", `{"id":"`+id+`"}`, "", nil)
+	assert.Equal(t, uint64(2), r.Stats().Verified, "entries expire after the cache TTL")
+}
+
+func TestVerifyCacheBounds(t *testing.T) {
+	c := newVerifyCache(time.Hour, 2)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	// Entries never outlive their ID
+	c.put("a", rigid.VerifyResult{ExpiresAt: now.Add(time.Second)})
+	_, ok := c.get("a")
+	assert.True(t, ok)
+	now = now.Add(time.Second)
+	_, ok = c.get("a")
+	assert.False(t, ok)
+
+	for i := range 5 {
+		c.put(fmt.Sprint(i), rigid.VerifyResult{})
+		assert.LessOrEqual(t, len(c.entries), 2)
+	}
+	_, ok = c.get("4")
+	assert.True(t, ok, "the latest entry is kept")
+}
+
+func TestVerifyCacheDisabledWithReplayStore(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	srv := New(r.WithReplayStore(replayStore{}, time.Hour), WithVerifyCache(time.Minute, 10))
+	assert.Nil(t, srv.cache)
+}
+
+type replayStore struct{}
+
+func (replayStore) MarkUsed(context.Context, string, time.Duration) (bool, error) { return true, nil }
+
+func TestRevocationBatcher(t *testing.T) {
+	store := batchStore{&countingStore{revoked: map[string]bool{"revoked": true}}}
+	b := NewRevocationBatcher(store, 50*time.Millisecond, 0)
+
+	var wg sync.WaitGroup
+	results := make([]bool, 6)
+	for i, u := range []string{"a", "b", "revoked", "a", "c", "revoked"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			revoked, err := b.IsRevoked(context.Background(), u)
+			assert.NoError(t, err)
+			results[i] = revoked
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []bool{false, false, true, false, false, true}, results)
+	require.Len(t, store.batches, 1, "concurrent lookups share a batch")
+	assert.ElementsMatch(t, []string{"a", "b", "c", "revoked"}, store.batches[0])
+	assert.Zero(t, store.lookups)
+
+	require.NoError(t, b.Revoke(context.Background(), "a", 0))
+	revoked, err := b.IsRevoked(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevocationBatcherMaxBatch(t *testing.T) {
+	store := &countingStore{revoked: map[string]bool{}}
+	b := NewRevocationBatcher(store, time.Hour, 1)
+
+	// A full batch is sent without waiting for the window
+	revoked, err := b.IsRevoked(context.Background(), "a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, 1, store.lookups, "stores without batch support are queried per ID")
+}
+
+func TestRevocationBatcherErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	store := batchStore{&countingStore{revoked: map[string]bool{}, err: failure}}
+	b := NewRevocationBatcher(store, time.Millisecond, 0)
+	_, err := b.IsRevoked(context.Background(), "a")
+	assert.ErrorIs(t, err, failure)
+
+	slow := NewRevocationBatcher(store, time.Hour, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = slow.IsRevoked(ctx, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Verification fails rather than accepting IDs whose revocation is unknown
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r = r.WithRevocationStore(b)
+	id, err := r.Generate()
+	require.NoError(t, err)
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, failure)
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bahadrix/rigid-go"
+)
+
+var testSecretKey = []byte("test-secret-key-for-rigid-testing")
+
+// memoryStore is a minimal rigid.RevocationStore for tests.
+type memoryStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Duration
+}
+
+func (s *memoryStore) Revoke(_ context.Context, ulid string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revoked == nil {
+		s.revoked = map[string]time.Duration{}
+	}
+	s.revoked[ulid] = ttl
+	return nil
+}
+
+func (s *memoryStore) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.revoked[ulid]
+	return ok, nil
+}
+
+func newManager(t *testing.T, opts ...Option) *Manager {
+	t.Helper()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	m, err := NewManager(r, opts...)
+	require.NoError(t, err)
+	return m
+}
+
+func TestCreateAndValidate(t *testing.T) {
+	m := newManager(t, WithTTL(time.Hour))
+	ctx := context.Background()
+
+	s, err := m.Create(ctx, Claims{UserID: "alice@example.com", IP: "2001:db8::1"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", s.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), s.ExpiresAt, time.Second)
+
+	got, err := m.Validate(ctx, s.ID, "2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, s, got)
+
+	_, err = m.Validate(ctx, s.ID, "203.0.113.9")
+	assert.ErrorIs(t, err, ErrIPMismatch)
+
+	_, err = m.Validate(ctx, s.ID, "")
+	assert.NoError(t, err)
+}
+
+func TestCreateWithReplayStore(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	m, err := NewManager(r.WithReplayStore(rigid.NewMemoryStore(0), time.Hour))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Creating does not spend the single use of the session ID
+	s, err := m.Create(ctx, Claims{UserID: "alice"})
+	require.NoError(t, err)
+	got, err := m.Validate(ctx, s.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, s, got)
+	_, err = m.Validate(ctx, s.ID, "")
+	assert.ErrorIs(t, err, rigid.ErrReplayed)
+}
+
+func TestUnboundSession(t *testing.T) {
+	m := newManager(t)
+
+	s, err := m.Create(context.Background(), Claims{UserID: "bob"})
+	require.NoError(t, err)
+
+	_, err = m.Validate(context.Background(), s.ID, "203.0.113.9")
+	assert.NoError(t, err)
+}
+
+func TestInvalidSessions(t *testing.T) {
+	m := newManager(t, WithTTL(time.Hour))
+	ctx := context.Background()
+
+	_, err := m.Create(ctx, Claims{})
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	expired, err := r.GenerateAt(time.Now().Add(-2*time.Hour), "u=alice")
+	require.NoError(t, err)
+	_, err = m.Validate(ctx, expired, "")
+	assert.ErrorIs(t, err, rigid.ErrExpired)
+
+	noClaims, err := r.Generate("plain-metadata")
+	require.NoError(t, err)
+	_, err = m.Validate(ctx, noClaims, "")
+	assert.ErrorIs(t, err, ErrInvalidClaims)
+
+	other, err := rigid.NewRigid([]byte("another-secret-key"))
+	require.NoError(t, err)
+	forged, err := other.Generate("u=alice")
+	require.NoError(t, err)
+	_, err = m.Validate(ctx, forged, "")
+	assert.ErrorIs(t, err, rigid.ErrIntegrityFailure)
+}
+
+func TestRevoke(t *testing.T) {
+	store := &memoryStore{}
+	m := newManager(t, WithTTL(time.Hour), WithStore(store))
+	ctx := context.Background()
+
+	s, err := m.Create(ctx, Claims{UserID: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, m.Revoke(ctx, s.ID))
+
+	ttl := store.revoked[s.ID[:26]]
+	assert.Greater(t, ttl, 59*time.Minute)
+	assert.LessOrEqual(t, ttl, time.Hour+time.Second)
+
+	_, err = m.Validate(ctx, s.ID, "")
+	assert.ErrorIs(t, err, rigid.ErrRevoked)
+
+	s, err = newManager(t).Create(ctx, Claims{UserID: "alice"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, newManager(t).Revoke(ctx, s.ID), rigid.ErrNoRevocationStore)
+}
+
+func TestRenew(t *testing.T) {
+	store := &memoryStore{}
+	m := newManager(t, WithTTL(time.Hour), WithRenewWindow(10*time.Minute), WithStore(store))
+	ctx := context.Background()
+
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+	aging, err := r.GenerateAt(time.Now().Add(-55*time.Minute), "ip=198.51.100.7&u=alice")
+	require.NoError(t, err)
+
+	old, err := m.Validate(ctx, aging, "198.51.100.7")
+	require.NoError(t, err)
+	assert.True(t, m.NeedsRenewal(old))
+
+	renewed, err := m.Renew(ctx, aging, "198.51.100.7")
+	require.NoError(t, err)
+	assert.NotEqual(t, aging, renewed.ID)
+	assert.Equal(t, old.Claims, renewed.Claims)
+	assert.False(t, m.NeedsRenewal(renewed))
+
+	_, err = m.Validate(ctx, aging, "")
+	assert.ErrorIs(t, err, rigid.ErrRevoked)
+
+	// Without a store the old session stays valid until it expires.
+	plain := newManager(t)
+	s, err := plain.Create(ctx, Claims{UserID: "bob"})
+	require.NoError(t, err)
+	_, err = plain.Renew(ctx, s.ID, "")
+	assert.NoError(t, err)
+}
+
+func TestNewManagerErrors(t *testing.T) {
+	r, err := rigid.NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	_, err = NewManager(r, WithTTL(0))
+	assert.Error(t, err)
+
+	_, err = NewManager(r, WithTTL(time.Hour), WithRenewWindow(2*time.Hour))
+	assert.Error(t, err)
+}
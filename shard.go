@@ -0,0 +1,48 @@
+package rigid
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrInvalidShardCount indicates the number of shards passed to Shard is not positive.
+var ErrInvalidShardCount = errors.New("shard count must be positive")
+
+// Shard returns the shard in [0, n) that the rigid ID id routes to, for partitioned storage
+// layers that spread rows by ID. The shard is derived from the low 64 bits of the ULID entropy
+// only, so IDs generated in the same millisecond spread evenly and a burst of writes does not
+// land on one shard, and node IDs, which occupy the top entropy bits, do not skew it. Signatures,
+// prefixes and metadata are ignored, so every rendering of one ULID routes alike. The mapping
+// is stable across processes and releases.
+// Shard does not verify signatures. Returns ErrInvalidShardCount if n is not positive, or
+// ErrInvalidFormat or ErrInvalidULID if id is malformed.
+func Shard(id string, n int) (int, error) {
+	if n <= 0 {
+		return 0, ErrInvalidShardCount
+	}
+	seg, err := splitID(id)
+	if err != nil {
+		return 0, err
+	}
+	u, err := ulid.ParseStrict(seg.ulid)
+	if err != nil {
+		return 0, ErrInvalidULID
+	}
+	return shardOf(u, n), nil
+}
+
+// shardOf maps the low 64 entropy bits of u to [0, n).
+func shardOf(u ulid.ULID, n int) int {
+	// Finalizer of SplitMix64, so consecutive monotonic entropy values spread over all shards
+	h := binary.BigEndian.Uint64(u[8:])
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	hi, _ := bits.Mul64(h, uint64(n))
+	return int(hi)
+}
@@ -0,0 +1,71 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShard(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	s, err := Shard(id, 16)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, s, 0)
+	assert.Less(t, s, 16)
+
+	// Stable, and independent of the signature, prefix and metadata
+	u, err := r.ExtractULID(id)
+	require.NoError(t, err)
+	other := "usr_" + u.String() + "-AAAAAAAAAAAAAAAAAAAAAAAAAA"
+	for range 3 {
+		again, err := Shard(other, 16)
+		require.NoError(t, err)
+		assert.Equal(t, s, again)
+	}
+
+	one, err := Shard(id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, one)
+
+	// A fixed vector guards against the mapping changing between releases
+	s, err = Shard("01ARZ3NDEKTSV4RRFFQ69G5FAV-AAAAAAAAAAAAAAAAAAAAAAAAAA", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 874, s)
+}
+
+func TestShardSpread(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// IDs of one millisecond only differ in their monotonic entropy
+	const n, count = 8, 8000
+	at := time.Now()
+	counts := make([]int, n)
+	for range count {
+		id, err := r.GenerateAt(at)
+		require.NoError(t, err)
+		s, err := Shard(id, n)
+		require.NoError(t, err)
+		counts[s]++
+	}
+	for i, c := range counts {
+		assert.InDelta(t, count/n, c, count/n/4, "shard %d", i)
+	}
+}
+
+func TestShardInvalid(t *testing.T) {
+	_, err := Shard("01ARZ3NDEKTSV4RRFFQ69G5FAV-AAAAAAAAAAAAAAAAAAAAAAAAAA", 0)
+	assert.ErrorIs(t, err, ErrInvalidShardCount)
+	_, err = Shard("01ARZ3NDEKTSV4RRFFQ69G5FAV-AAAAAAAAAAAAAAAAAAAAAAAAAA", -1)
+	assert.ErrorIs(t, err, ErrInvalidShardCount)
+	_, err = Shard("not-an-id", 4)
+	assert.ErrorIs(t, err, ErrInvalidULID)
+	_, err = Shard("nodash", 4)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
@@ -0,0 +1,138 @@
+package rigid
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// stateReserve is how far ahead of the IDs generated so far the saved state reaches, so the state
+// is saved at most about once per stateReserve rather than for every ID.
+const stateReserve = time.Second
+
+// StateStore persists the generator state of an instance across restarts, see WithStateStore.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	// LoadState returns the saved timestamp, or the zero time if none has been saved.
+	LoadState() (time.Time, error)
+	// SaveState durably replaces the saved timestamp with t.
+	SaveState(t time.Time) error
+}
+
+// stateKeeper keeps the timestamps of a generator after those saved by earlier runs, and saves
+// the timestamps it hands out ahead of time.
+type stateKeeper struct {
+	mu    sync.Mutex
+	store StateStore
+	// floor is the earliest Unix millisecond IDs may be generated in
+	floor uint64
+	// saved is the Unix millisecond up to which IDs may be generated without saving again
+	saved atomic.Uint64
+}
+
+// WithStateStore returns a copy of r that persists its generator state in s, so IDs generated
+// after a restart always sort after those of earlier runs, even if the clock stepped back or the
+// restart took less than a millisecond. The state is saved ahead of the IDs generated, at most
+// about once per second, and IDs that would sort before the saved state are generated with the
+// timestamp following it instead. After a fast restart, IDs may thus carry timestamps up to a
+// second in the future; this also applies to the timestamps passed to GenerateAt, so use an
+// instance without a state store for backfills.
+// Generation fails if the state cannot be saved. s must not be shared by instances running
+// concurrently, including those of other processes.
+// Unlike the other With methods, the returned instance does not share its entropy source with r.
+// Returns an error if the state cannot be loaded.
+func (r *Rigid) WithStateStore(s StateStore) (*Rigid, error) {
+	t, err := s.LoadState()
+	if err != nil {
+		return nil, fmt.Errorf("load generator state: %w", err)
+	}
+
+	k := &stateKeeper{store: s}
+	if !t.IsZero() && t.After(time.UnixMilli(0)) {
+		k.floor = ulid.Timestamp(t) + 1
+		k.saved.Store(k.floor - 1)
+	}
+	c := r.clone()
+	c.gen = newGenerator(len(r.gen.shards), r.gen.node, k, r.gen.source)
+	return c, nil
+}
+
+// reserve returns the timestamp to generate an ID with instead of ms, saving the state if needed.
+func (k *stateKeeper) reserve(ms uint64) (uint64, error) {
+	ms = max(ms, k.floor)
+	if ms <= k.saved.Load() || ms > ulid.MaxTime() {
+		return ms, nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if ms <= k.saved.Load() {
+		return ms, nil
+	}
+	until := min(ms+uint64(stateReserve.Milliseconds()), ulid.MaxTime())
+	if err := k.store.SaveState(ulid.Time(until)); err != nil {
+		return 0, fmt.Errorf("save generator state: %w", err)
+	}
+	k.saved.Store(until)
+	return ms, nil
+}
+
+// StateFile is a StateStore keeping the state in a file, written atomically by replacing it.
+type StateFile struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStateFile returns a StateStore keeping the state in the file at path. The file is created
+// with mode 0600 on the first save; a missing file holds no state.
+func NewStateFile(path string) *StateFile {
+	return &StateFile{path: path}
+}
+
+// LoadState implements StateStore.
+func (f *StateFile) LoadState() (time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse state file %s: %w", f.path, err)
+	}
+	return t, nil
+}
+
+// SaveState implements StateStore. The state is written to a temporary file that is synced and
+// renamed over the state file, so a crash leaves either the old or the new state.
+func (f *StateFile) SaveState(t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(t.UTC().Format(time.RFC3339Nano) + "\n")
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
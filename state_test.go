@@ -0,0 +1,136 @@
+package rigid
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryState is a StateStore recording the saved states.
+type memoryState struct {
+	mu      sync.Mutex
+	t       time.Time
+	saves   []time.Time
+	loadErr error
+	saveErr error
+}
+
+func (m *memoryState) LoadState() (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.t, m.loadErr
+}
+
+func (m *memoryState) SaveState(t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.t = t
+	m.saves = append(m.saves, t)
+	return nil
+}
+
+func idTime(t *testing.T, id string) time.Time {
+	t.Helper()
+	u, err := ulid.ParseStrict(id[:ulid.EncodedSize])
+	require.NoError(t, err)
+	return ulid.Time(u.Time())
+}
+
+func TestWithStateStore(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	// The previous run saved a state ahead of the clock, as after the clock stepped back
+	saved := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	store := &memoryState{t: saved}
+	s, err := r.WithStateStore(store)
+	require.NoError(t, err)
+	assert.True(t, s.Config().StateStore)
+	assert.False(t, r.Config().StateStore)
+
+	previous, err := r.GenerateAt(saved)
+	require.NoError(t, err)
+	first, err := s.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, saved.Add(time.Millisecond), idTime(t, first))
+	assert.Less(t, previous, first)
+
+	for range 100 {
+		_, err := s.Generate()
+		require.NoError(t, err)
+	}
+	require.Len(t, store.saves, 1, "the state is saved ahead of the IDs")
+	assert.Equal(t, saved.Add(time.Millisecond+stateReserve), store.saves[0])
+
+	later, err := s.WithEntropyShards(2).GenerateAt(saved.Add(2 * stateReserve))
+	require.NoError(t, err)
+	assert.Equal(t, saved.Add(2*stateReserve), idTime(t, later), "derived instances share the state")
+	assert.Len(t, store.saves, 2)
+}
+
+func TestWithStateStoreErrors(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	failure := errors.New("disk full")
+
+	_, err = r.WithStateStore(&memoryState{loadErr: failure})
+	assert.ErrorIs(t, err, failure)
+
+	s, err := r.WithStateStore(&memoryState{saveErr: failure})
+	require.NoError(t, err)
+	_, err = s.Generate()
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, uint64(1), s.Stats().GenerateFailures)
+}
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rigid.state")
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	first, err := r.WithStateStore(NewStateFile(path))
+	require.NoError(t, err)
+	var last string
+	for range 10 {
+		last, err = first.Generate()
+		require.NoError(t, err)
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	saved, err := NewStateFile(path).LoadState()
+	require.NoError(t, err)
+	assert.False(t, saved.Before(idTime(t, last)), "the saved state covers the IDs generated")
+
+	// A restart within the same millisecond still sorts after the previous run
+	second, err := r.WithStateStore(NewStateFile(path))
+	require.NoError(t, err)
+	next, err := second.Generate()
+	require.NoError(t, err)
+	assert.Less(t, last, next)
+	assert.True(t, idTime(t, next).After(saved))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	missing, err := NewStateFile(filepath.Join(t.TempDir(), "missing")).LoadState()
+	require.NoError(t, err)
+	assert.True(t, missing.IsZero())
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = r.WithStateStore(NewStateFile(path))
+	assert.Error(t, err)
+}
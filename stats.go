@@ -0,0 +1,156 @@
+package rigid
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Failure reasons reported by FailureReason and counted by Stats.
+const (
+	ReasonTooLong                 = "too_long"
+	ReasonInvalidFormat           = "invalid_format"
+	ReasonInvalidULID             = "invalid_ulid"
+	ReasonInvalidMetadata         = "invalid_metadata"
+	ReasonSignatureLengthMismatch = "signature_length_mismatch"
+	ReasonIntegrity               = "integrity"
+	ReasonTimestampOutOfRange     = "timestamp_out_of_range"
+	ReasonUntrustedIssuer         = "untrusted_issuer"
+	ReasonExpired                 = "expired"
+	ReasonRevoked                 = "revoked"
+	ReasonReplayed                = "replayed"
+	ReasonRateLimited             = "rate_limited"
+	ReasonOther                   = "other"
+)
+
+// reasons maps the errors verification fails with to their reasons. ErrSignatureLengthMismatch
+// wraps ErrIntegrityFailure, so it comes first.
+var reasons = [...]struct {
+	err    error
+	reason string
+}{
+	{ErrTooLong, ReasonTooLong},
+	{ErrInvalidFormat, ReasonInvalidFormat},
+	{ErrInvalidULID, ReasonInvalidULID},
+	{ErrInvalidMetadata, ReasonInvalidMetadata},
+	{ErrSignatureLengthMismatch, ReasonSignatureLengthMismatch},
+	{ErrIntegrityFailure, ReasonIntegrity},
+	{ErrTimestampOutOfRange, ReasonTimestampOutOfRange},
+	{ErrUntrustedIssuer, ReasonUntrustedIssuer},
+	{ErrExpired, ReasonExpired},
+	{ErrRevoked, ReasonRevoked},
+	{ErrReplayed, ReasonReplayed},
+	{ErrRateLimited, ReasonRateLimited},
+}
+
+// FailureReason returns a short, stable name for the reason verification failed with err,
+// suitable as a metric label: one of the Reason constants. Errors of revocation and replay
+// stores, and errors not returned by verification, are ReasonOther. Returns "" for a nil error.
+func FailureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return reasonName(reasonIndex(err))
+}
+
+// reasonIndex returns the index of the reason of err in reasons, or len(reasons) for ReasonOther.
+func reasonIndex(err error) int {
+	for i, r := range reasons {
+		if errors.Is(err, r.err) {
+			return i
+		}
+	}
+	return len(reasons)
+}
+
+// reasonName returns the reason at index i of reasons, or ReasonOther.
+func reasonName(i int) string {
+	if i < len(reasons) {
+		return reasons[i].reason
+	}
+	return ReasonOther
+}
+
+// Stats are the counters of an instance, see Rigid.Stats.
+type Stats struct {
+	// Since is the time the counters started, when NewRigid was called.
+	Since time.Time
+	// Generated is the number of IDs generated.
+	Generated uint64
+	// GenerateFailures is the number of generations that failed.
+	GenerateFailures uint64
+	// Verified is the number of verifications that succeeded.
+	Verified uint64
+	// VerifyFailures is the number of verifications that failed.
+	VerifyFailures uint64
+	// FailuresByReason is the number of failed verifications by FailureReason. Reasons that
+	// did not occur are omitted.
+	FailuresByReason map[string]uint64
+	// AvgGenerateLatency is the average duration of a generation, failed or not.
+	AvgGenerateLatency time.Duration
+	// AvgVerifyLatency is the average duration of a verification, failed or not, including
+	// revocation and replay checks.
+	AvgVerifyLatency time.Duration
+}
+
+// stats are the counters shared by an instance and the instances derived from it.
+type stats struct {
+	since            time.Time
+	generated        atomic.Uint64
+	generateFailures atomic.Uint64
+	generateNanos    atomic.Uint64
+	verified         atomic.Uint64
+	verifyNanos      atomic.Uint64
+	failures         [len(reasons) + 1]atomic.Uint64
+}
+
+func newStats() *stats {
+	return &stats{since: time.Now()}
+}
+
+// generate counts a generation that took d and failed with err, if not nil.
+func (s *stats) generate(d time.Duration, err error) {
+	if err != nil {
+		s.generateFailures.Add(1)
+	} else {
+		s.generated.Add(1)
+	}
+	s.generateNanos.Add(uint64(d))
+}
+
+// verify counts a verification that took d and failed with err, if not nil.
+func (s *stats) verify(d time.Duration, err error) {
+	if err != nil {
+		s.failures[reasonIndex(err)].Add(1)
+	} else {
+		s.verified.Add(1)
+	}
+	s.verifyNanos.Add(uint64(d))
+}
+
+// Stats returns the number of IDs generated and verified, verification failures by reason, and
+// average latencies since the instance was created with NewRigid. Counters are shared by r and
+// all instances derived from the same NewRigid call, such as with WithPrefix, and are updated
+// atomically; the counters returned may be a few operations apart from each other.
+func (r *Rigid) Stats() Stats {
+	s := Stats{
+		Since:            r.stats.since,
+		Generated:        r.stats.generated.Load(),
+		GenerateFailures: r.stats.generateFailures.Load(),
+		Verified:         r.stats.verified.Load(),
+		FailuresByReason: make(map[string]uint64),
+	}
+	for i := range r.stats.failures {
+		if n := r.stats.failures[i].Load(); n > 0 {
+			s.FailuresByReason[reasonName(i)] = n
+			s.VerifyFailures += n
+		}
+	}
+	if n := s.Generated + s.GenerateFailures; n > 0 {
+		s.AvgGenerateLatency = time.Duration(r.stats.generateNanos.Load() / n)
+	}
+	if n := s.Verified + s.VerifyFailures; n > 0 {
+		s.AvgVerifyLatency = time.Duration(r.stats.verifyNanos.Load() / n)
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason string
+	}{
+		{nil, ""},
+		{ErrInvalidFormat, ReasonInvalidFormat},
+		{ErrInvalidULID, ReasonInvalidULID},
+		{ErrIntegrityFailure, ReasonIntegrity},
+		{ErrSignatureLengthMismatch, ReasonSignatureLengthMismatch},
+		{ErrTooLong, ReasonTooLong},
+		{fmt.Errorf("%w: control character", ErrInvalidMetadata), ReasonInvalidMetadata},
+		{ErrTimestampOutOfRange, ReasonTimestampOutOfRange},
+		{ErrUntrustedIssuer, ReasonUntrustedIssuer},
+		{ErrExpired, ReasonExpired},
+		{ErrRevoked, ReasonRevoked},
+		{ErrReplayed, ReasonReplayed},
+		{fmt.Errorf("check revocation: %w", errors.New("connection refused")), ReasonOther},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.reason, FailureReason(tt.err), "%v", tt.err)
+	}
+}
+
+func TestStatsUntrustedIssuer(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	acme, err := r.WithIssuer("acme")
+	require.NoError(t, err)
+	id, err := acme.Generate()
+	require.NoError(t, err)
+
+	_, err = r.Verify(id)
+	require.ErrorIs(t, err, ErrUntrustedIssuer)
+	assert.Equal(t, map[string]uint64{ReasonUntrustedIssuer: 1}, r.Stats().FailuresByReason)
+}
+
+func TestStats(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Since: r.Stats().Since, FailuresByReason: map[string]uint64{}}, r.Stats())
+	assert.WithinDuration(t, time.Now(), r.Stats().Since, time.Minute)
+
+	typed, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	typed = typed.WithReplayStore(newMapStore(), time.Minute)
+
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+	_, err = typed.AppendGenerate(nil)
+	require.NoError(t, err)
+	typedID, err := typed.Generate()
+	require.NoError(t, err)
+	_, err = r.WithMaxLength(30).Generate("user:alice")
+	require.ErrorIs(t, err, ErrTooLong)
+
+	_, err = r.Verify(id)
+	require.NoError(t, err)
+	_, err = r.VerifyBytes([]byte(id))
+	require.NoError(t, err)
+	_, err = typed.VerifyContext(context.Background(), typedID)
+	require.NoError(t, err)
+	_, err = typed.Verify(typedID)
+	require.ErrorIs(t, err, ErrReplayed)
+	_, err = r.Verify(id[:27] + "AAAAAAAAAAAAA")
+	require.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.VerifyBytes([]byte(id[:27] + "AAAAAAAAAAAAA"))
+	require.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.Verify("invalid")
+	require.Error(t, err)
+
+	// Counters are shared by all instances derived from r
+	for _, g := range []*Rigid{r, typed} {
+		s := g.Stats()
+		assert.Equal(t, uint64(3), s.Generated)
+		assert.Equal(t, uint64(1), s.GenerateFailures)
+		assert.Equal(t, uint64(3), s.Verified)
+		assert.Equal(t, uint64(4), s.VerifyFailures)
+		assert.Equal(t, map[string]uint64{
+			ReasonIntegrity:     2,
+			ReasonReplayed:      1,
+			ReasonInvalidFormat: 1,
+		}, s.FailuresByReason)
+		assert.Positive(t, s.AvgGenerateLatency)
+		assert.Positive(t, s.AvgVerifyLatency)
+	}
+
+	// Instances created separately count separately
+	other, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	assert.Zero(t, other.Stats().Verified)
+}
@@ -0,0 +1,138 @@
+package rigid
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RevocationStore records revoked IDs, keyed by their ULID.
+// Implementations must be safe for concurrent use.
+type RevocationStore interface {
+	// Revoke marks the ID with the given ULID as revoked. A positive ttl allows the store to
+	// forget the entry once the ID can no longer be presented; zero keeps it indefinitely.
+	Revoke(ctx context.Context, ulid string, ttl time.Duration) error
+	// IsRevoked reports whether the ID with the given ULID has been revoked.
+	IsRevoked(ctx context.Context, ulid string) (bool, error)
+}
+
+// Revocation is a revoked ID listed by a RevocationLister.
+type Revocation struct {
+	ULID string
+	// ExpiresAt is the time the store forgets the revocation, or the zero time if it is kept indefinitely.
+	ExpiresAt time.Time
+}
+
+// RevocationLister is implemented by revocation stores able to enumerate their entries,
+// such as for auditing or the rigid command-line tool.
+type RevocationLister interface {
+	// ListRevoked returns the current revocations, ordered by ULID.
+	ListRevoked(ctx context.Context) ([]Revocation, error)
+}
+
+// RevocationBatchChecker is implemented by revocation stores able to check several IDs in one
+// round trip, which batching verifiers such as server.RevocationBatcher use when available.
+type RevocationBatchChecker interface {
+	// AreRevoked reports for each of the given ULIDs whether its ID has been revoked.
+	AreRevoked(ctx context.Context, ulids []string) ([]bool, error)
+}
+
+// ReplayStore records the IDs that have been presented, making them single-use.
+// Implementations must be safe for concurrent use.
+type ReplayStore interface {
+	// MarkUsed records a use of the ID with the given ULID and reports whether it was the first.
+	// A positive ttl allows the store to forget the entry after that duration.
+	MarkUsed(ctx context.Context, ulid string, ttl time.Duration) (bool, error)
+}
+
+// WithRevocationStore returns a copy of r whose verification rejects IDs revoked in s with ErrRevoked.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithRevocationStore(s RevocationStore) *Rigid {
+	c := r.clone()
+	c.revocations = s
+	return c
+}
+
+// WithReplayStore returns a copy of r that accepts each ID only once, rejecting later
+// presentations with ErrReplayed. Uses are remembered in s for ttl, or indefinitely if ttl is zero.
+// Every successful verification counts as a use, including decoding an ID type through the
+// default verifier, so single-use instances should be kept apart from general-purpose ones.
+// The returned instance shares the secret key and entropy source with r.
+func (r *Rigid) WithReplayStore(s ReplayStore, ttl time.Duration) *Rigid {
+	c := r.clone()
+	c.replays = s
+	c.replayTTL = ttl
+	return c
+}
+
+// VerifyContext verifies id like Verify, passing ctx to the configured revocation and replay stores.
+// IDs failing the integrity check are rejected before any store is consulted, and an ID is only
+// marked as used once it has passed the revocation check. The rate limiter, if any, is consulted
+// for the caller of ctx before the ID is checked at all. Options adjust the checks of this call
+// only, and apply before the stores are consulted.
+func (r *Rigid) VerifyContext(ctx context.Context, id string, opts ...VerifyOption) (VerifyResult, error) {
+	start := time.Now()
+	var result VerifyResult
+	v := r
+	err := r.allow(ctx)
+	var policy *verifyPolicy
+	if err == nil && len(opts) > 0 {
+		policy = newVerifyPolicy(opts)
+		v, err = policy.instance(r)
+	}
+	if err == nil {
+		result, err = v.verify(id)
+	}
+	if err == nil && policy != nil {
+		result, err = policy.check(result, time.Now())
+	}
+	if err == nil {
+		result, err = v.checkStores(ctx, result)
+	}
+	if err != nil {
+		result = VerifyResult{}
+	}
+	r.observeVerify(ctx, start, id, result, err)
+	return result, err
+}
+
+// checkStores checks a verified result against the configured revocation and replay stores.
+func (r *Rigid) checkStores(ctx context.Context, result VerifyResult) (VerifyResult, error) {
+	if r.revocations != nil {
+		revoked, err := r.revocations.IsRevoked(ctx, result.ULID)
+		if err != nil {
+			return VerifyResult{}, fmt.Errorf("check revocation: %w", err)
+		}
+		if revoked {
+			return VerifyResult{}, ErrRevoked
+		}
+	}
+
+	if r.replays != nil {
+		first, err := r.replays.MarkUsed(ctx, result.ULID, r.replayTTL)
+		if err != nil {
+			return VerifyResult{}, fmt.Errorf("check replay: %w", err)
+		}
+		if !first {
+			return VerifyResult{}, ErrReplayed
+		}
+	}
+
+	return result, nil
+}
+
+// Revoke verifies id and records it as revoked in the configured revocation store,
+// so IDs that were not issued with this key cannot be written to the store.
+// See RevocationStore.Revoke for the meaning of ttl.
+// Returns ErrNoRevocationStore if r has no revocation store.
+func (r *Rigid) Revoke(ctx context.Context, id string, ttl time.Duration) error {
+	if r.revocations == nil {
+		return ErrNoRevocationStore
+	}
+
+	result, err := r.verify(id)
+	if err != nil {
+		return err
+	}
+	return r.revocations.Revoke(ctx, result.ULID, ttl)
+}
@@ -0,0 +1,155 @@
+package rigid
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is a minimal RevocationStore and ReplayStore for tests.
+type mapStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Duration
+	used    map[string]time.Duration
+	err     error
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{revoked: map[string]time.Duration{}, used: map[string]time.Duration{}}
+}
+
+func (s *mapStore) Revoke(_ context.Context, ulid string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[ulid] = ttl
+	return s.err
+}
+
+func (s *mapStore) IsRevoked(_ context.Context, ulid string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.revoked[ulid]
+	return ok, s.err
+}
+
+func (s *mapStore) MarkUsed(_ context.Context, ulid string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, ok := s.used[ulid]; ok {
+		return false, nil
+	}
+	s.used[ulid] = ttl
+	return true, nil
+}
+
+func TestRevocation(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	store := newMapStore()
+	r := base.WithRevocationStore(store)
+	ctx := context.Background()
+
+	id, err := r.Generate("session")
+	require.NoError(t, err)
+	other, err := r.Generate()
+	require.NoError(t, err)
+
+	require.NoError(t, r.Revoke(ctx, id, time.Hour))
+	assert.Equal(t, time.Hour, store.revoked[id[:26]])
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrRevoked)
+	_, err = r.VerifyContext(ctx, id)
+	assert.ErrorIs(t, err, ErrRevoked)
+
+	_, err = r.Verify(other)
+	assert.NoError(t, err)
+
+	// The base instance does not consult the store.
+	_, err = base.Verify(id)
+	assert.NoError(t, err)
+}
+
+func TestRevokeRejectsUnverifiedIDs(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, r.Revoke(context.Background(), id, 0), ErrNoRevocationStore)
+
+	store := newMapStore()
+	r = r.WithRevocationStore(store)
+	err = r.Revoke(context.Background(), id[:27]+"AAAAAAAAAAAAA", 0)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	assert.Empty(t, store.revoked)
+}
+
+func TestReplay(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	store := newMapStore()
+	r := base.WithReplayStore(store, 15*time.Minute)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	result, err := r.Verify(id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, 15*time.Minute, store.used[id[:26]])
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrReplayed)
+
+	// Forged IDs are rejected before reaching the store.
+	_, err = r.Verify(id[:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	assert.Len(t, store.used, 1)
+}
+
+func TestRevokedIDsAreNotMarkedUsed(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	revocations, replays := newMapStore(), newMapStore()
+	r = r.WithRevocationStore(revocations).WithReplayStore(replays, 0)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(context.Background(), id, 0))
+
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrRevoked)
+	assert.Empty(t, replays.used)
+}
+
+func TestStoreErrors(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+
+	unavailable := errors.New("store unavailable")
+	store := newMapStore()
+	store.err = unavailable
+
+	result, err := r.WithRevocationStore(store).Verify(id)
+	assert.ErrorIs(t, err, unavailable)
+	assert.False(t, result.Valid)
+
+	_, err = r.WithReplayStore(store, 0).Verify(id)
+	assert.ErrorIs(t, err, unavailable)
+}
@@ -0,0 +1,74 @@
+package rigid
+
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ULIDRange returns the smallest and the largest ULID with a timestamp in [from, to), in their
+// canonical encoding, so rows keyed by ULID can be selected by creation time with an indexed
+// range query, such as WHERE ulid BETWEEN $1 AND $2, instead of extracting the timestamp of
+// every row. Timestamps have millisecond precision, so from and to are truncated to the
+// millisecond, and times outside the range of ULIDs are clamped to it. If to is not after from,
+// maxULID sorts before minULID and the range matches nothing.
+// Columns holding full rigid IDs rather than ULIDs are queried with the bounds of Rigid.IDRange.
+func ULIDRange(from, to time.Time) (minULID, maxULID string) {
+	lo := ulidAt(ulidMillis(from), 0x00)
+	hi := ulidMillis(to)
+	if hi == 0 {
+		return lo, ""
+	}
+	return lo, ulidAt(hi-1, 0xFF)
+}
+
+// IDRange returns bounds selecting the IDs generated by r with a timestamp in [from, to), for
+// range queries over a column holding full rigid IDs, including their prefix, signature and
+// metadata: every such ID sorts between lo and hi, and lo and hi are not IDs themselves, so they
+// serve as inclusive or exclusive bounds alike. Columns must compare bytewise, as with a binary
+// or "C" collation. For instances obfuscating timestamps, the range is widened to whole periods
+// of their granularity, within which timestamps are permuted.
+// Returns ErrNoTimestamp if r generates unordered IDs.
+func (r *Rigid) IDRange(from, to time.Time) (lo, hi string, err error) {
+	if r.unordered {
+		return "", "", ErrNoTimestamp
+	}
+
+	start, end := ulidMillis(from), ulidMillis(to)
+	if c := r.obfuscation; c != nil {
+		start -= start % c.granularity
+		if rem := end % c.granularity; rem != 0 {
+			end = min(end-rem+c.granularity, ulid.MaxTime()+1)
+		}
+	}
+
+	var prefix string
+	if r.prefix != "" {
+		prefix = r.prefix + "_"
+	}
+	lo = prefix + ulidAt(start, 0x00)
+	if end == 0 {
+		return lo, lo, nil
+	}
+	// IDs continue after the ULID with '-', which sorts right before '.'
+	return lo, prefix + ulidAt(end-1, 0xFF) + ".", nil
+}
+
+// ulidMillis returns the ULID timestamp of t, clamped to the range of ULIDs.
+func ulidMillis(t time.Time) uint64 {
+	ms := t.UnixMilli()
+	if ms < 0 {
+		return 0
+	}
+	return min(uint64(ms), ulid.MaxTime())
+}
+
+// ulidAt returns the encoding of the ULID with timestamp ms whose entropy bytes are all b.
+func ulidAt(ms uint64, b byte) string {
+	var u ulid.ULID
+	_ = u.SetTime(ms)
+	for i := 6; i < len(u); i++ {
+		u[i] = b
+	}
+	return u.String()
+}
@@ -0,0 +1,105 @@
+package rigid
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULIDRange(t *testing.T) {
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	april := march.AddDate(0, 1, 0)
+	lo, hi := ULIDRange(march, april)
+
+	loULID, err := ulid.ParseStrict(lo)
+	require.NoError(t, err)
+	hiULID, err := ulid.ParseStrict(hi)
+	require.NoError(t, err)
+	assert.Equal(t, ulid.Timestamp(march), loULID.Time())
+	assert.Equal(t, ulid.Timestamp(april)-1, hiULID.Time())
+	assert.Equal(t, "0000000000000000", lo[10:], "the lower bound has zero entropy")
+	assert.Equal(t, "ZZZZZZZZZZZZZZZZ", hi[10:])
+
+	in := func(ts time.Time) bool {
+		u := ulid.MustNew(ulid.Timestamp(ts), ulid.DefaultEntropy()).String()
+		return lo <= u && u <= hi
+	}
+	assert.True(t, in(march))
+	assert.True(t, in(march.Add(15*24*time.Hour)))
+	assert.True(t, in(april.Add(-time.Millisecond)))
+	assert.False(t, in(april))
+	assert.False(t, in(march.Add(-time.Millisecond)))
+
+	// Empty and clamped ranges
+	lo, hi = ULIDRange(april, march)
+	assert.Less(t, hi, lo)
+	lo, hi = ULIDRange(time.Unix(-10, 0), time.Unix(0, 0))
+	assert.Equal(t, "00000000000000000000000000", lo)
+	assert.Empty(t, hi)
+	_, hi = ULIDRange(march, time.Date(20000, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "7ZZZZZZZZ", hi[:9])
+}
+
+func TestIDRange(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err := base.WithPrefix("ord")
+	require.NoError(t, err)
+
+	day := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+	lo, hi, err := r.IDRange(day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "ord_", lo[:4])
+
+	var ids []string
+	for _, ts := range []time.Time{day.Add(-time.Millisecond), day, day.Add(12 * time.Hour), day.Add(24*time.Hour - time.Millisecond), day.Add(24 * time.Hour)} {
+		id, err := r.GenerateAt(ts, "metadata-with~high:chars")
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	var selected []string
+	for _, id := range ids {
+		if lo <= id && id <= hi {
+			selected = append(selected, id)
+		}
+	}
+	assert.Equal(t, ids[1:4], selected)
+	assert.False(t, slices.Contains(ids, lo))
+	assert.False(t, slices.Contains(ids, hi))
+
+	// Without a prefix
+	lo, _, err = base.IDRange(day, day)
+	require.NoError(t, err)
+	assert.Len(t, lo, 26)
+	lo, hi, err = base.IDRange(time.Unix(0, 0), time.Unix(0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, lo, hi)
+
+	_, _, err = base.WithUnordered().IDRange(day, day)
+	assert.ErrorIs(t, err, ErrNoTimestamp)
+}
+
+func TestIDRangeObfuscated(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err := base.WithTimestampObfuscation(time.Hour)
+	require.NoError(t, err)
+
+	hour := time.Date(2025, time.March, 14, 10, 0, 0, 0, time.UTC)
+	lo, hi, err := r.IDRange(hour.Add(10*time.Minute), hour.Add(20*time.Minute))
+	require.NoError(t, err)
+	wantLo, wantHi := ULIDRange(hour, hour.Add(time.Hour))
+	assert.Equal(t, wantLo, lo, "the range is widened to whole periods")
+	assert.Equal(t, wantHi+".", hi)
+
+	// IDs created within the range are selected wherever their timestamp lands in the period
+	for range 20 {
+		id, err := r.GenerateAt(hour.Add(15 * time.Minute))
+		require.NoError(t, err)
+		assert.True(t, lo <= id && id <= hi)
+	}
+}
@@ -0,0 +1,20 @@
+package rigid
+
+import "errors"
+
+// ErrNoTimestamp indicates a timestamp was requested from an instance generating unordered IDs,
+// which carry none.
+var ErrNoTimestamp = errors.New("unordered IDs carry no timestamp")
+
+// WithUnordered returns a copy of r generating unordered IDs, whose 128-bit ULID component is
+// drawn entirely from crypto/rand instead of starting with a timestamp, for identifiers whose
+// creation time and order must not be inferable. The IDs keep the format and signature of rigid
+// IDs, but must be verified by an unordered instance: it reports no timestamp and skips the TTL
+// and timestamp bound checks, which would otherwise apply to random values.
+// Node IDs, state stores and timestamp obfuscation do not apply to unordered IDs.
+// The returned instance shares the secret key with r.
+func (r *Rigid) WithUnordered() *Rigid {
+	c := r.clone()
+	c.unordered = true
+	return c
+}
@@ -0,0 +1,43 @@
+package rigid
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUnordered(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	u := r.WithUnordered().WithTTL(time.Hour).WithMaxClockSkew(time.Minute)
+	assert.True(t, u.Config().Unordered)
+	assert.False(t, r.Config().Unordered)
+
+	ids := make([]string, 100)
+	seen := make(map[string]bool)
+	for i := range ids {
+		ids[i], err = u.Generate("user:alice")
+		require.NoError(t, err)
+		seen[ids[i][:26]] = true
+
+		result, err := u.Verify(ids[i])
+		require.NoError(t, err)
+		assert.Equal(t, ids[i][:26], result.ULID)
+		assert.Equal(t, "user:alice", result.Metadata)
+		assert.True(t, result.Timestamp.IsZero())
+		assert.True(t, result.ExpiresAt.IsZero())
+		assert.True(t, u.Explain(ids[i]).Valid())
+	}
+	assert.Len(t, seen, len(ids))
+	assert.False(t, sort.StringsAreSorted(ids), "unordered IDs do not sort by generation")
+
+	_, err = u.ExtractTimestamp(ids[0])
+	assert.ErrorIs(t, err, ErrNoTimestamp)
+	_, err = u.Verify(ids[0][:27] + "AAAAAAAAAAAAA")
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+	_, err = r.Verify(ids[0])
+	assert.NoError(t, err, "unordered IDs carry regular signatures")
+}
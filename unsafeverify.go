@@ -0,0 +1,14 @@
+package rigid
+
+// UnsafeVerifyStructureOnly checks id like Verify does, except for its signature: the format,
+// the type prefix or issuer, the signature length, the metadata rules, and the timestamp bounds
+// and TTL of the instance. It returns the ULID, metadata and timestamps of id without computing
+// an HMAC, for hot internal pipelines that only extract fields from IDs verified upstream.
+//
+// A forged ID passes this check. Never use it on IDs crossing a trust boundary; verify those
+// with Verify. The returned result has Valid unset, as the ID is not authenticated. Revocation
+// and replay stores are not consulted, and the call is not counted in Stats or reported to hooks.
+func (r *Rigid) UnsafeVerifyStructureOnly(id string) (VerifyResult, error) {
+	result, _, err := r.verifySegments(id, false)
+	return result, err
+}
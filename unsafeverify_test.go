@@ -0,0 +1,48 @@
+package rigid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsafeVerifyStructureOnly(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	createdAt := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	id, err := r.GenerateAt(createdAt, "user:alice")
+	require.NoError(t, err)
+
+	result, err := r.UnsafeVerifyStructureOnly(id)
+	require.NoError(t, err)
+	assert.False(t, result.Valid, "the signature is not verified")
+	assert.Empty(t, result.KeyID)
+	assert.Equal(t, id[:26], result.ULID)
+	assert.Equal(t, "user:alice", result.Metadata)
+	assert.True(t, createdAt.Equal(result.Timestamp))
+
+	// Forged signatures pass, which is why the method is unsafe
+	forged := id[:27] + "AAAAAAAAAAAAA-user:alice"
+	_, err = r.UnsafeVerifyStructureOnly(forged)
+	assert.NoError(t, err)
+	_, err = r.Verify(forged)
+	assert.ErrorIs(t, err, ErrIntegrityFailure)
+
+	for _, bad := range []string{
+		"garbage",
+		id[:27] + "aaaaaaaaaaaaa-user:alice",
+		id[:27] + "AAAAAAAA-user:alice",
+		"ord_" + id,
+	} {
+		_, err := r.UnsafeVerifyStructureOnly(bad)
+		assert.Error(t, err, bad)
+	}
+
+	// The TTL of the instance still applies
+	_, err = r.WithTTL(time.Second).UnsafeVerifyStructureOnly(id)
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.Zero(t, r.Stats().Verified, "structural checks are not counted")
+}
@@ -0,0 +1,145 @@
+package rigid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUIDv7(t *testing.T) {
+	u, err := NewUUIDv7(testSecretKey)
+	require.NoError(t, err)
+
+	before := time.Now().Truncate(time.Millisecond)
+	id, err := u.Generate("user:alice")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}-[A-Z2-7]{13}-user:alice$`, id)
+
+	result, err := u.Verify(id)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, id[:36], result.UUID)
+	assert.Equal(t, "user:alice", result.Metadata)
+	assert.WithinRange(t, result.Timestamp, before, time.Now())
+
+	// Upper-case UUIDs, as some databases render them, verify to the same UUID
+	upper, err := u.Verify(strings.ToUpper(id[:36]) + id[36:])
+	require.NoError(t, err)
+	assert.Equal(t, result, upper)
+
+	plain, err := u.Generate()
+	require.NoError(t, err)
+	assert.Len(t, plain, 36+1+13)
+	result, err = u.Verify(plain)
+	require.NoError(t, err)
+	assert.Empty(t, result.Metadata)
+}
+
+func TestUUIDv7VerifyFailures(t *testing.T) {
+	u, err := NewUUIDv7(testSecretKey)
+	require.NoError(t, err)
+	id, err := u.Generate("user:alice")
+	require.NoError(t, err)
+	long, err := NewUUIDv7(testSecretKey, 16)
+	require.NoError(t, err)
+	longID, err := long.Generate()
+	require.NoError(t, err)
+	other, err := NewUUIDv7([]byte("another-secret-key-for-testing"))
+	require.NoError(t, err)
+	otherID, err := other.Generate("user:alice")
+	require.NoError(t, err)
+
+	// A rigid ID of the same key and the same signed segments does not verify as a UUID
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r, err = r.WithFormatVersion(FormatV2)
+	require.NoError(t, err)
+	mac := r.getMAC(FormatV2)
+	crossSigned := id[:37] + string(mac.signature(FormatV2, "", id[:36], "user:alice", 8)) + "-user:alice"
+	r.putMAC(FormatV2, mac)
+
+	tests := []struct {
+		name string
+		id   string
+		err  error
+	}{
+		{"empty", "", ErrInvalidFormat},
+		{"no signature", id[:36], ErrInvalidFormat},
+		{"empty signature", id[:37], ErrInvalidFormat},
+		{"bad hex", "g" + id[1:], ErrInvalidUUID},
+		{"misplaced hyphen", id[:7] + "-0" + id[9:], ErrInvalidUUID},
+		{"version 4", id[:14] + "4" + id[15:], ErrInvalidUUID},
+		{"variant", id[:19] + "c" + id[20:], ErrInvalidUUID},
+		{"ulid", "01ARZ3NDEKTSV4RRFFQ69G5FAV-MFRGG2BAMFRGG", ErrInvalidFormat},
+		{"tampered uuid", id[:35] + flipHex(id[35]) + id[36:], ErrIntegrityFailure},
+		{"tampered metadata", strings.Replace(id, "alice", "mallory", 1), ErrIntegrityFailure},
+		{"tampered signature", id[:37] + "AAAAAAAAAAAAA" + id[50:], ErrIntegrityFailure},
+		{"signature length", longID, ErrSignatureLengthMismatch},
+		{"other key", otherID, ErrIntegrityFailure},
+		{"rigid signature", crossSigned, ErrIntegrityFailure},
+		{"too long", id + strings.Repeat("x", DefaultMaxLength), ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := u.Verify(tt.id)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, UUIDResult{}, result)
+		})
+	}
+}
+
+func TestUUIDv7Monotonic(t *testing.T) {
+	u, err := NewUUIDv7(testSecretKey)
+	require.NoError(t, err)
+
+	// Within a millisecond the random bits count up, and once exhausted move to the next one
+	at := time.UnixMilli(1700000000000)
+	u.gen.hi = 1<<12 - 1
+	u.gen.lo = 1<<62 - 1
+	u.gen.lastMS = uint64(at.UnixMilli())
+
+	prev := formatUUID([16]byte{})
+	for i := range 1000 {
+		b, err := u.gen.next(at)
+		require.NoError(t, err)
+		uuid := formatUUID(b)
+		require.Greater(t, uuid, prev, i)
+		prev = uuid
+
+		parsed, err := parseUUID(uuid)
+		require.NoError(t, err)
+		assert.Equal(t, b, parsed)
+	}
+	assert.Equal(t, uint64(at.UnixMilli()+1), u.gen.lastMS)
+
+	// A clock stepping back does not break the order
+	b, err := u.gen.next(at.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Greater(t, formatUUID(b), prev)
+
+	_, err = u.gen.next(time.UnixMilli(-1))
+	assert.Error(t, err)
+}
+
+func TestNewUUIDv7Errors(t *testing.T) {
+	_, err := NewUUIDv7(nil)
+	assert.ErrorIs(t, err, ErrEmptySecretKey)
+	_, err = NewUUIDv7(testSecretKey, 3)
+	assert.ErrorIs(t, err, ErrInvalidSigLength)
+
+	u, err := NewUUIDv7(testSecretKey)
+	require.NoError(t, err)
+	_, err = u.Generate(strings.Repeat("m", DefaultMaxLength))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+// flipHex returns a hex digit other than c.
+func flipHex(c byte) string {
+	if c == '0' {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,89 @@
+package rigid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrIncompatible indicates this build does not reproduce test vectors, see CheckCompatibility.
+var ErrIncompatible = errors.New("incompatible with test vectors")
+
+// TestVectorsVersion is the version of the layout of TestVectors documents.
+const TestVectorsVersion = 1
+
+// TestVectors is a document of cross-language test vectors, as written by the rigid vectors
+// command and kept in the rigidtest package. Implementations in other languages check the vectors
+// to prove they produce and accept the same IDs.
+type TestVectors struct {
+	Version int          `json:"version"`
+	Vectors []TestVector `json:"vectors"`
+}
+
+// TestVector is a cross-language test vector: signing ULID and Metadata with Key in FormatV1,
+// truncated to SignatureLength bytes and typed with Prefix, must produce exactly ID.
+type TestVector struct {
+	Key             string `json:"key"`
+	SignatureLength int    `json:"signature_length"`
+	Prefix          string `json:"prefix,omitempty"`
+	ULID            string `json:"ulid"`
+	Metadata        string `json:"metadata,omitempty"`
+	ID              string `json:"id"`
+}
+
+// CheckCompatibility reads a TestVectors document from vectors and checks that this build
+// reproduces every vector: signing the vector's ULID and metadata must produce its ID, and
+// verifying the ID must return them. Deployments call it at startup with the vectors of the other
+// implementations they share IDs with, to detect algorithm or encoding drift before issuing IDs.
+// Returns an error wrapping ErrIncompatible and naming the first failing vector, or an error if
+// the document cannot be read or has an unsupported version.
+func CheckCompatibility(vectors io.Reader) error {
+	var doc TestVectors
+	if err := json.NewDecoder(vectors).Decode(&doc); err != nil {
+		return fmt.Errorf("read test vectors: %w", err)
+	}
+	if doc.Version != TestVectorsVersion {
+		return fmt.Errorf("unsupported test vectors version %d", doc.Version)
+	}
+
+	for i, v := range doc.Vectors {
+		if err := v.check(); err != nil {
+			return fmt.Errorf("%w: vector %d (%s): %w", ErrIncompatible, i, v.ID, err)
+		}
+	}
+	return nil
+}
+
+// check signs and verifies v.
+func (v TestVector) check() error {
+	r, err := NewRigid([]byte(v.Key), v.SignatureLength)
+	if err != nil {
+		return err
+	}
+	if r, err = r.WithPrefix(v.Prefix); err != nil {
+		return err
+	}
+	u, err := ulid.ParseStrict(v.ULID)
+	if err != nil {
+		return err
+	}
+
+	id, err := r.Sign(u, v.Metadata)
+	if err != nil {
+		return err
+	}
+	if id != v.ID {
+		return fmt.Errorf("signed as %s", id)
+	}
+	result, err := r.Verify(v.ID)
+	if err != nil {
+		return err
+	}
+	if result.ULID != v.ULID || result.Metadata != v.Metadata {
+		return fmt.Errorf("verified as ULID %s and metadata %q", result.ULID, result.Metadata)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package rigid
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeVectors(t *testing.T, doc TestVectors) *bytes.Reader {
+	t.Helper()
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return bytes.NewReader(b)
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	doc := TestVectors{Version: TestVectorsVersion}
+	u := ulid.MustParse("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	for _, prefix := range []string{"", "ord"} {
+		for _, metadata := range []string{"", "a-b-c", "ünïcödé ✓"} {
+			r, err := NewRigid(testSecretKey, 12)
+			require.NoError(t, err)
+			r, err = r.WithPrefix(prefix)
+			require.NoError(t, err)
+			id, err := r.Sign(u, metadata)
+			require.NoError(t, err)
+			doc.Vectors = append(doc.Vectors, TestVector{
+				Key:             string(testSecretKey),
+				SignatureLength: 12,
+				Prefix:          prefix,
+				ULID:            u.String(),
+				Metadata:        metadata,
+				ID:              id,
+			})
+		}
+	}
+	require.NoError(t, CheckCompatibility(encodeVectors(t, doc)))
+
+	// Drift in the signature is reported with the failing vector
+	drifted := doc
+	drifted.Vectors = append([]TestVector(nil), doc.Vectors...)
+	drifted.Vectors[4].ID = drifted.Vectors[4].ID[:27] + "AAAAAAAAAAAAA" + drifted.Vectors[4].ID[40:]
+	err := CheckCompatibility(encodeVectors(t, drifted))
+	assert.ErrorIs(t, err, ErrIncompatible)
+	assert.Contains(t, err.Error(), "vector 4")
+
+	// Metadata that no longer round-trips is drift too
+	drifted.Vectors = append([]TestVector(nil), doc.Vectors...)
+	drifted.Vectors[1].Metadata = "a-b-d"
+	assert.ErrorIs(t, CheckCompatibility(encodeVectors(t, drifted)), ErrIncompatible)
+}
+
+func TestCheckCompatibilityErrors(t *testing.T) {
+	err := CheckCompatibility(strings.NewReader("not json"))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrIncompatible)
+
+	err = CheckCompatibility(encodeVectors(t, TestVectors{Version: TestVectorsVersion + 1}))
+	assert.ErrorContains(t, err, "unsupported test vectors version")
+
+	assert.NoError(t, CheckCompatibility(encodeVectors(t, TestVectors{Version: TestVectorsVersion})))
+}
@@ -0,0 +1,144 @@
+package rigid
+
+import (
+	"strings"
+	"time"
+)
+
+// VerifyOption adjusts the checks of a single Verify or VerifyContext call, so policies that
+// vary per call, such as a shorter maximum age for sensitive operations, need no separately
+// configured instance.
+type VerifyOption func(*verifyPolicy)
+
+// verifyPolicy holds the verify options of a call.
+type verifyPolicy struct {
+	maxAge         time.Duration
+	grace          time.Duration
+	graceTTL       bool
+	metadataPrefix *string
+	prefix         *string
+	revocation     *bool
+	context        *[]byte
+}
+
+// WithMaxAge rejects IDs whose timestamp is more than d in the past with ErrExpired, in addition
+// to the TTL of the instance, and reports the earlier of both expiries in VerifyResult.ExpiresAt.
+// IDs of unordered instances carry no timestamp, so their age cannot be checked and they are
+// rejected. A zero or negative d leaves the age unchecked.
+func WithMaxAge(d time.Duration) VerifyOption {
+	return func(p *verifyPolicy) {
+		p.maxAge = d
+	}
+}
+
+// WithGracePeriod accepts IDs that expired, by the TTL of the instance or WithMaxAge, less than
+// d ago, reporting them with VerifyResult.InGracePeriod set, so refresh flows can exchange a
+// recently expired ID for a new one without accepting arbitrarily old IDs. VerifyResult.ExpiresAt
+// still reports the expiry itself. A zero or negative d allows no grace.
+func WithGracePeriod(d time.Duration) VerifyOption {
+	return func(p *verifyPolicy) {
+		p.grace = max(d, 0)
+	}
+}
+
+// WithExpectedMetadata rejects IDs whose metadata does not start with prefix with
+// ErrInvalidMetadata, such as "user:" for IDs that must have been issued to a user.
+func WithExpectedMetadata(prefix string) VerifyOption {
+	return func(p *verifyPolicy) {
+		p.metadataPrefix = &prefix
+	}
+}
+
+// WithExpectedPrefix verifies IDs as an instance with the type prefix p would, as derived with
+// Rigid.WithPrefix, so one instance can verify IDs of several types. IDs with another prefix
+// fail verification with ErrInvalidFormat, and an invalid p fails it with ErrInvalidPrefix.
+func WithExpectedPrefix(p string) VerifyOption {
+	return func(policy *verifyPolicy) {
+		policy.prefix = &p
+	}
+}
+
+// WithRevocationCheck enables or disables the revocation check of the call. Disabling it
+// skips the revocation store, such as for reads tolerating a revoked ID where the round trip to
+// the store is not worth it; enabling it on an instance without a revocation store fails
+// verification with ErrNoRevocationStore rather than silently accepting the ID.
+func WithRevocationCheck(enabled bool) VerifyOption {
+	return func(p *verifyPolicy) {
+		p.revocation = &enabled
+	}
+}
+
+// WithContext verifies IDs as an instance derived with Rigid.WithContext(b) would, for IDs
+// generated with a bound context. IDs generated for another context, or for none, fail
+// verification with ErrIntegrityFailure.
+func WithContext(b []byte) VerifyOption {
+	return func(p *verifyPolicy) {
+		p.context = &b
+	}
+}
+
+// newVerifyPolicy returns the policy set by opts. Callers only build a policy when options are
+// given, keeping verification without options free of allocations.
+func newVerifyPolicy(opts []VerifyOption) *verifyPolicy {
+	p := &verifyPolicy{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// instance returns the instance verifying IDs under the policy, derived from r.
+func (p *verifyPolicy) instance(r *Rigid) (*Rigid, error) {
+	if p.prefix != nil {
+		var err error
+		if r, err = r.WithPrefix(*p.prefix); err != nil {
+			return nil, err
+		}
+	}
+	if p.context != nil {
+		r = r.WithContext(*p.context)
+	}
+	if p.grace > 0 && r.ttl > 0 {
+		// Extend the TTL by the grace period, and take it back out of the result in check
+		r = r.clone()
+		r.ttl += p.grace
+		p.graceTTL = true
+	}
+	if p.revocation != nil {
+		if *p.revocation && r.revocations == nil {
+			return nil, ErrNoRevocationStore
+		}
+		if !*p.revocation && r.revocations != nil {
+			r = r.clone()
+			r.revocations = nil
+		}
+	}
+	return r, nil
+}
+
+// check checks a verified result against the policy, returning the result with its expiry
+// adjusted to the maximum age and the grace period.
+func (p *verifyPolicy) check(result VerifyResult, now time.Time) (VerifyResult, error) {
+	if p.graceTTL {
+		result.ExpiresAt = result.ExpiresAt.Add(-p.grace)
+	}
+	if p.maxAge > 0 {
+		if result.Timestamp.IsZero() {
+			return VerifyResult{}, ErrExpired
+		}
+		expiresAt := result.Timestamp.Add(p.maxAge)
+		if !now.Before(expiresAt.Add(p.grace)) {
+			return VerifyResult{}, ErrExpired
+		}
+		if result.ExpiresAt.IsZero() || expiresAt.Before(result.ExpiresAt) {
+			result.ExpiresAt = expiresAt
+		}
+	}
+	if p.grace > 0 && !result.ExpiresAt.IsZero() && !now.Before(result.ExpiresAt) {
+		result.InGracePeriod = true
+	}
+	if p.metadataPrefix != nil && !strings.HasPrefix(result.Metadata, *p.metadataPrefix) {
+		return VerifyResult{}, ErrInvalidMetadata
+	}
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package rigid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxAge(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+
+	old, err := r.GenerateAt(time.Now().Add(-2*time.Hour), "user:alice")
+	require.NoError(t, err)
+	_, err = r.Verify(old)
+	require.NoError(t, err)
+	_, err = r.Verify(old, WithMaxAge(time.Hour))
+	assert.ErrorIs(t, err, ErrExpired)
+	_, err = r.Verify(old, WithMaxAge(0))
+	assert.NoError(t, err, "a zero maximum age leaves the age unchecked")
+
+	fresh, err := r.Generate()
+	require.NoError(t, err)
+	result, err := r.Verify(fresh, WithMaxAge(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, result.Timestamp.Add(time.Hour), result.ExpiresAt)
+
+	// The earlier of the TTL and the maximum age applies
+	result, err = r.WithTTL(time.Minute).Verify(fresh, WithMaxAge(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, result.Timestamp.Add(time.Minute), result.ExpiresAt)
+
+	// Unordered IDs have no age to check
+	unordered := r.WithUnordered()
+	id, err := unordered.Generate()
+	require.NoError(t, err)
+	_, err = unordered.Verify(id, WithMaxAge(time.Hour))
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestWithExpectedMetadata(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	id, err := r.Generate("user:alice")
+	require.NoError(t, err)
+
+	result, err := r.Verify(id, WithExpectedMetadata("user:"))
+	require.NoError(t, err)
+	assert.Equal(t, "user:alice", result.Metadata)
+	_, err = r.Verify(id, WithExpectedMetadata("order:"))
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+
+	bare, err := r.Generate()
+	require.NoError(t, err)
+	_, err = r.Verify(bare, WithExpectedMetadata(""))
+	assert.NoError(t, err)
+	_, err = r.Verify(bare, WithExpectedMetadata("user:"))
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+}
+
+func TestWithExpectedPrefix(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	users, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	orders, err := r.WithPrefix("ord")
+	require.NoError(t, err)
+	userID, err := users.Generate()
+	require.NoError(t, err)
+	orderID, err := orders.Generate()
+	require.NoError(t, err)
+
+	// One instance verifies IDs of several types
+	_, err = r.Verify(userID, WithExpectedPrefix("usr"))
+	assert.NoError(t, err)
+	_, err = r.Verify(orderID, WithExpectedPrefix("ord"))
+	assert.NoError(t, err)
+	_, err = r.Verify(orderID, WithExpectedPrefix("usr"))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = r.Verify(userID, WithExpectedPrefix("us-r"))
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+
+	// The option replaces the prefix of the instance
+	_, err = users.Verify(orderID, WithExpectedPrefix("ord"))
+	assert.NoError(t, err)
+	_, err = r.Verify(userID)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestWithRevocationCheck(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	store := newMapStore()
+	r := base.WithRevocationStore(store)
+	ctx := context.Background()
+
+	id, err := r.Generate()
+	require.NoError(t, err)
+	require.NoError(t, r.Revoke(ctx, id, 0))
+
+	_, err = r.VerifyContext(ctx, id)
+	assert.ErrorIs(t, err, ErrRevoked)
+	_, err = r.VerifyContext(ctx, id, WithRevocationCheck(false))
+	assert.NoError(t, err)
+	_, err = r.VerifyContext(ctx, id, WithRevocationCheck(true))
+	assert.ErrorIs(t, err, ErrRevoked)
+
+	// Requiring the check fails closed without a store
+	_, err = base.Verify(id, WithRevocationCheck(true))
+	assert.ErrorIs(t, err, ErrNoRevocationStore)
+	_, err = base.Verify(id, WithRevocationCheck(false))
+	assert.NoError(t, err)
+}
+
+func TestVerifyOptionsBeforeStores(t *testing.T) {
+	base, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	r := base.WithReplayStore(newMapStore(), time.Hour)
+
+	id, err := r.Generate("order:42")
+	require.NoError(t, err)
+
+	// An ID failing an option is not marked as used
+	_, err = r.Verify(id, WithExpectedMetadata("user:"))
+	assert.ErrorIs(t, err, ErrInvalidMetadata)
+	_, err = r.Verify(id)
+	assert.NoError(t, err)
+	_, err = r.Verify(id)
+	assert.ErrorIs(t, err, ErrReplayed)
+
+	assert.Equal(t, uint64(2), r.Stats().VerifyFailures)
+}
+
+func TestVerifyOptionsCombined(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	users, err := r.WithPrefix("usr")
+	require.NoError(t, err)
+	u := ulid.Make()
+	id, err := users.Sign(u, "user:alice")
+	require.NoError(t, err)
+
+	result, err := r.Verify(id, WithExpectedPrefix("usr"), WithExpectedMetadata("user:"), WithMaxAge(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, u.String(), result.ULID)
+}
+
+func TestWithGracePeriod(t *testing.T) {
+	r, err := NewRigid(testSecretKey)
+	require.NoError(t, err)
+	short := r.WithTTL(time.Hour)
+
+	expired, err := short.GenerateAt(time.Now().Add(-90 * time.Minute))
+	require.NoError(t, err)
+	_, err = short.Verify(expired)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	result, err := short.Verify(expired, WithGracePeriod(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.InGracePeriod)
+	assert.Equal(t, result.Timestamp.Add(time.Hour), result.ExpiresAt, "the expiry excludes the grace period")
+
+	_, err = short.Verify(expired, WithGracePeriod(15*time.Minute))
+	assert.ErrorIs(t, err, ErrExpired, "IDs expired longer than the grace period are rejected")
+
+	fresh, err := short.Generate()
+	require.NoError(t, err)
+	result, err = short.Verify(fresh, WithGracePeriod(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, result.InGracePeriod)
+
+	// The grace period applies to the maximum age too
+	old, err := r.GenerateAt(time.Now().Add(-90 * time.Minute))
+	require.NoError(t, err)
+	result, err = r.Verify(old, WithMaxAge(time.Hour), WithGracePeriod(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, result.InGracePeriod)
+	_, err = r.Verify(old, WithMaxAge(time.Hour), WithGracePeriod(0))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// Without an expiry there is nothing to be lenient about
+	result, err = r.Verify(old, WithGracePeriod(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, result.InGracePeriod)
+}